@@ -1,10 +1,11 @@
 // 文件功能：应用配置加载与默认值设置；支持从配置文件与环境变量合并生成运行时配置。
 // 用户认证相关处理
-// 包功能：配置包，提供 Server、DeepSeek、ES8 等模块的配置结构体与加载函数。
+// 包功能：配置包，提供 Server、DeepSeek、ES8、向量化、向量存储、检索等模块的配置结构体与加载函数。
 package config
 
 import (
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -29,22 +30,163 @@ type DeepSeekConfig struct {
 //   - Address：服务地址；
 //   - Username：用户名；
 //   - Password：密码；
-//   - Index：默认索引名称。
+//   - Index：检索使用的别名；index 命令写入带时间戳的版本索引（<index>-<时间戳>），校验通过后切换别名；
+//   - KeepVersions：切换别名后保留的旧版本数，供 index rollback 回滚；
+//   - BulkSize：单次 bulk 请求的文档数；
+//   - MaxRetries：限流与网关错误的最大重试次数；
+//   - Timeout：单次请求超时。
 type ES8Config struct {
 	Address  string `mapstructure:"address"`  // ES 地址
 	Username string `mapstructure:"username"` // 用户名
 	Password string `mapstructure:"password"` // 密码
-	Index    string `mapstructure:"index"`    // 索引名称
+	Index    string `mapstructure:"index"`    // 别名
+
+	KeepVersions int           `mapstructure:"keep_versions"` // 保留的旧版本数
+	BulkSize     int           `mapstructure:"bulk_size"`     // bulk 批大小
+	MaxRetries   int           `mapstructure:"max_retries"`   // 最大重试次数
+	Timeout      time.Duration `mapstructure:"timeout"`       // 请求超时
+}
+
+// ParserConfig：语料收集与解析配置。
+//   - Dir：语料根目录；
+//   - Include：包含模式；非空时仅收集匹配的文件；
+//   - Exclude：排除模式；默认排除 template/；
+//   - IgnoreFile：语料根目录下的忽略文件名；
+//   - CollapseBoilerplate：索引时折叠大部分菜谱共有的样板行（如 Issue/PR 提示）。
+type ParserConfig struct {
+	Dir        string   `mapstructure:"dir"`         // 语料根目录
+	Include    []string `mapstructure:"include"`     // 包含模式
+	Exclude    []string `mapstructure:"exclude"`     // 排除模式
+	IgnoreFile string   `mapstructure:"ignore_file"` // 忽略文件名
+
+	CollapseBoilerplate bool `mapstructure:"collapse_boilerplate"` // 折叠样板行
+}
+
+// EmbeddingConfig：文本向量化配置。
+//   - Provider：后端（openai：OpenAI 兼容接口；hash：离线哈希 n-gram，无需凭据）；
+//   - BaseURL：OpenAI 兼容接口基础地址（含 /v1）；
+//   - Model：模型名称；
+//   - APIKey：访问密钥，可由环境变量 RECIPE_AGENT_EMBEDDING_API_KEY 覆盖；
+//   - Dimensions：向量维度；默认 0：hash 后端取 512，openai 后端请求中不携带 dimensions、使用模型默认维度；
+//   - BatchSize：单次请求的文本数；
+//   - Timeout：单次请求超时；
+//   - CachePath：持久化向量缓存文件路径（键为模型与文本哈希，更换模型后旧向量自动失效）；为空时不缓存。
+type EmbeddingConfig struct {
+	Provider   string        `mapstructure:"provider"`   // 后端
+	BaseURL    string        `mapstructure:"base_url"`   // 接口基础地址
+	Model      string        `mapstructure:"model"`      // 模型名称
+	APIKey     string        `mapstructure:"api_key"`    // 访问密钥
+	Dimensions int           `mapstructure:"dimensions"` // 向量维度
+	BatchSize  int           `mapstructure:"batch_size"` // 批大小
+	Timeout    time.Duration `mapstructure:"timeout"`    // 请求超时
+	CachePath  string        `mapstructure:"cache_path"` // 向量缓存路径
+}
+
+// VectorConfig：向量存储配置。
+//   - Backend：存储后端（local：本地快照文件，无需外部服务；es：Elasticsearch，连接参数见 es8）；
+//   - Path：local 后端的快照文件路径；
+//   - ExactLimit：local 后端过滤后候选不超过该数量时精确计算，超过时走 HNSW 近似图；为负时始终走近似图。
+type VectorConfig struct {
+	Backend    string `mapstructure:"backend"`     // 存储后端
+	Path       string `mapstructure:"path"`        // 快照文件路径
+	ExactLimit int    `mapstructure:"exact_limit"` // 精确计算上限
+}
+
+// ArmConfig：混合检索中一路召回的配置。
+//   - Weight：融合权重；0 表示关闭该路；
+//   - TopK：该路召回条数。
+type ArmConfig struct {
+	Weight float64 `mapstructure:"weight"` // 融合权重
+	TopK   int     `mapstructure:"top_k"`  // 召回条数
+}
+
+// ExpansionConfig：查询扩展配置。
+//   - Enabled：是否扩展；
+//   - File：追加的原料本体词表路径（格式同内嵌词表：「A = B」同义词、「A < B」上下位）；为空时只用内嵌词表；
+//   - SynonymWeight/NarrowerWeight：同义词与直接下位词的权重（原查询词为 1）；
+//   - Decay：下位词每深一层的权重衰减；
+//   - MaxDepth：下位词最大层级；
+//   - MaxTerms：扩展词上限。
+type ExpansionConfig struct {
+	Enabled        bool    `mapstructure:"enabled"`         // 是否扩展
+	File           string  `mapstructure:"file"`            // 追加词表
+	SynonymWeight  float64 `mapstructure:"synonym_weight"`  // 同义词权重
+	NarrowerWeight float64 `mapstructure:"narrower_weight"` // 下位词权重
+	Decay          float64 `mapstructure:"decay"`           // 层级衰减
+	MaxDepth       int     `mapstructure:"max_depth"`       // 最大层级
+	MaxTerms       int     `mapstructure:"max_terms"`       // 扩展词上限
+}
+
+// DiversityConfig：结果多样化配置。
+//   - Enabled：是否在融合与重排之后做多样化；
+//   - Lambda：MMR 相关度权重（0～1）；越小结果越分散，0 时只按每菜谱上限截取；
+//   - MaxPerRecipe：每道菜谱最多保留的分块数；0 表示不限（问题中出现完整菜名的菜谱不受限）；
+//   - SuggestPerRecipe：推荐类问题（如「推荐几道快手早餐」）每道菜谱的分块上限；
+//   - Depth：参与多样化的候选数；0 时为返回条数的 4 倍；
+//   - GroupSize：按菜谱分组返回时每道菜谱的分块数。
+type DiversityConfig struct {
+	Enabled          bool    `mapstructure:"enabled"`            // 是否多样化
+	Lambda           float64 `mapstructure:"lambda"`             // MMR 相关度权重
+	MaxPerRecipe     int     `mapstructure:"max_per_recipe"`     // 每菜谱上限
+	SuggestPerRecipe int     `mapstructure:"suggest_per_recipe"` // 推荐类问题每菜谱上限
+	Depth            int     `mapstructure:"depth"`              // 候选数
+	GroupSize        int     `mapstructure:"group_size"`         // 分组大小
+}
+
+// RetrievalConfig：问答检索配置。
+//   - Fusion：融合方式（rrf：倒数排名融合；weighted：归一化得分加权）；
+//   - RRFK：RRF 平滑常数；
+//   - TopK：融合后返回条数（请求未指定时）；
+//   - Dense/Lexical/Name：稠密向量、BM25 与菜名三路召回；
+//   - Expansion：查询扩展（同义词与原料上下位词，作用于 BM25 召回）；
+//   - Diversity：结果多样化（MMR、每菜谱上限与按菜谱分组）。
+type RetrievalConfig struct {
+	Fusion  string    `mapstructure:"fusion"`  // 融合方式
+	RRFK    float64   `mapstructure:"rrf_k"`   // RRF 常数
+	TopK    int       `mapstructure:"top_k"`   // 返回条数
+	Dense   ArmConfig `mapstructure:"dense"`   // 稠密召回
+	Lexical ArmConfig `mapstructure:"lexical"` // BM25 召回
+	Name    ArmConfig `mapstructure:"name"`    // 菜名召回
+
+	Expansion ExpansionConfig `mapstructure:"expansion"` // 查询扩展
+	Diversity DiversityConfig `mapstructure:"diversity"` // 结果多样化
+}
+
+// RerankConfig：检索结果重排配置。
+//   - Provider：重排器（none：不重排；heuristic：离线启发式；openai：OpenAI 兼容 rerank 接口；llm：DeepSeek 大模型打分，连接参数见 deepseek）；
+//   - Depth：参与重排的候选数；
+//   - Timeout：单次重排超时；超时后保留融合顺序；
+//   - BaseURL/Model/APIKey：openai 重排接口的基础地址（含 /v1）、模型与访问密钥，密钥可由环境变量 RECIPE_AGENT_RERANK_API_KEY 覆盖；
+//   - MaxChars：远程重排时单个候选送出的最大字符数。
+type RerankConfig struct {
+	Provider string        `mapstructure:"provider"`  // 重排器
+	Depth    int           `mapstructure:"depth"`     // 重排深度
+	Timeout  time.Duration `mapstructure:"timeout"`   // 重排超时
+	BaseURL  string        `mapstructure:"base_url"`  // 接口基础地址
+	Model    string        `mapstructure:"model"`     // 模型名称
+	APIKey   string        `mapstructure:"api_key"`   // 访问密钥
+	MaxChars int           `mapstructure:"max_chars"` // 候选截断长度
 }
 
 // AppConfig：应用配置根结构。
 //   - Server：HTTP 服务配置；
 //   - DeepSeek：大模型调用配置；
-//   - ES8：向量检索/索引构建的存储后端配置。
+//   - ES8：向量检索/索引构建的存储后端配置；
+//   - Parser：语料收集与解析配置；
+//   - Embedding：文本向量化配置；
+//   - Vector：向量存储配置；
+//   - Retrieval：问答检索配置；
+//   - Rerank：检索结果重排配置。
 type AppConfig struct {
 	Server   ServerConfig   `mapstructure:"server"`   // 服务配置
 	DeepSeek DeepSeekConfig `mapstructure:"deepseek"` // DeepSeek 配置
 	ES8      ES8Config      `mapstructure:"es8"`      // ES8 配置
+	Parser   ParserConfig   `mapstructure:"parser"`   // 解析配置
+
+	Embedding EmbeddingConfig `mapstructure:"embedding"` // 向量化配置
+	Vector    VectorConfig    `mapstructure:"vector"`    // 向量存储配置
+	Retrieval RetrievalConfig `mapstructure:"retrieval"` // 检索配置
+	Rerank    RerankConfig    `mapstructure:"rerank"`    // 重排配置
 }
 
 // Load：加载应用配置。
@@ -75,6 +217,12 @@ func Load() (*AppConfig, error) {
 	if k := v.GetString("DEEPSEEK_API_KEY"); k != "" {
 		cfg.DeepSeek.APIKey = k
 	}
+	if k := v.GetString("EMBEDDING_API_KEY"); k != "" {
+		cfg.Embedding.APIKey = k
+	}
+	if k := v.GetString("RERANK_API_KEY"); k != "" {
+		cfg.Rerank.APIKey = k
+	}
 	return cfg, nil
 }
 
@@ -89,6 +237,50 @@ func setDefaults(v *viper.Viper) error {
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("deepseek.base_url", "https://api.deepseek.com")
 	v.SetDefault("deepseek.model", "deepseek-chat")
+	v.SetDefault("es8.address", "http://localhost:9200")
 	v.SetDefault("es8.index", "recipes")
+	v.SetDefault("es8.keep_versions", 2)
+	v.SetDefault("es8.bulk_size", 200)
+	v.SetDefault("es8.max_retries", 3)
+	v.SetDefault("es8.timeout", "30s")
+	v.SetDefault("parser.dir", "recipes")
+	v.SetDefault("parser.exclude", []string{"template/"})
+	v.SetDefault("parser.ignore_file", ".recipeignore")
+	v.SetDefault("parser.collapse_boilerplate", false)
+	v.SetDefault("embedding.provider", "hash")
+	v.SetDefault("embedding.base_url", "https://api.openai.com/v1")
+	v.SetDefault("embedding.model", "text-embedding-3-small")
+	v.SetDefault("embedding.dimensions", 0) // 0：按后端取默认维度，避免向 openai 后端发送 hash 后端的维度
+	v.SetDefault("embedding.batch_size", 64)
+	v.SetDefault("embedding.timeout", "30s")
+	v.SetDefault("embedding.cache_path", "data/embeddings.cache")
+	v.SetDefault("vector.backend", "local")
+	v.SetDefault("vector.path", "data/vectors.snap")
+	v.SetDefault("vector.exact_limit", 512)
+	v.SetDefault("retrieval.fusion", "rrf")
+	v.SetDefault("retrieval.rrf_k", 60)
+	v.SetDefault("retrieval.top_k", 5)
+	v.SetDefault("retrieval.dense.weight", 1.0)
+	v.SetDefault("retrieval.dense.top_k", 20)
+	v.SetDefault("retrieval.lexical.weight", 1.0)
+	v.SetDefault("retrieval.lexical.top_k", 20)
+	v.SetDefault("retrieval.name.weight", 0.5)
+	v.SetDefault("retrieval.name.top_k", 10)
+	v.SetDefault("retrieval.expansion.enabled", true)
+	v.SetDefault("retrieval.expansion.synonym_weight", 0.8)
+	v.SetDefault("retrieval.expansion.narrower_weight", 0.6)
+	v.SetDefault("retrieval.expansion.decay", 0.75)
+	v.SetDefault("retrieval.expansion.max_depth", 3)
+	v.SetDefault("retrieval.expansion.max_terms", 24)
+	v.SetDefault("retrieval.diversity.enabled", true)
+	v.SetDefault("retrieval.diversity.lambda", 0.7)
+	v.SetDefault("retrieval.diversity.max_per_recipe", 2)
+	v.SetDefault("retrieval.diversity.suggest_per_recipe", 1)
+	v.SetDefault("retrieval.diversity.group_size", 3)
+	v.SetDefault("rerank.provider", "heuristic")
+	v.SetDefault("rerank.depth", 20)
+	v.SetDefault("rerank.timeout", "10s")
+	v.SetDefault("rerank.model", "BAAI/bge-reranker-v2-m3")
+	v.SetDefault("rerank.max_chars", 512)
 	return nil
 }
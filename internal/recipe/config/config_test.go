@@ -0,0 +1,21 @@
+package config
+
+import "testing"
+
+func TestEmbeddingDimensions(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Embedding.Provider != "hash" || cfg.Embedding.Dimensions != 0 {
+		t.Fatalf("default embedding: %+v", cfg.Embedding)
+	}
+	t.Setenv("RECIPE_AGENT_EMBEDDING_PROVIDER", "openai")
+	if cfg, _ = Load(); cfg.Embedding.Dimensions != 0 {
+		t.Fatalf("openai inherits dimensions %d", cfg.Embedding.Dimensions)
+	}
+	t.Setenv("RECIPE_AGENT_EMBEDDING_DIMENSIONS", "256")
+	if cfg, _ = Load(); cfg.Embedding.Dimensions != 256 {
+		t.Fatalf("dimensions override = %d", cfg.Embedding.Dimensions)
+	}
+}
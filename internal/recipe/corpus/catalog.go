@@ -0,0 +1,269 @@
+// 文件功能：内存语料目录（catalog）；保存当前语料的分块与结构化菜谱，支持按菜谱包（bundle）局部刷新并产出变更。
+package corpus
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"cook/internal/recipe/dedup"
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/parser/types"
+)
+
+// Catalog：内存语料目录；并发安全。
+// 业务背景：serve 与 index --watch 共用；文件变更后只重新解析受影响的菜谱包，并同步更新分块与菜谱列表。
+type Catalog struct {
+	reg    *parser.Registry
+	opts   types.Options
+	bpOpts *dedup.BoilerplateOptions // 非 nil 时折叠样板行
+	bp     *dedup.Boilerplate        // Load 时学习到的样板行
+
+	update  sync.Mutex // 串行化 Load 与 Refresh；解析在 mu 之外进行，期间查询不受阻塞
+	mu      sync.RWMutex
+	version uint64                      // 每次内容变化后递增
+	chunks  map[string][]types.Chunk    // 相对路径 → 分块（稳定 ID）
+	recipes map[string][]*parser.Recipe // 相对路径 → 结构化菜谱
+}
+
+// NewCatalog：构造语料目录。
+// 参数说明：
+//   - reg：解析器注册表；其 Filter 决定哪些文件属于语料；
+//   - opts：解析选项；Root 为语料根目录，必填。
+func NewCatalog(reg *parser.Registry, opts types.Options) *Catalog {
+	return &Catalog{
+		reg:     reg,
+		opts:    opts,
+		chunks:  make(map[string][]types.Chunk),
+		recipes: make(map[string][]*parser.Recipe),
+	}
+}
+
+// Root：语料根目录。
+func (c *Catalog) Root() string { return c.opts.Root }
+
+// CollapseBoilerplate：启用样板行折叠；须在 Load 之前调用。
+// 功能说明：Load 时从全量分块学习样板行（如每篇菜谱末尾的 Issue/PR 提示），入库前剔除，
+// 仅由样板行组成的分块不再入库；Refresh 沿用 Load 时学习到的集合。
+func (c *Catalog) CollapseBoilerplate(opts dedup.BoilerplateOptions) { c.bpOpts = &opts }
+
+// Load：全量收集并解析语料；返回全部分块的 upsert 变更。
+func (c *Catalog) Load() ([]Delta, error) {
+	c.update.Lock()
+	defer c.update.Unlock()
+	files, err := c.reg.Collect(c.opts.Root)
+	if err != nil {
+		return nil, err
+	}
+	parsed := make([]parsedFile, 0, len(files))
+	var all []types.Chunk
+	for _, pth := range files {
+		pf, err := c.parseFile(pth)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, pf)
+		all = append(all, pf.chunks...)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.bpOpts != nil {
+		c.bp = dedup.LearnBoilerplate(all, *c.bpOpts)
+	}
+	var deltas []Delta
+	for _, pf := range parsed {
+		deltas = c.commitLocked(pf, deltas)
+	}
+	c.version++
+	return deltas, nil
+}
+
+// Refresh：按触发变更的路径局部刷新。
+// 功能说明：
+//  1. 重新收集语料文件列表（仅遍历目录，不解析），得到新增与删除的文件；
+//  2. 触发路径所在的菜谱包（分类/菜名/ 目录；位于分类目录下的单文件即其自身）内的文件重新解析；
+//  3. 已不存在或被过滤规则排除的文件输出全部分块的删除墓碑。
+//
+// 与 Load 相同，解析在写锁之外进行，仅提交结果时持有写锁，刷新期间的查询读取刷新前的内容。
+// 参数说明：
+//   - paths：变更路径（绝对路径或以 Root 为前缀的路径）；可以是文件或目录。
+//
+// 返回值说明：
+//   - []Delta：本次刷新产生的变更；
+//   - error：收集或解析失败时返回错误；目录保持不变，下次刷新会重新对比。
+func (c *Catalog) Refresh(paths []string) ([]Delta, error) {
+	c.update.Lock()
+	defer c.update.Unlock()
+	files, err := c.reg.Collect(c.opts.Root)
+	if err != nil {
+		return nil, err
+	}
+	bundles := make(map[string]bool)
+	for _, p := range paths {
+		if rel, ok := c.rel(p); ok {
+			bundles[bundleOf(rel)] = true
+		}
+	}
+	present := make(map[string]bool, len(files))
+	var stale []string
+	c.mu.RLock()
+	for _, pth := range files {
+		rel, _ := c.rel(pth)
+		present[rel] = true
+		if _, known := c.chunks[rel]; !known || inBundles(rel, bundles) {
+			stale = append(stale, pth)
+		}
+	}
+	c.mu.RUnlock()
+	parsed := make([]parsedFile, 0, len(stale))
+	for _, pth := range stale {
+		pf, err := c.parseFile(pth)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, pf)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var deltas []Delta
+	for _, pf := range parsed {
+		deltas = c.commitLocked(pf, deltas)
+	}
+	removed := make([]string, 0)
+	for rel := range c.chunks {
+		if !present[rel] {
+			removed = append(removed, rel)
+		}
+	}
+	sort.Strings(removed)
+	for _, rel := range removed {
+		for _, ch := range c.chunks[rel] {
+			deltas = append(deltas, Delta{Op: OpDelete, ID: ch.ID, Path: rel})
+		}
+		delete(c.chunks, rel)
+		delete(c.recipes, rel)
+	}
+	if len(deltas) > 0 {
+		c.version++
+	}
+	return deltas, nil
+}
+
+// Version：内容版本号；Load 与产生变更的 Refresh 之后递增，调用方据此判断派生索引是否需要重建。
+func (c *Catalog) Version() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version
+}
+
+// Chunks：返回当前全部分块（按路径与序号排序）。
+func (c *Catalog) Chunks() []types.Chunk {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]types.Chunk, 0, len(c.chunks)*6)
+	for _, rel := range c.sortedPaths() {
+		out = append(out, c.chunks[rel]...)
+	}
+	return out
+}
+
+// Recipes：返回当前全部结构化菜谱（按路径排序）。
+func (c *Catalog) Recipes() []*parser.Recipe {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]*parser.Recipe, 0, len(c.recipes))
+	for _, rel := range c.sortedPaths() {
+		out = append(out, c.recipes[rel]...)
+	}
+	return out
+}
+
+// parsedFile：单个文件的解析结果。
+type parsedFile struct {
+	rel     string
+	chunks  []types.Chunk
+	recipes []*parser.Recipe
+}
+
+// parseFile：解析单个文件并赋予稳定 ID；不访问目录状态，无需持锁。
+func (c *Catalog) parseFile(pth string) (parsedFile, error) {
+	rel, ok := c.rel(pth)
+	if !ok {
+		return parsedFile{}, fmt.Errorf("catalog: %s outside root %s", pth, c.opts.Root)
+	}
+	chunks, err := c.reg.ParseFiles([]string{pth}, c.opts)
+	if err != nil {
+		return parsedFile{}, err
+	}
+	AssignStableIDs(chunks)
+	recipes, err := c.reg.ParseRecipes([]string{pth}, c.opts)
+	if err != nil {
+		return parsedFile{}, err
+	}
+	return parsedFile{rel: rel, chunks: chunks, recipes: recipes}, nil
+}
+
+// commitLocked：折叠样板行后与目录中的旧分块对比并替换；调用方持有写锁。
+func (c *Catalog) commitLocked(pf parsedFile, deltas []Delta) []Delta {
+	chunks := pf.chunks
+	if c.bp != nil {
+		chunks = c.bp.Strip(chunks)
+	}
+	old := make([]ChunkEntry, 0, len(c.chunks[pf.rel]))
+	for _, ch := range c.chunks[pf.rel] {
+		old = append(old, ChunkEntry{ID: ch.ID, Hash: ChunkHash(ch)})
+	}
+	_, deltas = diffChunks(pf.rel, old, chunks, deltas)
+	c.chunks[pf.rel] = chunks
+	c.recipes[pf.rel] = pf.recipes
+	return deltas
+}
+
+// rel：计算相对 Root 的路径；不在 Root 之下时返回 false。
+func (c *Catalog) rel(p string) (string, bool) {
+	absRoot, err := filepath.Abs(c.opts.Root)
+	if err != nil {
+		return "", false
+	}
+	absP, err := filepath.Abs(p)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(absRoot, absP)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// sortedPaths：目录中已知文件的有序相对路径；调用方持有读锁。
+func (c *Catalog) sortedPaths() []string {
+	out := make([]string, 0, len(c.chunks))
+	for rel := range c.chunks {
+		out = append(out, rel)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// bundleOf：菜谱包路径；分类/菜名/文件 → 分类/菜名；分类/文件 → 自身；目录变更同样按前两级归并。
+func bundleOf(rel string) string {
+	parts := strings.Split(rel, string(filepath.Separator))
+	if len(parts) >= 3 {
+		return filepath.Join(parts[0], parts[1])
+	}
+	return rel
+}
+
+// inBundles：rel 是否属于任一受影响的菜谱包（包本身或其子路径）。
+func inBundles(rel string, bundles map[string]bool) bool {
+	for b := range bundles {
+		if rel == b || strings.HasPrefix(rel, b+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+// 文件功能：内存语料目录的单元测试；验证按菜谱包局部刷新、新增文件与删除墓碑，以及刷新解析期间查询不被阻塞。
+package corpus
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cook/internal/recipe/parser"
+	_ "cook/internal/recipe/parser/impl"
+	"cook/internal/recipe/parser/types"
+)
+
+func TestCatalogRefresh(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, body string) string {
+		full := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return full
+	}
+	a := write("meat/甲/甲.md", "# 甲的做法\n简介\n\n## 计算\n肉 100g")
+	write("meat/乙.md", "# 乙的做法\n简介")
+
+	cat := NewCatalog(parser.Default(), types.Options{ByHeader: true, Root: dir})
+	deltas, err := cat.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(deltas) != 3 || len(cat.Recipes()) != 2 {
+		t.Fatalf("load: %d deltas, %d recipes", len(deltas), len(cat.Recipes()))
+	}
+
+	// 未触及的菜谱包即使内容变化也不会重新解析
+	write("meat/乙.md", "# 乙的做法\n改动")
+	write("meat/甲/甲.md", "# 甲的做法\n简介\n\n## 计算\n肉 200g")
+	c := write("meat/丙.md", "# 丙的做法\n简介")
+	deltas, err = cat.Refresh([]string{a, c})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if len(deltas) != 2 || deltas[0].Path != filepath.Join("meat", "丙.md") || deltas[1].Chunk.Header != "## 计算" {
+		t.Fatalf("refresh: %+v", deltas)
+	}
+
+	if err := os.RemoveAll(filepath.Join(dir, "meat", "甲")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	deltas, err = cat.Refresh([]string{filepath.Join(dir, "meat", "甲")})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if len(deltas) != 2 || deltas[0].Op != OpDelete || len(cat.Recipes()) != 2 {
+		t.Fatalf("remove: %+v", deltas)
+	}
+}
+
+// gateParser：解析前等待 gate 的测试解析器；entered 在每次进入解析时收到通知。
+type gateParser struct {
+	entered chan struct{}
+	gate    chan struct{}
+}
+
+func (g *gateParser) Collect(root string) ([]string, error) { return nil, nil }
+
+func (g *gateParser) ParseFiles(paths []string, opts types.Options) ([]types.Chunk, error) {
+	g.entered <- struct{}{}
+	<-g.gate
+	return []types.Chunk{{Header: "# " + filepath.Base(paths[0]), Text: "正文", Path: paths[0]}}, nil
+}
+
+func TestCatalogRefreshUnlocked(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.gate"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g := &gateParser{entered: make(chan struct{}, 1), gate: make(chan struct{})}
+	reg := parser.NewRegistry()
+	if err := reg.Register(parser.Format{Name: "gate", Extensions: []string{".gate"}, New: func() parser.Parser { return g }}); err != nil {
+		t.Fatal(err)
+	}
+	cat := NewCatalog(reg, types.Options{Root: dir})
+	close(g.gate)
+	if _, err := cat.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	<-g.entered
+
+	g.gate = make(chan struct{})
+	done := make(chan []Delta)
+	go func() {
+		deltas, err := cat.Refresh([]string{filepath.Join(dir, "a.gate")})
+		if err != nil {
+			t.Errorf("refresh: %v", err)
+		}
+		done <- deltas
+	}()
+	<-g.entered // Refresh 正在解析
+	read := make(chan int)
+	go func() { read <- len(cat.Chunks()) + len(cat.Recipes()) }()
+	select {
+	case n := <-read:
+		if n != 1 {
+			t.Fatalf("read during refresh saw %d items", n)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("query blocked while refresh was parsing")
+	}
+	close(g.gate)
+	<-done
+}
@@ -0,0 +1,3 @@
+// 文件功能：语料管理包说明。
+// 包功能：corpus 包，负责语料文件清单（manifest）、增量解析与变更（delta）生成，供解析命令与索引流程复用。
+package corpus
@@ -0,0 +1,201 @@
+// 文件功能：增量解析；对比清单仅重新解析新增或变更的文件，输出分块级 upsert 与删除墓碑（tombstone）。
+package corpus
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/parser/types"
+)
+
+// 变更操作类型。
+const (
+	OpUpsert = "upsert" // 新增或内容变更的分块
+	OpDelete = "delete" // 已删除文件或章节的墓碑
+)
+
+// Delta：单条变更记录；下游索引器按顺序应用即可与本次解析结果保持一致。
+//   - Op：操作类型（upsert/delete）；
+//   - ID：稳定分块标识；
+//   - Path：来源文件相对路径；
+//   - Chunk：upsert 时的分块内容；delete 时为空。
+type Delta struct {
+	Op    string       `json:"op"`              // 操作类型
+	ID    string       `json:"id"`              // 分块标识
+	Path  string       `json:"path"`            // 来源路径
+	Chunk *types.Chunk `json:"chunk,omitempty"` // 分块内容
+}
+
+// Stats：增量解析统计。
+type Stats struct {
+	Files     int // 本次收集的文件数
+	Added     int // 新增文件数
+	Changed   int // 内容变更文件数
+	Removed   int // 删除文件数
+	Unchanged int // 未变更文件数
+	Upserts   int // upsert 记录数
+	Deletes   int // 墓碑记录数
+}
+
+// Incremental：基于上次清单执行增量解析。
+// 功能说明：
+//  1. 大小与修改时间均未变化的文件直接沿用清单记录；否则计算内容哈希，哈希未变同样跳过解析；
+//  2. 新增或变更的文件重新解析并赋予稳定 ID，仅内容哈希变化的分块输出 upsert，消失的分块输出 delete；
+//  3. 上次存在、本次未收集到的文件，其全部分块输出 delete。
+//
+// 参数说明：
+//   - p：解析器（通常为注册表）；
+//   - files：本次收集到的文件；
+//   - opts：解析选项；Root 必须为语料根目录，用于计算清单中的相对路径；
+//   - prev：上次的清单；为 nil 时视为首次运行（全部为 upsert）。
+//
+// 返回值说明：
+//   - []Delta：按文件路径排序的变更记录；
+//   - *Manifest：本次运行后的清单，调用方在成功写出变更后保存；
+//   - Stats：统计信息；
+//   - error：文件读取或解析失败时返回错误。
+func Incremental(p parser.Parser, files []string, opts types.Options, prev *Manifest) ([]Delta, *Manifest, Stats, error) {
+	if opts.Root == "" {
+		return nil, nil, Stats{}, fmt.Errorf("incremental: root is empty")
+	}
+	if prev == nil {
+		prev = NewManifest()
+	}
+	next := NewManifest()
+	next.Options = optionsFingerprint(opts)
+	// 关键逻辑：分块选项或元数据识别规则版本变化时旧分块全部失效，跳过「大小/时间/哈希未变」的快捷判断，但仍与旧分块对比以产出墓碑；
+	sameOpts := prev.Options == next.Options
+	st := Stats{Files: len(files)}
+	var deltas []Delta
+	for _, pth := range files {
+		rel, err := filepath.Rel(opts.Root, pth)
+		if err != nil {
+			return nil, nil, st, fmt.Errorf("incremental: %w", err)
+		}
+		fi, err := os.Stat(pth)
+		if err != nil {
+			return nil, nil, st, fmt.Errorf("incremental: stat %s: %w", pth, err)
+		}
+		old := prev.Files[rel]
+		if sameOpts && old != nil && old.Size == fi.Size() && old.ModTime == fi.ModTime().UnixNano() {
+			next.Files[rel] = old
+			st.Unchanged++
+			continue
+		}
+		hash, err := hashFile(pth)
+		if err != nil {
+			return nil, nil, st, fmt.Errorf("incremental: hash %s: %w", pth, err)
+		}
+		entry := &FileEntry{Path: rel, Size: fi.Size(), ModTime: fi.ModTime().UnixNano(), Hash: hash}
+		if sameOpts && old != nil && old.Hash == hash {
+			entry.Chunks = old.Chunks
+			next.Files[rel] = entry
+			st.Unchanged++
+			continue
+		}
+		chunks, err := p.ParseFiles([]string{pth}, opts)
+		if err != nil {
+			return nil, nil, st, err
+		}
+		AssignStableIDs(chunks)
+		var oldChunks []ChunkEntry
+		if old != nil {
+			oldChunks = old.Chunks
+			st.Changed++
+		} else {
+			st.Added++
+		}
+		entry.Chunks, deltas = diffChunks(rel, oldChunks, chunks, deltas)
+		next.Files[rel] = entry
+	}
+	removed := make([]string, 0)
+	for rel := range prev.Files {
+		if _, ok := next.Files[rel]; !ok {
+			removed = append(removed, rel)
+		}
+	}
+	sort.Strings(removed)
+	for _, rel := range removed {
+		st.Removed++
+		for _, ce := range prev.Files[rel].Chunks {
+			deltas = append(deltas, Delta{Op: OpDelete, ID: ce.ID, Path: rel})
+		}
+	}
+	for _, d := range deltas {
+		if d.Op == OpUpsert {
+			st.Upserts++
+		} else {
+			st.Deletes++
+		}
+	}
+	return deltas, next, st, nil
+}
+
+// diffChunks：对比单个文件新旧分块，追加 upsert/delete 记录并返回新的分块清单。
+func diffChunks(rel string, old []ChunkEntry, chunks []types.Chunk, deltas []Delta) ([]ChunkEntry, []Delta) {
+	oldHash := make(map[string]string, len(old))
+	for _, ce := range old {
+		oldHash[ce.ID] = ce.Hash
+	}
+	entries := make([]ChunkEntry, 0, len(chunks))
+	seen := make(map[string]bool, len(chunks))
+	for i := range chunks {
+		c := chunks[i]
+		h := ChunkHash(c)
+		entries = append(entries, ChunkEntry{ID: c.ID, Hash: h})
+		seen[c.ID] = true
+		if oldHash[c.ID] != h {
+			deltas = append(deltas, Delta{Op: OpUpsert, ID: c.ID, Path: rel, Chunk: &c})
+		}
+	}
+	for _, ce := range old {
+		if !seen[ce.ID] {
+			deltas = append(deltas, Delta{Op: OpDelete, ID: ce.ID, Path: rel})
+		}
+	}
+	return entries, deltas
+}
+
+// AssignStableIDs：以内容位置而非全局序号生成分块 ID，使同一章节在多次运行间保持相同标识。
+// 算法说明：DocID 取「路径 + 文档名」的哈希；分块 ID 取「路径 + 文档名 + 标题 + 同名标题序号」的哈希，
+// 因此插入或删除其他章节不会改变已有章节的 ID。全量解析输出也须经过本函数，增量变更的 ID 才能与全量输出对应。
+func AssignStableIDs(chunks []types.Chunk) {
+	dup := make(map[string]int)
+	for i := range chunks {
+		c := &chunks[i]
+		doc := c.Path + "\x00" + c.Name
+		key := doc + "\x00" + c.Header
+		n := dup[key]
+		dup[key]++
+		c.DocID = "doc-" + shortHash(doc, 12)
+		c.ID = "chunk-" + shortHash(fmt.Sprintf("%s\x00%d", key, n), 16)
+	}
+}
+
+// ChunkHash：分块内容哈希；忽略 ID 与 Source（可能含时间戳），其余字段（含文档内序号 Index）变化均视为内容变更。
+// 插入或删除章节时同一文件中后续分块的 Index 变化，这些分块随之 upsert，下游的 index 元数据与分块输出保持一致；
+// 其他文件不受影响。
+func ChunkHash(c types.Chunk) string {
+	c.ID, c.Source = "", ""
+	b, _ := json.Marshal(c)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// optionsFingerprint：影响分块结果的选项与元数据识别规则版本（parser.AnnotationVersion）的指纹。
+func optionsFingerprint(opts types.Options) string {
+	return fmt.Sprintf("byHeader=%t,chunk=%d,overlap=%d,annotations=%d", opts.ByHeader, opts.ChunkSize, opts.Overlap, parser.AnnotationVersion)
+}
+
+// shortHash：SHA-1 十六进制前缀。
+func shortHash(s string, n int) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])[:n]
+}
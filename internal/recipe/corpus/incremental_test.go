@@ -0,0 +1,88 @@
+// 文件功能：增量解析的单元测试；验证未变更跳过、规则版本变化时重新解析、章节级 upsert 与文件/章节删除墓碑。
+package corpus
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cook/internal/recipe/parser/impl"
+	"cook/internal/recipe/parser/types"
+)
+
+func TestIncremental(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) {
+		full := filepath.Join(dir, "staple", name)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		// 保证修改时间变化，避免同一时间粒度内的写入被视为未变更
+		later := time.Now().Add(time.Duration(len(body)) * time.Second)
+		_ = os.Chtimes(full, later, later)
+	}
+	write("a.md", "# 甲的做法\n简介\n\n## 计算\n米 100g\n\n## 操作\n- 煮")
+	write("b.md", "# 乙的做法\n简介")
+
+	p := impl.NewMarkdownParser()
+	opts := types.Options{ByHeader: true, Root: dir}
+	run := func(prev *Manifest) ([]Delta, *Manifest, Stats) {
+		files, err := p.Collect(dir)
+		if err != nil {
+			t.Fatalf("collect: %v", err)
+		}
+		deltas, next, st, err := Incremental(p, files, opts, prev)
+		if err != nil {
+			t.Fatalf("incremental: %v", err)
+		}
+		return deltas, next, st
+	}
+
+	deltas, m, st := run(nil)
+	if st.Added != 2 || st.Upserts != 4 || len(deltas) != 4 {
+		t.Fatalf("first run: %+v", st)
+	}
+	if _, _, st = run(m); st.Unchanged != 2 || st.Upserts+st.Deletes != 0 {
+		t.Fatalf("second run: %+v", st)
+	}
+	// 旧版元数据识别规则产出的清单：未修改的文件同样重新解析（分块内容未变，不产生 upsert）。
+	old := *m
+	old.Options = "byHeader=true,chunk=0,overlap=0"
+	if _, _, st = run(&old); st.Unchanged != 0 || st.Changed != 2 {
+		t.Fatalf("annotation version run: %+v", st)
+	}
+
+	write("a.md", "# 甲的做法\n简介\n\n## 计算\n米 200g")
+	if err := os.Remove(filepath.Join(dir, "staple", "b.md")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	deltas, m, st = run(m)
+	if st.Changed != 1 || st.Removed != 1 || st.Upserts != 1 || st.Deletes != 2 {
+		t.Fatalf("third run: %+v %+v", st, deltas)
+	}
+	if deltas[0].Op != OpUpsert || deltas[0].Chunk.Header != "## 计算" {
+		t.Fatalf("expected upsert of 计算, got %+v", deltas[0])
+	}
+
+	// 在已有章节之前插入新章节（不影响菜谱级元数据）：新章节 upsert，后续章节 ID 不变、以新序号 upsert，不产生墓碑。
+	write("a.md", "# 甲的做法\n简介\n\n## 小贴士\n多泡一会\n\n## 计算\n米 200g")
+	deltas, _, st = run(m)
+	if st.Upserts != 2 || st.Deletes != 0 || deltas[0].Chunk.Header != "## 小贴士" || deltas[1].Chunk.Index != 2 {
+		t.Fatalf("insert run: %+v %+v", st, deltas)
+	}
+
+	// 全量解析经 AssignStableIDs 后与增量变更的 ID 一致。
+	files, _ := p.Collect(dir)
+	full, err := p.ParseFiles(files, opts)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	AssignStableIDs(full)
+	if full[1].ID != deltas[0].ID {
+		t.Fatalf("full parse id %s != delta id %s", full[1].ID, deltas[0].ID)
+	}
+}
@@ -0,0 +1,122 @@
+// 文件功能：语料文件清单；记录每个文件的路径、大小、修改时间、内容哈希及其产出的分块，用于增量解析。
+package corpus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// manifestVersion：清单格式版本；不兼容变更时递增，旧清单将被视为不存在。
+const manifestVersion = 1
+
+// ChunkEntry：清单中的单个分块记录。
+//   - ID：稳定分块标识；
+//   - Hash：分块内容哈希（不含 ID 与来源时间戳）。
+type ChunkEntry struct {
+	ID   string `json:"id"`   // 分块标识
+	Hash string `json:"hash"` // 内容哈希
+}
+
+// FileEntry：清单中的单个文件记录。
+//   - Path：相对语料根目录的路径；
+//   - Size：文件大小（字节）；
+//   - ModTime：修改时间（Unix 纳秒）；
+//   - Hash：文件内容 SHA-256；
+//   - Chunks：该文件产出的分块。
+type FileEntry struct {
+	Path    string       `json:"path"`     // 相对路径
+	Size    int64        `json:"size"`     // 文件大小
+	ModTime int64        `json:"mod_time"` // 修改时间
+	Hash    string       `json:"hash"`     // 内容哈希
+	Chunks  []ChunkEntry `json:"chunks"`   // 分块记录
+}
+
+// Manifest：语料文件清单。
+//   - Version：格式版本；
+//   - Options：生成分块时的选项指纹；选项变化时全部文件需重新解析；
+//   - Files：以相对路径为键的文件记录。
+type Manifest struct {
+	Version int                   `json:"version"` // 格式版本
+	Options string                `json:"options"` // 选项指纹
+	Files   map[string]*FileEntry `json:"files"`   // 文件记录
+}
+
+// NewManifest：构造空清单。
+func NewManifest() *Manifest {
+	return &Manifest{Version: manifestVersion, Files: make(map[string]*FileEntry)}
+}
+
+// LoadManifest：读取清单文件。
+// 功能说明：文件不存在或版本不匹配时返回空清单（相当于首次全量解析）。
+// 参数说明：
+//   - path：清单文件路径。
+//
+// 返回值说明：
+//   - *Manifest：清单；
+//   - error：读取或反序列化失败时返回错误。
+func LoadManifest(path string) (*Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewManifest(), nil
+		}
+		return nil, fmt.Errorf("load manifest: %w", err)
+	}
+	m := NewManifest()
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("load manifest: %w", err)
+	}
+	if m.Version != manifestVersion {
+		return NewManifest(), nil
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]*FileEntry)
+	}
+	return m, nil
+}
+
+// Save：原子写出清单；先写临时文件再重命名，避免中断时留下半份清单。
+func (m *Manifest) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("save manifest: %w", err)
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save manifest: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".manifest-*")
+	if err != nil {
+		return fmt.Errorf("save manifest: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("save manifest: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("save manifest: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("save manifest: %w", err)
+	}
+	return nil
+}
+
+// hashFile：计算文件内容的 SHA-256 十六进制串。
+func hashFile(path string) (string, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, fh); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,53 @@
+// 文件功能：变更落地接口；索引流程将 Delta 交由 Sink 应用（写出日志或更新索引）。
+package corpus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Sink：变更接收方；Apply 需按顺序应用 deltas（同一 ID 后出现的记录覆盖先出现的）。
+type Sink interface {
+	Apply(ctx context.Context, deltas []Delta) error
+}
+
+// JSONLSink：将变更以 JSONL 形式追加写出；并发安全。
+type JSONLSink struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+// NewJSONLSink：构造写出到 w 的 JSONLSink。
+func NewJSONLSink(w io.Writer) *JSONLSink { return &JSONLSink{w: bufio.NewWriter(w)} }
+
+// Apply：逐条写出变更并刷新缓冲。
+func (s *JSONLSink) Apply(_ context.Context, deltas []Delta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enc := json.NewEncoder(s.w)
+	enc.SetEscapeHTML(false)
+	for _, d := range deltas {
+		if err := enc.Encode(d); err != nil {
+			return err
+		}
+	}
+	return s.w.Flush()
+}
+
+// Tee：依次将同一批变更交给多个 Sink；任一失败即返回其错误，后续 Sink 不再执行。
+func Tee(sinks ...Sink) Sink { return teeSink(sinks) }
+
+type teeSink []Sink
+
+// Apply：按顺序应用到每个 Sink。
+func (t teeSink) Apply(ctx context.Context, deltas []Delta) error {
+	for _, s := range t {
+		if err := s.Apply(ctx, deltas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// 文件功能：语料目录监听；基于 fsnotify 递归监听菜谱目录，对连续编辑进行去抖后批量回调。
+package corpus
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"cook/internal/recipe/parser"
+)
+
+// DefaultDebounce：默认去抖间隔；编辑器保存常伴随多次写入与重命名，间隔内的事件合并为一次回调。
+const DefaultDebounce = 500 * time.Millisecond
+
+// Watch：递归监听 root 目录，直到 ctx 取消。
+// 功能说明：
+//  1. 启动时为 root 及全部子目录（跳过以 . 开头的隐藏目录）注册监听；新建的子目录自动加入监听；
+//  2. 每个事件重置去抖计时器，计时器到期后以去重排序的路径集合调用 onChange；
+//  3. onChange 在监听协程中串行执行，执行期间到达的事件会在下一轮合并处理。
+//
+// 参数说明：
+//   - ctx：生命周期控制；
+//   - root：监听根目录；
+//   - debounce：去抖间隔；≤0 时使用 DefaultDebounce；
+//   - onChange：变更回调；参数为发生变化的文件或目录路径。
+//
+// 返回值说明：
+//   - error：监听器创建或初始注册失败时返回错误；ctx 取消时返回 nil。
+func Watch(ctx context.Context, root string, debounce time.Duration, onChange func(paths []string)) error {
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	defer w.Close()
+	if err := addTree(w, root); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(debounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if isHidden(root, ev.Name) {
+				continue
+			}
+			if ev.Has(fsnotify.Create) {
+				if st, err := os.Stat(ev.Name); err == nil && st.IsDir() {
+					if err := addTree(w, ev.Name); err != nil {
+						log.Printf("watch: add %s: %v", ev.Name, err)
+					}
+				}
+			}
+			pending[ev.Name] = true
+			timer.Reset(debounce)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("watch: %v", err)
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for p := range pending {
+				paths = append(paths, p)
+			}
+			sort.Strings(paths)
+			pending = make(map[string]bool)
+			onChange(paths)
+		}
+	}
+}
+
+// addTree：为目录及其全部非隐藏子目录注册监听。
+func addTree(w *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return w.Add(p)
+	})
+}
+
+// isHidden：路径中是否含有隐藏段（编辑器临时文件、.git 等），此类事件不触发刷新。
+// 例外：根目录下的忽略文件变更需要触发刷新，因此不视为隐藏。
+func isHidden(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == parser.IgnoreFileName {
+		return false
+	}
+	for _, seg := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.HasPrefix(seg, ".") && seg != "." && seg != ".." {
+			return true
+		}
+	}
+	return strings.HasSuffix(p, "~") || strings.HasSuffix(p, ".swp")
+}
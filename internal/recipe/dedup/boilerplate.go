@@ -0,0 +1,115 @@
+// 文件功能：样板行识别与折叠；出现在大部分文档中的相同文本行（如 Issue/PR 提示）对检索无益，索引时可剔除。
+package dedup
+
+import (
+	"sort"
+	"strings"
+
+	"cook/internal/recipe/parser/types"
+)
+
+// BoilerplateOptions：样板行判定参数。
+//   - MinShare：包含该行的文档占比下限（0~1）；
+//   - MinDocs：包含该行的文档数下限；语料很小时避免把偶然重复当作样板；
+//   - MinChars：归一化后的最小长度；过滤「- 盐」这类常见但有意义的短行。
+type BoilerplateOptions struct {
+	MinShare float64 // 文档占比下限
+	MinDocs  int     // 文档数下限
+	MinChars int     // 最小长度
+}
+
+// DefaultBoilerplateOptions：默认参数；一半以上文档共有、归一化后不少于 10 字的行视为样板。
+// 「预估烹饪难度」等带有信息量的模板行在各菜谱中取值不同，占比远低于一半，不会被误判。
+func DefaultBoilerplateOptions() BoilerplateOptions {
+	return BoilerplateOptions{MinShare: 0.5, MinDocs: 3, MinChars: 10}
+}
+
+// Boilerplate：已识别的样板行集合（按归一化文本匹配）；只读，可并发使用。
+type Boilerplate struct {
+	lines map[string]string // 归一化文本 → 首次出现的原文
+}
+
+// LearnBoilerplate：从语料中识别样板行。
+// 参数说明：
+//   - chunks：全量分块；按 DocID 统计每行出现的文档数；
+//   - opts：判定参数。
+//
+// 返回值说明：
+//   - *Boilerplate：样板行集合；可能为空集合，不为 nil。
+func LearnBoilerplate(chunks []types.Chunk, opts BoilerplateOptions) *Boilerplate {
+	docs := make(map[string]map[string]bool) // 归一化行 → 文档集合
+	original := make(map[string]string)
+	allDocs := make(map[string]bool)
+	for _, c := range chunks {
+		allDocs[c.DocID] = true
+		for _, line := range strings.Split(c.Text, "\n") {
+			n := Normalize(line)
+			if len([]rune(n)) < opts.MinChars {
+				continue
+			}
+			if docs[n] == nil {
+				docs[n] = make(map[string]bool)
+				original[n] = strings.TrimSpace(line)
+			}
+			docs[n][c.DocID] = true
+		}
+	}
+	b := &Boilerplate{lines: make(map[string]string)}
+	for n, set := range docs {
+		if len(set) >= opts.MinDocs && float64(len(set)) >= opts.MinShare*float64(len(allDocs)) {
+			b.lines[n] = original[n]
+		}
+	}
+	return b
+}
+
+// Lines：样板行原文（按字典序）。
+func (b *Boilerplate) Lines() []string {
+	out := make([]string, 0, len(b.lines))
+	for _, l := range b.lines {
+		out = append(out, l)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Strip：剔除分块中的样板行。
+// 功能说明：正文仅由样板行（及空行）组成的分块整体折叠（不输出）；其余分块去掉样板行后保留。
+// 原本就没有正文的分块（如只有标题、正文在子标题下的「## 操作」）保持不变。
+// 参数说明：
+//   - chunks：待处理分块；不会被修改。
+//
+// 返回值说明：
+//   - []types.Chunk：处理后的分块；序号与标识保持原值。
+func (b *Boilerplate) Strip(chunks []types.Chunk) []types.Chunk {
+	if len(b.lines) == 0 {
+		return chunks
+	}
+	out := make([]types.Chunk, 0, len(chunks))
+	for _, c := range chunks {
+		if strings.TrimSpace(c.Text) == "" {
+			out = append(out, c)
+			continue
+		}
+		lines := strings.Split(c.Text, "\n")
+		kept := lines[:0:0]
+		stripped := false
+		for _, line := range lines {
+			if _, ok := b.lines[Normalize(line)]; ok {
+				stripped = true
+				continue
+			}
+			kept = append(kept, line)
+		}
+		if !stripped {
+			out = append(out, c)
+			continue
+		}
+		c.Text = strings.TrimSpace(strings.Join(kept, "\n"))
+		if c.Text == "" {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,257 @@
+// 文件功能：近重复聚类；在菜谱（文档）或章节（分块）粒度上发现相似度超过阈值的条目并合并为簇。
+package dedup
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"cook/internal/recipe/parser/types"
+)
+
+// 聚类粒度。
+const (
+	LevelRecipe  = "recipe"  // 以文档为单位，拼接全部分块正文
+	LevelSection = "section" // 以分块为单位
+)
+
+// Options：聚类参数。
+//   - Level：聚类粒度（recipe/section）；
+//   - Threshold：判定为近重复的最小 Jaccard 相似度估计；
+//   - Shingle：shingle 字符数；
+//   - Hashes：MinHash 哈希函数个数；须能被 Bands 整除；
+//   - Bands：LSH 分段数；段数越多召回越高、候选越多；0 表示按 Threshold 自动选择；
+//   - MinChars：归一化后短于该长度的条目不参与聚类（如只有标题的分块）；
+//   - Boilerplate：非 nil 时先剔除样板行再计算相似度，避免模板文字抬高所有菜谱之间的相似度。
+type Options struct {
+	Level       string       // 聚类粒度
+	Threshold   float64      // 相似度阈值
+	Shingle     int          // shingle 长度
+	Hashes      int          // 哈希函数个数
+	Bands       int          // LSH 分段数
+	MinChars    int          // 最小长度
+	Boilerplate *Boilerplate // 样板行
+}
+
+// DefaultOptions：默认参数；128 个哈希，分段数随阈值自动选择。
+func DefaultOptions() Options {
+	return Options{Level: LevelRecipe, Threshold: 0.5, Shingle: 3, Hashes: 128, MinChars: 30}
+}
+
+// Member：簇成员。
+type Member struct {
+	ID     string `json:"id"`               // 文档或分块标识
+	Path   string `json:"path"`             // 来源路径
+	Header string `json:"header,omitempty"` // 分块标题（章节粒度）
+}
+
+// Pair：簇内一对近重复条目及其相似度。
+type Pair struct {
+	A          int     `json:"a"`          // Members 下标
+	B          int     `json:"b"`          // Members 下标
+	Similarity float64 `json:"similarity"` // 相似度估计
+}
+
+// Cluster：近重复簇。
+type Cluster struct {
+	Members []Member `json:"members"` // 成员（按路径排序）
+	Pairs   []Pair   `json:"pairs"`   // 超过阈值的成员对（按相似度降序）
+	Max     float64  `json:"max"`     // 最高相似度
+}
+
+// item：参与聚类的条目。
+type item struct {
+	member Member
+	text   string
+}
+
+// Find：发现近重复簇。
+// 功能说明：
+//  1. 按 Level 组织条目（文档粒度拼接同一 DocID 的分块正文）；
+//  2. 计算 shingle 的 MinHash 签名，以 LSH 分桶得到候选对，再以签名相似度复核；
+//  3. 超过阈值的条目对以并查集合并为簇。
+//
+// 参数说明：
+//   - chunks：解析得到的分块；
+//   - opts：聚类参数；零值字段使用 DefaultOptions 的取值。
+//
+// 返回值说明：
+//   - []Cluster：成员数 ≥2 的簇；按成员数降序、最高相似度降序排序。
+func Find(chunks []types.Chunk, opts Options) []Cluster {
+	opts = withDefaults(opts)
+	if opts.Boilerplate != nil {
+		chunks = opts.Boilerplate.Strip(chunks)
+	}
+	items := collectItems(chunks, opts.Level)
+
+	mh := NewMinHasher(opts.Hashes, 1)
+	kept := make([]item, 0, len(items))
+	sigs := make([][]uint64, 0, len(items))
+	for _, it := range items {
+		if len([]rune(Normalize(it.text))) < opts.MinChars {
+			continue
+		}
+		kept = append(kept, it)
+		sigs = append(sigs, mh.Signature(Shingles(it.text, opts.Shingle)))
+	}
+
+	parent := make([]int, len(kept))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	type scored struct {
+		a, b int
+		sim  float64
+	}
+	var pairs []scored
+	for _, p := range candidates(sigs, opts.Bands) {
+		sim := Similarity(sigs[p[0]], sigs[p[1]])
+		if sim < opts.Threshold {
+			continue
+		}
+		pairs = append(pairs, scored{p[0], p[1], sim})
+		parent[find(p[0])] = find(p[1])
+	}
+
+	groups := make(map[int][]int)
+	for i := range kept {
+		r := find(i)
+		groups[r] = append(groups[r], i)
+	}
+	var out []Cluster
+	for _, idx := range groups {
+		if len(idx) < 2 {
+			continue
+		}
+		sort.Slice(idx, func(i, j int) bool { return lessMember(kept[idx[i]].member, kept[idx[j]].member) })
+		pos := make(map[int]int, len(idx))
+		c := Cluster{Members: make([]Member, 0, len(idx))}
+		for i, k := range idx {
+			pos[k] = i
+			c.Members = append(c.Members, kept[k].member)
+		}
+		for _, p := range pairs {
+			a, okA := pos[p.a]
+			b, okB := pos[p.b]
+			if !okA || !okB {
+				continue
+			}
+			if a > b {
+				a, b = b, a
+			}
+			c.Pairs = append(c.Pairs, Pair{A: a, B: b, Similarity: p.sim})
+			c.Max = max(c.Max, p.sim)
+		}
+		sort.Slice(c.Pairs, func(i, j int) bool {
+			if c.Pairs[i].Similarity != c.Pairs[j].Similarity {
+				return c.Pairs[i].Similarity > c.Pairs[j].Similarity
+			}
+			if c.Pairs[i].A != c.Pairs[j].A {
+				return c.Pairs[i].A < c.Pairs[j].A
+			}
+			return c.Pairs[i].B < c.Pairs[j].B
+		})
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i].Members) != len(out[j].Members) {
+			return len(out[i].Members) > len(out[j].Members)
+		}
+		if out[i].Max != out[j].Max {
+			return out[i].Max > out[j].Max
+		}
+		return lessMember(out[i].Members[0], out[j].Members[0])
+	})
+	return out
+}
+
+// collectItems：按粒度组织条目；文档粒度保持首次出现顺序。
+func collectItems(chunks []types.Chunk, level string) []item {
+	if level == LevelSection {
+		out := make([]item, 0, len(chunks))
+		for _, c := range chunks {
+			out = append(out, item{member: Member{ID: c.ID, Path: c.Path, Header: c.Header}, text: c.Text})
+		}
+		return out
+	}
+	byDoc := make(map[string]int)
+	var out []item
+	var texts []*strings.Builder
+	for _, c := range chunks {
+		i, ok := byDoc[c.DocID]
+		if !ok {
+			i = len(out)
+			byDoc[c.DocID] = i
+			out = append(out, item{member: Member{ID: c.DocID, Path: c.Path}})
+			texts = append(texts, &strings.Builder{})
+		}
+		texts[i].WriteString(c.Text)
+		texts[i].WriteByte('\n')
+	}
+	for i := range out {
+		out[i].text = texts[i].String()
+	}
+	return out
+}
+
+// withDefaults：以 DefaultOptions 补齐零值字段；哈希数不能被段数整除时向上取整。
+func withDefaults(o Options) Options {
+	d := DefaultOptions()
+	if o.Level == "" {
+		o.Level = d.Level
+	}
+	if o.Threshold <= 0 {
+		o.Threshold = d.Threshold
+	}
+	if o.Shingle <= 0 {
+		o.Shingle = d.Shingle
+	}
+	if o.Hashes <= 0 {
+		o.Hashes = d.Hashes
+	}
+	if o.Bands <= 0 {
+		o.Bands = bandsFor(o.Hashes, o.Threshold)
+	}
+	if r := o.Hashes % o.Bands; r != 0 {
+		o.Hashes += o.Bands - r
+	}
+	if o.MinChars <= 0 {
+		o.MinChars = d.MinChars
+	}
+	return o
+}
+
+// bandsFor：自动选择 LSH 分段数。
+// 算法说明：b 段、每段 r 行时，相似度为 s 的一对成为候选的概率为 1-(1-s^r)^b，其 S 形曲线拐点约为 (1/b)^(1/r)；
+// 在 hashes 的约数中选择每段行数最多、且拐点不高于 threshold-0.1 的组合，使阈值附近的条目对大概率进入候选。
+func bandsFor(hashes int, threshold float64) int {
+	best := hashes
+	for r := 1; r <= hashes; r++ {
+		if hashes%r != 0 {
+			continue
+		}
+		b := hashes / r
+		if math.Pow(1/float64(b), 1/float64(r)) <= threshold-0.1 {
+			best = b
+		}
+	}
+	return best
+}
+
+// lessMember：成员排序（路径、标题、标识）。
+func lessMember(a, b Member) bool {
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	if a.Header != b.Header {
+		return a.Header < b.Header
+	}
+	return a.ID < b.ID
+}
@@ -0,0 +1,82 @@
+// 文件功能：近重复检测的单元测试；验证 MinHash 相似度、菜谱/章节聚类与样板行折叠。
+package dedup
+
+import (
+	"strings"
+	"testing"
+
+	"cook/internal/recipe/parser/types"
+)
+
+const footer = "如果您遵循本指南的制作流程而发现有问题或可以改进的流程，请提出 Issue 或 Pull request 。"
+
+func corpus() []types.Chunk {
+	doc := func(id, path, intro, steps string) []types.Chunk {
+		return []types.Chunk{
+			{ID: id + "-1", DocID: id, Path: path, Header: "# 做法", Text: intro},
+			{ID: id + "-2", DocID: id, Path: path, Header: "## 操作", Text: steps},
+			{ID: id + "-3", DocID: id, Path: path, Header: "## 附加内容", Text: footer},
+		}
+	}
+	var out []types.Chunk
+	out = append(out, doc("a", "aquatic/红烧鱼.md", "红烧鱼是一道经典的家常菜，鱼肉鲜嫩，酱汁浓郁。",
+		"- 鱼洗净改刀，两面抹盐腌制十分钟\n- 热锅凉油，鱼下锅煎至两面金黄\n- 加入葱姜蒜爆香，倒入生抽老抽和清水\n- 大火烧开转小火炖十五分钟收汁")...)
+	out = append(out, doc("b", "aquatic/红烧鲤鱼.md", "红烧鲤鱼是一道经典的家常菜，鱼肉鲜嫩，酱汁浓郁。",
+		"- 鲤鱼洗净改刀，两面抹盐腌制十分钟\n- 热锅凉油，鱼下锅煎至两面金黄\n- 加入葱姜蒜爆香，倒入生抽老抽和清水\n- 大火烧开转小火炖十五分钟收汁")...)
+	out = append(out, doc("c", "vegetable_dish/拍黄瓜.md", "拍黄瓜清爽开胃，夏天必备凉菜。",
+		"- 黄瓜拍碎切段\n- 加蒜末、香醋、香油和少许白糖拌匀\n- 冷藏半小时口感更佳")...)
+	return out
+}
+
+func TestSimilarity(t *testing.T) {
+	mh := NewMinHasher(128, 1)
+	a := mh.Signature(Shingles("热锅凉油，鱼下锅煎至两面金黄", 3))
+	b := mh.Signature(Shingles("热锅 凉油！鱼下锅煎至两面金黄", 3))
+	c := mh.Signature(Shingles("黄瓜拍碎切段，加蒜末拌匀", 3))
+	if s := Similarity(a, b); s != 1 {
+		t.Fatalf("punctuation should not matter: %v", s)
+	}
+	if s := Similarity(a, c); s > 0.2 {
+		t.Fatalf("unrelated texts too similar: %v", s)
+	}
+}
+
+func TestFindRecipesAndSections(t *testing.T) {
+	chunks := corpus()
+	bp := LearnBoilerplate(chunks, DefaultBoilerplateOptions())
+
+	opts := DefaultOptions()
+	opts.Boilerplate = bp
+	clusters := Find(chunks, opts)
+	if len(clusters) != 1 || len(clusters[0].Members) != 2 || clusters[0].Members[1].Path != "aquatic/红烧鲤鱼.md" {
+		t.Fatalf("recipe clusters: %+v", clusters)
+	}
+
+	// 简介短于 MinChars 不参与章节聚类，只有两篇「操作」成簇
+	opts.Level = LevelSection
+	clusters = Find(chunks, opts)
+	if len(clusters) != 1 || clusters[0].Members[0].ID != "a-2" || clusters[0].Members[1].ID != "b-2" {
+		t.Fatalf("section clusters: %+v", clusters)
+	}
+}
+
+func TestBoilerplateStrip(t *testing.T) {
+	chunks := corpus()
+	chunks[2].Text = "鱼要选新鲜的。\n\n" + footer
+	bp := LearnBoilerplate(chunks, DefaultBoilerplateOptions())
+	if lines := bp.Lines(); len(lines) != 1 || lines[0] != footer {
+		t.Fatalf("boilerplate: %q", lines)
+	}
+	out := bp.Strip(chunks)
+	if len(out) != 7 {
+		t.Fatalf("expected 2 footer-only chunks collapsed, got %d chunks", len(out))
+	}
+	if out[2].ID != "a-3" || out[2].Text != "鱼要选新鲜的。" {
+		t.Fatalf("footer not stripped: %+v", out[2])
+	}
+	for _, c := range out {
+		if strings.Contains(c.Text, "Pull request") {
+			t.Fatalf("footer left in %s", c.ID)
+		}
+	}
+}
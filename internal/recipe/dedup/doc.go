@@ -0,0 +1,3 @@
+// 文件功能：近重复检测包说明。
+// 包功能：dedup 包，基于字符 shingle 与 MinHash/LSH 发现近似相同的菜谱与章节，并识别跨文档重复的样板行（如 Issue/PR 提示），供索引时折叠。
+package dedup
@@ -0,0 +1,138 @@
+// 文件功能：文本归一化、字符 shingle 与 MinHash 签名；LSH 分桶生成候选对。
+package dedup
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Normalize：相似度计算用的归一化文本；仅保留字母、数字与汉字并转为小写，忽略空白、标点与 Markdown 标记。
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// Shingles：归一化文本的 k 字符 shingle 哈希集合（去重）；文本短于 k 时整体作为一个 shingle。
+func Shingles(text string, k int) []uint64 {
+	runes := []rune(Normalize(text))
+	if len(runes) == 0 {
+		return nil
+	}
+	if k <= 0 {
+		k = 1
+	}
+	if len(runes) < k {
+		k = len(runes)
+	}
+	seen := make(map[uint64]bool, len(runes))
+	out := make([]uint64, 0, len(runes))
+	for i := 0; i+k <= len(runes); i++ {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(string(runes[i : i+k])))
+		v := h.Sum64()
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// MinHasher：MinHash 签名器；同一签名器产生的签名之间可比较。
+type MinHasher struct {
+	seeds []uint64
+}
+
+// NewMinHasher：构造 n 个哈希函数的签名器；seed 相同时结果可复现。
+func NewMinHasher(n int, seed uint64) *MinHasher {
+	m := &MinHasher{seeds: make([]uint64, n)}
+	s := seed
+	for i := range m.seeds {
+		s = splitmix64(s)
+		m.seeds[i] = s
+	}
+	return m
+}
+
+// Signature：计算 shingle 集合的 MinHash 签名；空集合返回全为 MaxUint64 的签名。
+func (m *MinHasher) Signature(shingles []uint64) []uint64 {
+	sig := make([]uint64, len(m.seeds))
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for _, x := range shingles {
+		for i, s := range m.seeds {
+			if h := splitmix64(x ^ s); h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// Similarity：两个签名的 Jaccard 相似度估计（相同位置取值相等的比例）。
+func Similarity(a, b []uint64) float64 {
+	n := min(len(a), len(b))
+	if n == 0 {
+		return 0
+	}
+	eq := 0
+	for i := 0; i < n; i++ {
+		if a[i] == b[i] {
+			eq++
+		}
+	}
+	return float64(eq) / float64(n)
+}
+
+// candidates：LSH 分桶；签名切分为 bands 段，任一段完全相同的两项成为候选对。
+// 返回值为 i<j 的下标对，已去重。
+func candidates(sigs [][]uint64, bands int) [][2]int {
+	if len(sigs) == 0 || bands <= 0 {
+		return nil
+	}
+	rows := len(sigs[0]) / bands
+	if rows == 0 {
+		rows, bands = 1, len(sigs[0])
+	}
+	seen := make(map[[2]int]bool)
+	var out [][2]int
+	for b := 0; b < bands; b++ {
+		buckets := make(map[uint64][]int)
+		for i, sig := range sigs {
+			h := uint64(b)
+			for _, v := range sig[b*rows : (b+1)*rows] {
+				h = splitmix64(h ^ v)
+			}
+			buckets[h] = append(buckets[h], i)
+		}
+		for _, idx := range buckets {
+			for x := 0; x < len(idx); x++ {
+				for y := x + 1; y < len(idx); y++ {
+					p := [2]int{idx[x], idx[y]}
+					if !seen[p] {
+						seen[p] = true
+						out = append(out, p)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// splitmix64：64 位整数混淆函数，用作由种子派生的哈希族。
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,354 @@
+// 文件功能：持久化向量缓存；以「模型标识 + 规范化文本哈希」为键保存向量，包装任意 Embedder，
+// 未命中的文本才交给后端；快照以 gob 编码，写入同目录临时文件后重命名。
+package embedding
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheVersion：缓存文件格式版本；不兼容的变更需递增。
+const cacheVersion = 1
+
+// cacheEntry：一条缓存；向量以 float32 保存以减半体积。
+type cacheEntry struct {
+	Model   string
+	Vec     []float32
+	Created int64 // Unix 秒
+	Used    int64 // 最近一次命中或写入（Unix 秒）
+	Hits    int64 // 累计命中次数
+}
+
+// cacheFile：缓存文件内容。
+type cacheFile struct {
+	Version int
+	Entries map[string]*cacheEntry
+}
+
+// Cache：持久化向量缓存；并发安全。
+// 功能说明：键由模型标识（Embedder.Model，含维度）与规范化文本的 SHA-256 组成，更换模型或维度后旧向量不会被命中；
+// 写入只在 Save 时落盘。多个进程共用同一文件时，Save 会合并磁盘上由其他进程新增的条目。
+type Cache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	removed map[string]bool // 本进程 Prune 删除的键；Save 合并时不恢复
+	dirty   bool
+	hits    int64
+	misses  int64
+}
+
+// OpenCache：打开缓存文件；文件不存在时为空缓存，首次 Save 时创建。
+// 返回值说明：
+//   - error：文件无法读取或格式版本不符时返回错误。
+func OpenCache(path string) (*Cache, error) {
+	c := &Cache{path: path, entries: map[string]*cacheEntry{}, removed: map[string]bool{}}
+	entries, err := readCache(path)
+	if err != nil {
+		return nil, err
+	}
+	if entries != nil {
+		c.entries = entries
+	}
+	return c, nil
+}
+
+// Path：缓存文件路径。
+func (c *Cache) Path() string { return c.path }
+
+// cacheKey：模型标识与规范化文本（去掉首尾空白、连续空白折叠为一个空格）的 SHA-256。
+func cacheKey(model, text string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(text), " ")))
+	return model + "|" + hex.EncodeToString(sum[:])
+}
+
+// Wrap：返回经缓存的 Embedder；c 为 nil 时原样返回 e。
+func (c *Cache) Wrap(e Embedder) Embedder {
+	if c == nil {
+		return e
+	}
+	return &cached{inner: e, cache: c}
+}
+
+// cached：经缓存的 Embedder。
+type cached struct {
+	inner Embedder
+	cache *Cache
+}
+
+func (e *cached) Dimensions() int { return e.inner.Dimensions() }
+func (e *cached) Model() string   { return e.inner.Model() }
+
+// EmbedStrings：先查缓存，未命中的文本（同一批内去重）一次交给后端，结果写回缓存。
+// 未命中时返回的向量同样截断为 float32 精度，与之后命中时完全相同，重复索引不会因精度差异重写文档。
+// 返回值说明：
+//   - error：后端失败或返回条数不符时返回错误；已命中的结果不会写回。
+func (e *cached) EmbedStrings(ctx context.Context, texts []string) ([][]float64, error) {
+	model := e.inner.Model()
+	out := make([][]float64, len(texts))
+	keys := make([]string, len(texts))
+	pending := map[string][]int{}
+	var missTexts, missKeys []string
+	for i, t := range texts {
+		keys[i] = cacheKey(model, t)
+		if v, ok := e.cache.get(keys[i]); ok {
+			out[i] = v
+			continue
+		}
+		if _, ok := pending[keys[i]]; !ok {
+			missTexts = append(missTexts, t)
+			missKeys = append(missKeys, keys[i])
+		}
+		pending[keys[i]] = append(pending[keys[i]], i)
+	}
+	if len(missTexts) == 0 {
+		return out, nil
+	}
+	vecs, err := e.inner.EmbedStrings(ctx, missTexts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vecs) != len(missTexts) {
+		return nil, fmt.Errorf("embedding: %s returned %d vectors for %d inputs", model, len(vecs), len(missTexts))
+	}
+	for j, v := range vecs {
+		e.cache.put(missKeys[j], model, v)
+		v = float32Precision(v)
+		for _, i := range pending[missKeys[j]] {
+			out[i] = v
+		}
+	}
+	return out, nil
+}
+
+// get：命中时更新使用信息并返回向量副本。
+func (c *Cache) get(key string) ([]float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	e.Hits++
+	e.Used = time.Now().Unix()
+	c.dirty = true
+	v := make([]float64, len(e.Vec))
+	for i, x := range e.Vec {
+		v[i] = float64(x)
+	}
+	return v, true
+}
+
+// float32Precision：将向量各分量截断为 float32 精度（缓存的存储精度）。
+func float32Precision(v []float64) []float64 {
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = float64(float32(x))
+	}
+	return out
+}
+
+// put：写入一条缓存。
+func (c *Cache) put(key, model string, v []float64) {
+	vec := make([]float32, len(v))
+	for i, x := range v {
+		vec[i] = float32(x)
+	}
+	now := time.Now().Unix()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &cacheEntry{Model: model, Vec: vec, Created: now, Used: now}
+	delete(c.removed, key)
+	c.dirty = true
+}
+
+// CacheStats：缓存统计。
+//   - Path/Bytes：文件路径与磁盘大小（未保存时为 0）；
+//   - Entries：条目数；Models：各模型的条目数；
+//   - Vectors：向量总维数（估算内存占用，×4 字节）；
+//   - TotalHits：条目累计命中次数（跨进程持久化）；
+//   - Hits/Misses：本进程的命中与未命中次数；
+//   - Oldest/LastUsed：最早写入与最近使用时间。
+type CacheStats struct {
+	Path      string         `json:"path"`
+	Bytes     int64          `json:"bytes"`
+	Entries   int            `json:"entries"`
+	Models    map[string]int `json:"models"`
+	Vectors   int64          `json:"vector_values"`
+	TotalHits int64          `json:"total_hits"`
+	Hits      int64          `json:"hits"`
+	Misses    int64          `json:"misses"`
+	Oldest    time.Time      `json:"oldest,omitempty"`
+	LastUsed  time.Time      `json:"last_used,omitempty"`
+}
+
+// Stats：返回缓存统计。
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := CacheStats{Path: c.path, Entries: len(c.entries), Models: map[string]int{}, Hits: c.hits, Misses: c.misses}
+	if fi, err := os.Stat(c.path); err == nil {
+		s.Bytes = fi.Size()
+	}
+	var oldest, used int64
+	for _, e := range c.entries {
+		s.Models[e.Model]++
+		s.Vectors += int64(len(e.Vec))
+		s.TotalHits += e.Hits
+		if oldest == 0 || e.Created < oldest {
+			oldest = e.Created
+		}
+		used = max(used, e.Used)
+	}
+	if oldest > 0 {
+		s.Oldest = time.Unix(oldest, 0)
+		s.LastUsed = time.Unix(used, 0)
+	}
+	return s
+}
+
+// PruneOptions：清理条件（满足任一即删除）。
+//   - KeepModels：只保留这些模型的条目；为空时不按模型清理；
+//   - UnusedFor：删除超过该时长未被使用的条目；≤0 时不按时间清理；
+//   - MaxEntries：按最近使用时间保留至多 MaxEntries 条；≤0 时不限。
+type PruneOptions struct {
+	KeepModels []string
+	UnusedFor  time.Duration
+	MaxEntries int
+}
+
+// Prune：按条件删除条目并返回删除数；删除在 Save 后落盘。
+func (c *Cache) Prune(opts PruneOptions) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keep := map[string]bool{}
+	for _, m := range opts.KeepModels {
+		keep[m] = true
+	}
+	cutoff := int64(0)
+	if opts.UnusedFor > 0 {
+		cutoff = time.Now().Add(-opts.UnusedFor).Unix()
+	}
+	n := 0
+	drop := func(k string) {
+		delete(c.entries, k)
+		c.removed[k] = true
+		n++
+	}
+	for k, e := range c.entries {
+		if (len(keep) > 0 && !keep[e.Model]) || e.Used < cutoff {
+			drop(k)
+		}
+	}
+	if opts.MaxEntries > 0 && len(c.entries) > opts.MaxEntries {
+		keys := make([]string, 0, len(c.entries))
+		for k := range c.entries {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return c.entries[keys[i]].Used > c.entries[keys[j]].Used })
+		for _, k := range keys[opts.MaxEntries:] {
+			drop(k)
+		}
+	}
+	if n > 0 {
+		c.dirty = true
+	}
+	return n
+}
+
+// Save：有改动时写出缓存；c 为 nil 时为空操作。
+// 功能说明：写出前读取磁盘上的文件，合并其他进程新增、本进程未持有且未清理的条目；写入临时文件后重命名。
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	disk, err := readCache(c.path)
+	if err != nil {
+		return err
+	}
+	for k, e := range disk {
+		if _, ok := c.entries[k]; !ok && !c.removed[k] {
+			c.entries[k] = e
+		}
+	}
+	if err := writeCache(c.path, c.entries); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// readCache：读取缓存文件；文件不存在时返回 nil。
+func readCache(path string) (map[string]*cacheEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("embedding: cache: %w", err)
+	}
+	defer f.Close()
+	var cf cacheFile
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&cf); err != nil {
+		return nil, fmt.Errorf("embedding: read cache %s: %w", path, err)
+	}
+	if cf.Version != cacheVersion {
+		return nil, fmt.Errorf("embedding: %s has cache version %d, want %d", path, cf.Version, cacheVersion)
+	}
+	return cf.Entries, nil
+}
+
+// writeCache：原子写出缓存文件。
+func writeCache(path string, entries map[string]*cacheEntry) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("embedding: cache: %w", err)
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("embedding: cache: %w", err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // 重命名成功后为空操作
+	w := bufio.NewWriter(f)
+	err = f.Chmod(0o644)
+	if err == nil {
+		err = gob.NewEncoder(w).Encode(&cacheFile{Version: cacheVersion, Entries: entries})
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		return fmt.Errorf("embedding: write cache: %w", err)
+	}
+	return nil
+}
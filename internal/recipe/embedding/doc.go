@@ -0,0 +1,5 @@
+// 文件功能：向量化包说明。
+// 包功能：embedding 包，定义文本向量化接口（方法签名与 Eino embedding.Embedder 一致），
+// 提供 OpenAI 兼容接口后端与离线确定性的哈希 n-gram 后端；后者无需凭据，供本地开发与测试使用；
+// Cache 以模型与文本哈希为键持久化向量，包装任意后端，避免重复向量化未变更的文本。
+package embedding
@@ -0,0 +1,80 @@
+// 文件功能：向量化接口与后端选择。
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// 后端名称；对应配置 embedding.provider。
+const (
+	ProviderOpenAI = "openai" // OpenAI 兼容接口（OpenAI、DeepSeek、本地 vLLM/Ollama 等）
+	ProviderHash   = "hash"   // 离线哈希 n-gram 向量
+)
+
+// Embedder：文本向量化接口。
+//   - EmbedStrings：批量向量化；返回向量与输入一一对应；方法签名与 Eino embedding.Embedder 一致（省略可变选项）；
+//   - Dimensions：向量维度；远程后端未显式配置维度时，首次调用前返回 0；
+//   - Model：模型标识；不同模型的向量不可混用，索引与缓存以此区分。
+type Embedder interface {
+	EmbedStrings(ctx context.Context, texts []string) ([][]float64, error)
+	Dimensions() int
+	Model() string
+}
+
+// Config：后端选择与参数。
+//   - Provider：后端名称（openai/hash）；为空时使用 hash；
+//   - OpenAI：OpenAI 兼容后端参数；
+//   - Dimensions：hash 后端的向量维度；openai 后端以 OpenAI.Dimensions 为准。
+type Config struct {
+	Provider   string
+	OpenAI     OpenAIOptions
+	Dimensions int
+}
+
+// New：按配置构造向量化后端。
+// 返回值说明：
+//   - Embedder：后端实例；
+//   - error：后端名称未知或参数无效时返回错误。
+func New(cfg Config) (Embedder, error) {
+	switch cfg.Provider {
+	case "", ProviderHash:
+		return NewHash(cfg.Dimensions), nil
+	case ProviderOpenAI:
+		return NewOpenAI(cfg.OpenAI)
+	}
+	return nil, fmt.Errorf("embedding: unknown provider %q", cfg.Provider)
+}
+
+// Cosine：余弦相似度；任一向量为零向量或维度不同时返回 0。
+func Cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// normalize：原地 L2 归一化；零向量保持不变。
+func normalize(v []float64) {
+	var n float64
+	for _, x := range v {
+		n += x * x
+	}
+	if n == 0 {
+		return
+	}
+	n = math.Sqrt(n)
+	for i := range v {
+		v[i] /= n
+	}
+}
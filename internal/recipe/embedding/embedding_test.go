@@ -0,0 +1,196 @@
+// 文件功能：向量化后端的单元测试；验证哈希后端的确定性与相似度，以及 OpenAI 兼容后端的分批、重试与错误处理（httptest 模拟服务），以及向量缓存的命中、持久化、模型隔离与清理。
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHash(t *testing.T) {
+	h := NewHash(256)
+	vecs, err := h.EmbedStrings(context.Background(), []string{"番茄炒蛋", "番茄炒雞蛋", "红烧排骨", "", "Tomato Egg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := NewHash(256).EmbedStrings(context.Background(), []string{"番茄炒蛋"})
+	if Cosine(vecs[0], again[0]) != 1 {
+		t.Fatal("hash embedding should be deterministic")
+	}
+	if near, far := Cosine(vecs[0], vecs[1]), Cosine(vecs[0], vecs[2]); near < 0.5 || far > 0.2 {
+		t.Fatalf("similarity: near %.2f far %.2f", near, far)
+	}
+	if Cosine(vecs[3], vecs[0]) != 0 || len(vecs[4]) != 256 {
+		t.Fatal("empty text should embed to zero vector")
+	}
+	if h.Model() != "hash-ngram-256" {
+		t.Fatalf("model: %s", h.Model())
+	}
+}
+
+func TestOpenAI(t *testing.T) {
+	var calls, failures atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer k" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if failures.Add(1) == 1 {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		var req embeddingRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "m" || req.Dimensions != 3 {
+			http.Error(w, "bad model", http.StatusBadRequest)
+			return
+		}
+		var resp embeddingResponse
+		resp.Data = make([]struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		}, len(req.Input))
+		// 逆序返回，验证按 index 还原顺序
+		for i, in := range req.Input {
+			j := len(req.Input) - 1 - i
+			resp.Data[j].Index = i
+			resp.Data[j].Embedding = []float64{float64(len([]rune(in))), 0, 1}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	e, err := New(Config{Provider: ProviderOpenAI, OpenAI: OpenAIOptions{BaseURL: srv.URL + "/v1/", Model: "m", APIKey: "k", Dimensions: 3, BatchSize: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vecs, err := e.EmbedStrings(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs) != 3 || vecs[0][0] != 1 || vecs[1][0] != 2 || vecs[2][0] != 3 {
+		t.Fatalf("vectors: %v", vecs)
+	}
+	if calls.Load() != 3 || e.Model() != "m@3" || e.Dimensions() != 3 {
+		t.Fatalf("calls %d model %s dim %d", calls.Load(), e.Model(), e.Dimensions())
+	}
+
+	bad, _ := NewOpenAI(OpenAIOptions{BaseURL: srv.URL + "/v1", Model: "other", APIKey: "k", Dimensions: 3})
+	if _, err := bad.EmbedStrings(context.Background(), []string{"a"}); err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+	if _, err := New(Config{Provider: "word2vec"}); err == nil {
+		t.Fatal("unknown provider should fail")
+	}
+}
+
+// counting：记录后端收到的文本。
+type counting struct {
+	Embedder
+	calls [][]string
+}
+
+func (c *counting) EmbedStrings(ctx context.Context, texts []string) ([][]float64, error) {
+	c.calls = append(c.calls, texts)
+	return c.Embedder.EmbedStrings(ctx, texts)
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "emb.cache")
+	c, err := OpenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := &counting{Embedder: NewHash(64)}
+	emb := c.Wrap(inner)
+	first, err := emb.EmbedStrings(ctx, []string{"番茄炒蛋", "红烧肉", "番茄炒蛋"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.calls) != 1 || len(inner.calls[0]) != 2 {
+		t.Fatalf("backend calls = %v, want one call with 2 unique texts", inner.calls)
+	}
+	again, err := emb.EmbedStrings(ctx, []string{"  番茄炒蛋\n", "红烧肉"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.calls) != 1 {
+		t.Fatalf("normalized repeat hit the backend: %v", inner.calls)
+	}
+	for i := range again[0] {
+		if again[0][i] != first[0][i] {
+			t.Fatalf("cached vector differs at %d: %v != %v", i, again[0][i], first[0][i])
+		}
+	}
+	if st := c.Stats(); st.Hits != 2 || st.Misses != 3 || st.Entries != 2 {
+		t.Fatalf("stats = %+v", st)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新打开后命中；维度不同的模型不复用旧向量。
+	c2, err := OpenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner2 := &counting{Embedder: NewHash(64)}
+	if _, err := c2.Wrap(inner2).EmbedStrings(ctx, []string{"红烧肉"}); err != nil || len(inner2.calls) != 0 {
+		t.Fatalf("reopened cache missed: %v, %v", inner2.calls, err)
+	}
+	other := &counting{Embedder: NewHash(32)}
+	vecs, err := c2.Wrap(other).EmbedStrings(ctx, []string{"红烧肉"})
+	if err != nil || len(other.calls) != 1 || len(vecs[0]) != 32 {
+		t.Fatalf("other model served stale vector: calls %v, dims %d, %v", other.calls, len(vecs[0]), err)
+	}
+	if st := c2.Stats(); st.Models["hash-ngram-64"] != 2 || st.Models["hash-ngram-32"] != 1 || st.TotalHits != 3 {
+		t.Fatalf("stats after reopen = %+v", st)
+	}
+
+	// 另一进程在此期间写入的条目在保存时保留，本进程清理的条目不恢复。
+	c3, _ := OpenCache(path)
+	if _, err := c3.Wrap(NewHash(64)).EmbedStrings(ctx, []string{"清蒸鱼"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c3.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if n := c2.Prune(PruneOptions{KeepModels: []string{"hash-ngram-32"}}); n != 2 {
+		t.Fatalf("pruned %d, want 2", n)
+	}
+	if err := c2.Save(); err != nil {
+		t.Fatal(err)
+	}
+	c4, _ := OpenCache(path)
+	if st := c4.Stats(); st.Entries != 2 || st.Models["hash-ngram-64"] != 1 || st.Bytes == 0 {
+		t.Fatalf("merged stats = %+v", st)
+	}
+	if n := c4.Prune(PruneOptions{MaxEntries: 1}); n != 1 || c4.Stats().Entries != 1 {
+		t.Fatalf("max entries pruned %d", n)
+	}
+	if n := c4.Prune(PruneOptions{UnusedFor: -time.Hour}); n != 0 {
+		t.Fatalf("non-positive UnusedFor pruned %d", n)
+	}
+}
+
+func TestCacheNil(t *testing.T) {
+	var c *Cache
+	h := NewHash(16)
+	if c.Wrap(h) != Embedder(h) {
+		t.Fatal("nil cache wrapped the embedder")
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenCache(filepath.Join(t.TempDir(), "missing", "x.cache")); err != nil {
+		t.Fatalf("missing file: %v", err)
+	}
+}
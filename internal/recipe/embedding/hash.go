@@ -0,0 +1,107 @@
+// 文件功能：离线确定性向量化；将文本的字、词 n-gram 哈希到固定维度（feature hashing），无需网络与凭据。
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"unicode"
+
+	"cook/internal/recipe/textnorm"
+)
+
+// DefaultHashDimensions：哈希向量默认维度。
+const DefaultHashDimensions = 512
+
+// Hash：哈希 n-gram 向量化后端。
+// 算法说明：文本经 textnorm.Fold 折叠后切分为汉字串与拉丁词串；汉字取单字与相邻二字（二字权重更高），
+// 拉丁词取整词与字符三元组；每个特征以 FNV-64a 哈希确定维度与正负号，累加后 L2 归一化。
+// 相同输入在任何机器上产生相同向量；语义相近程度仅体现为字面重合，适合本地开发与测试，不适合线上检索质量评估。
+type Hash struct {
+	dim int
+}
+
+// NewHash：构造哈希后端；dim ≤ 0 时使用 DefaultHashDimensions。
+func NewHash(dim int) *Hash {
+	if dim <= 0 {
+		dim = DefaultHashDimensions
+	}
+	return &Hash{dim: dim}
+}
+
+// EmbedStrings：批量向量化；空文本得到零向量。
+func (h *Hash) EmbedStrings(ctx context.Context, texts []string) ([][]float64, error) {
+	out := make([][]float64, len(texts))
+	for i, t := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		out[i] = h.embed(t)
+	}
+	return out, nil
+}
+
+// Dimensions：向量维度。
+func (h *Hash) Dimensions() int { return h.dim }
+
+// Model：模型标识，含维度；维度不同的向量不可混用。
+func (h *Hash) Model() string { return fmt.Sprintf("hash-ngram-%d", h.dim) }
+
+// 特征权重。
+const (
+	weightUnigram = 0.5
+	weightBigram  = 1.0
+	weightWord    = 1.0
+	weightTrigram = 0.5
+)
+
+func (h *Hash) embed(text string) []float64 {
+	v := make([]float64, h.dim)
+	var han, word []rune
+	flush := func() {
+		for i, r := range han {
+			h.add(v, string(r), weightUnigram)
+			if i+1 < len(han) {
+				h.add(v, string(han[i:i+2]), weightBigram)
+			}
+		}
+		if len(word) > 0 {
+			h.add(v, string(word), weightWord)
+			padded := append(append([]rune{'^'}, word...), '$')
+			for i := 0; i+3 <= len(padded); i++ {
+				h.add(v, string(padded[i:i+3]), weightTrigram)
+			}
+		}
+		han, word = han[:0], word[:0]
+	}
+	for _, r := range textnorm.Fold(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if len(word) > 0 {
+				flush()
+			}
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if len(han) > 0 {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	normalize(v)
+	return v
+}
+
+// add：将特征哈希到维度并按符号位累加。
+func (h *Hash) add(v []float64, feature string, w float64) {
+	f := fnv.New64a()
+	f.Write([]byte(feature))
+	x := f.Sum64()
+	if x>>63 == 1 {
+		w = -w
+	}
+	v[x%uint64(h.dim)] += w
+}
@@ -0,0 +1,216 @@
+// 文件功能：OpenAI 兼容接口向量化后端；按批调用 POST {base_url}/embeddings，限流与服务端错误时退避重试。
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenAI 兼容后端默认值。
+const (
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	DefaultOpenAIModel   = "text-embedding-3-small"
+	DefaultBatchSize     = 64
+	DefaultTimeout       = 30 * time.Second
+	DefaultMaxRetries    = 3
+)
+
+// OpenAIOptions：OpenAI 兼容后端参数。
+//   - BaseURL：接口基础地址（含 /v1）；为空时使用 DefaultOpenAIBaseURL；
+//   - Model：模型名称；为空时使用 DefaultOpenAIModel；
+//   - APIKey：访问密钥；本地无鉴权服务可为空；
+//   - Dimensions：请求的向量维度；0 表示使用模型默认维度（请求中不携带 dimensions）；
+//   - BatchSize：单次请求的文本数；≤0 时使用 DefaultBatchSize；
+//   - Timeout：单次请求超时；≤0 时使用 DefaultTimeout；
+//   - MaxRetries：429 与 5xx 的最大重试次数；<0 表示不重试，0 时使用 DefaultMaxRetries；
+//   - Client：HTTP 客户端；为空时按 Timeout 构造（测试可注入）。
+type OpenAIOptions struct {
+	BaseURL    string
+	Model      string
+	APIKey     string
+	Dimensions int
+	BatchSize  int
+	Timeout    time.Duration
+	MaxRetries int
+	Client     *http.Client
+}
+
+// OpenAI：OpenAI 兼容向量化后端。
+type OpenAI struct {
+	opts OpenAIOptions
+
+	mu  sync.Mutex
+	dim int // 未配置维度时取首次返回的向量长度
+}
+
+// NewOpenAI：构造 OpenAI 兼容后端并填充默认值。
+func NewOpenAI(opts OpenAIOptions) (*OpenAI, error) {
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultOpenAIBaseURL
+	}
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+	if !strings.HasPrefix(opts.BaseURL, "http://") && !strings.HasPrefix(opts.BaseURL, "https://") {
+		return nil, fmt.Errorf("embedding: bad base url %q", opts.BaseURL)
+	}
+	if opts.Model == "" {
+		opts.Model = DefaultOpenAIModel
+	}
+	if opts.Dimensions < 0 {
+		return nil, fmt.Errorf("embedding: bad dimensions %d", opts.Dimensions)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = DefaultMaxRetries
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: opts.Timeout}
+	}
+	return &OpenAI{opts: opts, dim: opts.Dimensions}, nil
+}
+
+// Dimensions：向量维度；未配置且尚未调用时为 0。
+func (o *OpenAI) Dimensions() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.dim
+}
+
+// Model：模型标识；显式配置维度时附带维度（同一模型不同维度的向量不可混用）。
+func (o *OpenAI) Model() string {
+	if o.opts.Dimensions > 0 {
+		return fmt.Sprintf("%s@%d", o.opts.Model, o.opts.Dimensions)
+	}
+	return o.opts.Model
+}
+
+// EmbedStrings：按 BatchSize 分批向量化。
+// 返回值说明：
+//   - [][]float64：与 texts 一一对应的向量；
+//   - error：请求失败、重试耗尽、返回条数或维度不一致时返回错误。
+func (o *OpenAI) EmbedStrings(ctx context.Context, texts []string) ([][]float64, error) {
+	out := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += o.opts.BatchSize {
+		end := min(start+o.opts.BatchSize, len(texts))
+		vecs, err := o.embedBatch(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, vecs...)
+	}
+	return out, nil
+}
+
+// embeddingRequest / embeddingResponse：/embeddings 接口的请求与响应。
+type embeddingRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// embedBatch：单批请求；429 与 5xx 按指数退避重试。
+func (o *OpenAI) embedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	body, err := json.Marshal(embeddingRequest{
+		Model:          o.opts.Model,
+		Input:          texts,
+		Dimensions:     o.opts.Dimensions,
+		EncodingFormat: "float",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("embedding: %w", err)
+	}
+	retries := max(o.opts.MaxRetries, 0)
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		vecs, retry, err := o.post(ctx, body)
+		if err == nil {
+			return o.check(texts, vecs)
+		}
+		if !retry || attempt >= retries {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post：发送请求；返回是否值得重试。
+func (o *OpenAI) post(ctx context.Context, body []byte) ([][]float64, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.opts.BaseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, false, fmt.Errorf("embedding: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.opts.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.opts.APIKey)
+	}
+	resp, err := o.opts.Client.Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, fmt.Errorf("embedding: %w", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, true, fmt.Errorf("embedding: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(b))
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return nil, retry, fmt.Errorf("embedding: %s: status %d: %s", o.opts.Model, resp.StatusCode, msg)
+	}
+	var er embeddingResponse
+	if err := json.Unmarshal(b, &er); err != nil {
+		return nil, false, fmt.Errorf("embedding: decode response: %w", err)
+	}
+	sort.Slice(er.Data, func(i, j int) bool { return er.Data[i].Index < er.Data[j].Index })
+	vecs := make([][]float64, len(er.Data))
+	for i, d := range er.Data {
+		vecs[i] = d.Embedding
+	}
+	return vecs, false, nil
+}
+
+// check：校验返回条数与维度；未配置维度时记录首次返回的维度。
+func (o *OpenAI) check(texts []string, vecs [][]float64) ([][]float64, error) {
+	if len(vecs) != len(texts) {
+		return nil, fmt.Errorf("embedding: %s returned %d vectors for %d inputs", o.opts.Model, len(vecs), len(texts))
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, v := range vecs {
+		if o.dim == 0 {
+			o.dim = len(v)
+		}
+		if len(v) != o.dim {
+			return nil, fmt.Errorf("embedding: %s returned %d dimensions, want %d", o.opts.Model, len(v), o.dim)
+		}
+	}
+	return vecs, nil
+}
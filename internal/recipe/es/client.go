@@ -0,0 +1,149 @@
+// 文件功能：Elasticsearch HTTP 客户端；负责鉴权、超时与可重试错误（网络错误、429、502/503/504）的退避重试。
+package es
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 客户端默认值。
+const (
+	DefaultTimeout    = 30 * time.Second
+	DefaultMaxRetries = 3
+)
+
+// Options：客户端参数。
+//   - Address：服务地址（如 http://localhost:9200）；
+//   - Username/Password：Basic 鉴权；用户名为空时不鉴权；
+//   - Timeout：单次请求超时；≤0 时使用 DefaultTimeout；
+//   - MaxRetries：可重试错误的最大重试次数；<0 表示不重试，0 时使用 DefaultMaxRetries；
+//   - Backoff：首次重试等待时间，此后逐次翻倍；≤0 时为 500ms；
+//   - Client：HTTP 客户端；为空时按 Timeout 构造（测试可注入）。
+type Options struct {
+	Address    string
+	Username   string
+	Password   string
+	Timeout    time.Duration
+	MaxRetries int
+	Backoff    time.Duration
+	Client     *http.Client
+}
+
+// Client：Elasticsearch HTTP 客户端。
+type Client struct {
+	opts Options
+}
+
+// NewClient：构造客户端并填充默认值。
+func NewClient(opts Options) (*Client, error) {
+	opts.Address = strings.TrimRight(opts.Address, "/")
+	if !strings.HasPrefix(opts.Address, "http://") && !strings.HasPrefix(opts.Address, "https://") {
+		return nil, fmt.Errorf("es: bad address %q", opts.Address)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = DefaultMaxRetries
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 500 * time.Millisecond
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: opts.Timeout}
+	}
+	return &Client{opts: opts}, nil
+}
+
+// Error：Elasticsearch 返回的非成功状态。
+type Error struct {
+	Method string
+	Path   string
+	Status int
+	Body   string
+}
+
+// Error：含请求方法、路径、状态码与截断的响应体。
+func (e *Error) Error() string {
+	return fmt.Sprintf("es: %s %s: status %d: %s", e.Method, e.Path, e.Status, e.Body)
+}
+
+// do：发送请求并读取响应体。
+// 功能说明：网络错误与 429/502/503/504 按指数退避重试；其余非 2xx 状态返回 *Error（HEAD 请求的 404 由调用方按状态码处理）。
+// 参数说明：
+//   - method/path：请求方法与路径（以 / 开头）；
+//   - body：请求体；为 nil 时不携带；
+//   - contentType：请求体类型；bulk 为 application/x-ndjson。
+//
+// 返回值说明：
+//   - int：最终状态码；
+//   - []byte：响应体；
+//   - error：重试耗尽或不可重试的失败。
+func (c *Client) do(ctx context.Context, method, path string, body []byte, contentType string) (int, []byte, error) {
+	retries := max(c.opts.MaxRetries, 0)
+	backoff := c.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		status, b, err := c.once(ctx, method, path, body, contentType)
+		retry := err != nil && ctx.Err() == nil ||
+			status == http.StatusTooManyRequests || status == http.StatusBadGateway ||
+			status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+		if !retry || attempt >= retries {
+			if err == nil && status >= 300 && !(method == http.MethodHead && status == http.StatusNotFound) {
+				err = &Error{Method: method, Path: path, Status: status, Body: truncate(string(b), 300)}
+			}
+			return status, b, err
+		}
+		select {
+		case <-ctx.Done():
+			return 0, nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// once：发送单次请求。
+func (c *Client) once(ctx context.Context, method, path string, body []byte, contentType string) (int, []byte, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.opts.Address+path, r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("es: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.opts.Username != "" {
+		req.SetBasicAuth(c.opts.Username, c.opts.Password)
+	}
+	resp, err := c.opts.Client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("es: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("es: %s %s: read response: %w", method, path, err)
+	}
+	return resp.StatusCode, b, nil
+}
+
+// truncate：截断过长的文本（按字节，保证不截断 UTF-8 字符）。
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,5 @@
+// 文件功能：Elasticsearch 8 访问包说明。
+// 包功能：es 包，基于 net/http 直接调用 Elasticsearch 8 REST 接口，实现 vector.Store：
+// 建立含 dense_vector 与关键字元数据字段的索引映射，分批 bulk 写入与删除，并对限流与服务端错误重试；
+// 全量索引写入带时间戳的版本索引，校验后原子切换别名，保留旧版本供回滚。
+package es
@@ -0,0 +1,614 @@
+package es
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cook/internal/recipe/vector"
+)
+
+// fakeES：内存中的最小 Elasticsearch；支持建索引、映射查询、bulk、kNN 检索、delete_by_query、refresh，
+// 以及别名、_count、_cat/indices 与删除索引。
+type fakeES struct {
+	mu       sync.Mutex
+	mappings map[string]map[string]any
+	aliases  map[string]string                    // alias -> index
+	docs     map[string]map[string]map[string]any // index -> id -> source
+	reject   map[string]int                       // id -> 剩余单条 429 次数
+	fail     map[string]bool                      // id -> 单条 400
+	busy     int                                  // 剩余整批 503 次数
+	bulks    int
+	refresh  int
+}
+
+func newFakeES() *fakeES {
+	return &fakeES{
+		mappings: map[string]map[string]any{},
+		aliases:  map[string]string{},
+		docs:     map[string]map[string]map[string]any{},
+		reject:   map[string]int{},
+		fail:     map[string]bool{},
+	}
+}
+
+func (f *fakeES) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/_aliases":
+		f.handleAliases(w, r)
+	case len(parts) == 3 && parts[0] == "_cat" && parts[1] == "indices":
+		prefix := strings.TrimSuffix(parts[2], "*")
+		rows := []map[string]string{}
+		for index := range f.mappings {
+			if strings.HasPrefix(index, prefix) {
+				rows = append(rows, map[string]string{"index": index, "docs.count": fmt.Sprint(len(f.docs[index]))})
+			}
+		}
+		json.NewEncoder(w).Encode(rows)
+	case len(parts) == 2 && parts[0] == "_alias":
+		index, ok := f.aliases[parts[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":"alias missing","status":404}`)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{index: map[string]any{"aliases": map[string]any{parts[1]: map[string]any{}}}})
+	case len(parts) == 2 && parts[1] == "_count":
+		json.NewEncoder(w).Encode(map[string]any{"count": len(f.docs[parts[0]])})
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		delete(f.mappings, parts[0])
+		delete(f.docs, parts[0])
+		io.WriteString(w, `{"acknowledged":true}`)
+	case r.URL.Path == "/_bulk":
+		if f.busy > 0 {
+			f.busy--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		f.bulks++
+		f.handleBulk(w, r)
+	case len(parts) == 1 && r.Method == http.MethodHead:
+		if _, ok := f.mappings[parts[0]]; !ok && f.aliases[parts[0]] == "" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		if _, ok := f.mappings[parts[0]]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"error":{"type":"resource_already_exists_exception"}}`)
+			return
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		f.mappings[parts[0]] = body["mappings"].(map[string]any)
+		f.docs[parts[0]] = map[string]map[string]any{}
+		io.WriteString(w, `{"acknowledged":true}`)
+	case len(parts) == 2 && parts[1] == "_mapping":
+		m, ok := f.mappings[parts[0]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPut {
+			var body map[string]map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			for k, v := range body["properties"] {
+				m["properties"].(map[string]any)[k] = v
+			}
+			io.WriteString(w, `{"acknowledged":true}`)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{parts[0]: map[string]any{"mappings": m}})
+	case len(parts) == 2 && parts[1] == "_delete_by_query":
+		var q struct {
+			Query struct {
+				Bool struct {
+					MustNot []struct {
+						IDs struct {
+							Values []string `json:"values"`
+						} `json:"ids"`
+					} `json:"must_not"`
+				} `json:"bool"`
+			} `json:"query"`
+		}
+		json.NewDecoder(r.Body).Decode(&q)
+		keep := map[string]bool{}
+		for _, id := range q.Query.Bool.MustNot[0].IDs.Values {
+			keep[id] = true
+		}
+		n := 0
+		for id := range f.docs[parts[0]] {
+			if !keep[id] {
+				delete(f.docs[parts[0]], id)
+				n++
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"deleted": n})
+	case len(parts) == 2 && parts[1] == "_search":
+		f.handleSearch(w, r, parts[0])
+	case len(parts) == 2 && parts[1] == "_refresh":
+		f.refresh++
+		io.WriteString(w, `{}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// handleAliases：按顺序执行 add/remove/remove_index；任一动作失败时整体不生效。
+func (f *fakeES) handleAliases(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Actions []map[string]struct {
+			Index string `json:"index"`
+			Alias string `json:"alias"`
+		} `json:"actions"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	aliases := map[string]string{}
+	for k, v := range f.aliases {
+		aliases[k] = v
+	}
+	var drop []string
+	for _, a := range req.Actions {
+		for op, p := range a {
+			switch op {
+			case "add":
+				if _, ok := f.mappings[p.Index]; !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if _, ok := f.mappings[p.Alias]; ok && !slices.Contains(drop, p.Alias) {
+					w.WriteHeader(http.StatusBadRequest)
+					io.WriteString(w, `{"error":{"type":"invalid_alias_name_exception"}}`)
+					return
+				}
+				aliases[p.Alias] = p.Index
+			case "remove":
+				if aliases[p.Alias] != p.Index {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				delete(aliases, p.Alias)
+			case "remove_index":
+				drop = append(drop, p.Index)
+			}
+		}
+	}
+	for _, index := range drop {
+		delete(f.mappings, index)
+		delete(f.docs, index)
+	}
+	f.aliases = aliases
+	io.WriteString(w, `{"acknowledged":true}`)
+}
+
+func (f *fakeES) handleBulk(w http.ResponseWriter, r *http.Request) {
+	sc := bufio.NewScanner(r.Body)
+	sc.Buffer(make([]byte, 1<<20), 16<<20)
+	var items []map[string]any
+	errs := false
+	for sc.Scan() {
+		var meta map[string]struct {
+			Index string `json:"_index"`
+			ID    string `json:"_id"`
+		}
+		json.Unmarshal(sc.Bytes(), &meta)
+		for op, m := range meta {
+			status := http.StatusOK
+			var src map[string]any
+			if op == "index" {
+				sc.Scan()
+				json.Unmarshal(sc.Bytes(), &src)
+			}
+			item := map[string]any{"_id": m.ID}
+			switch {
+			case f.reject[m.ID] > 0:
+				f.reject[m.ID]--
+				status = http.StatusTooManyRequests
+				item["error"] = map[string]any{"type": "es_rejected_execution_exception", "reason": "queue full"}
+			case f.fail[m.ID]:
+				status = http.StatusBadRequest
+				item["error"] = map[string]any{"type": "document_parsing_exception", "reason": "bad field"}
+			case op == "index":
+				f.docs[m.Index][m.ID] = src
+			case op == "delete":
+				if _, ok := f.docs[m.Index][m.ID]; !ok {
+					status = http.StatusNotFound
+				}
+				delete(f.docs[m.Index], m.ID)
+			}
+			if status >= 300 {
+				errs = true
+			}
+			item["status"] = status
+			items = append(items, map[string]any{op: item})
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]any{"errors": errs, "items": items})
+}
+
+// handleSearch：精确计算 kNN（点积），按 terms 过滤。
+func (f *fakeES) handleSearch(w http.ResponseWriter, r *http.Request, index string) {
+	var req struct {
+		KNN struct {
+			Vector []float64 `json:"query_vector"`
+			K      int       `json:"k"`
+			Filter struct {
+				Bool struct {
+					Filter []struct {
+						Terms map[string][]string `json:"terms"`
+					} `json:"filter"`
+				} `json:"bool"`
+			} `json:"filter"`
+		} `json:"knn"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	type hit struct {
+		ID     string         `json:"_id"`
+		Score  float64        `json:"_score"`
+		Source map[string]any `json:"_source"`
+	}
+	var hits []hit
+	for id, src := range f.docs[index] {
+		ok := true
+		for _, c := range req.KNN.Filter.Bool.Filter {
+			for field, vs := range c.Terms {
+				ok = ok && slices.Contains(vs, fmt.Sprint(src[field]))
+			}
+		}
+		if !ok {
+			continue
+		}
+		var dot float64
+		for i, x := range src[FieldVector].([]any) {
+			dot += x.(float64) * req.KNN.Vector[i]
+		}
+		out := map[string]any{}
+		for k, v := range src {
+			if k != FieldVector {
+				out[k] = v
+			}
+		}
+		hits = append(hits, hit{ID: id, Score: (1 + dot) / 2, Source: out})
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > req.KNN.K {
+		hits = hits[:req.KNN.K]
+	}
+	json.NewEncoder(w).Encode(map[string]any{"hits": map[string]any{"hits": hits}})
+}
+
+func newTestStore(t *testing.T, f *fakeES, bulkSize int) *Store {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	c, err := NewClient(Options{Address: srv.URL, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewStore(c, "recipes", bulkSize)
+}
+
+func testDocs(ids ...string) []*vector.Document {
+	var out []*vector.Document
+	for _, id := range ids {
+		d := &vector.Document{ID: id, Content: "番茄炒蛋 " + id, MetaData: map[string]any{vector.MetaName: "番茄炒蛋", vector.MetaIndex: 0}}
+		out = append(out, d.WithScore(1).WithDenseVector([]float64{1, 0, 0}))
+	}
+	return out
+}
+
+func TestEnsure(t *testing.T) {
+	f := newFakeES()
+	s := newTestStore(t, f, 0)
+	ctx := context.Background()
+	if err := s.Ensure(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	props := f.mappings["recipes"]["properties"].(map[string]any)
+	vec := props[FieldVector].(map[string]any)
+	if vec["type"] != "dense_vector" || vec["dims"] != float64(3) || vec["similarity"] != "cosine" {
+		t.Fatalf("vector mapping = %v", vec)
+	}
+	if props[vector.MetaCategory].(map[string]any)["type"] != "keyword" {
+		t.Fatalf("category mapping = %v", props[vector.MetaCategory])
+	}
+	delete(props, vector.MetaDiets)
+	if err := s.Ensure(ctx, 3); err != nil {
+		t.Fatalf("ensure existing: %v", err)
+	}
+	if _, ok := props[vector.MetaDiets]; !ok {
+		t.Fatal("ensure did not add a new mapping field to an existing index")
+	}
+	if err := s.Ensure(ctx, 8); err == nil || !strings.Contains(err.Error(), "3-dim") {
+		t.Fatalf("ensure with other dims: %v", err)
+	}
+}
+
+func TestUpsertDeletePrune(t *testing.T) {
+	f := newFakeES()
+	s := newTestStore(t, f, 2)
+	ctx := context.Background()
+	if err := s.Ensure(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Upsert(ctx, testDocs("a", "b", "c", "d", "e")); err != nil {
+		t.Fatal(err)
+	}
+	if f.bulks != 3 || len(f.docs["recipes"]) != 5 {
+		t.Fatalf("bulks = %d, docs = %d", f.bulks, len(f.docs["recipes"]))
+	}
+	src := f.docs["recipes"]["a"]
+	if src[FieldContent] != "番茄炒蛋 a" || src[vector.MetaName] != "番茄炒蛋" || len(src[FieldVector].([]any)) != 3 {
+		t.Fatalf("source = %v", src)
+	}
+	if _, ok := src["_score"]; ok {
+		t.Fatalf("internal meta leaked into source: %v", src)
+	}
+	if err := s.Delete(ctx, []string{"a", "missing"}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	n, err := s.Prune(ctx, []string{"b", "c"})
+	if err != nil || n != 2 {
+		t.Fatalf("prune = %d, %v", n, err)
+	}
+	if len(f.docs["recipes"]) != 2 {
+		t.Fatalf("docs after prune = %v", f.docs["recipes"])
+	}
+	if err := s.Upsert(ctx, []*vector.Document{{ID: "x", Content: "x"}}); err == nil {
+		t.Fatal("upsert without vector should fail")
+	}
+}
+
+func TestBulkRetryAndFailures(t *testing.T) {
+	f := newFakeES()
+	f.busy = 1
+	f.reject["a"] = 2
+	f.fail["b"] = true
+	s := newTestStore(t, f, 0)
+	ctx := context.Background()
+	if err := s.Ensure(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	err := s.Upsert(ctx, testDocs("a", "b", "c"))
+	var be *vector.BulkError
+	if !errors.As(err, &be) {
+		t.Fatalf("err = %v, want *vector.BulkError", err)
+	}
+	if len(be.Failures) != 1 || be.Failures[0].ID != "b" || be.Failures[0].Status != http.StatusBadRequest {
+		t.Fatalf("failures = %+v", be.Failures)
+	}
+	if _, ok := f.docs["recipes"]["a"]; !ok {
+		t.Fatal("rejected document was not retried")
+	}
+	if f.bulks != 3 {
+		t.Fatalf("bulks = %d, want 3", f.bulks)
+	}
+
+	f.reject["c"] = 10
+	err = s.Upsert(ctx, testDocs("c"))
+	if !errors.As(err, &be) || be.Failures[0].Status != http.StatusTooManyRequests {
+		t.Fatalf("exhausted retries: %v", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	f := newFakeES()
+	s := newTestStore(t, f, 0)
+	ctx := context.Background()
+	if err := s.Ensure(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	docs := testDocs("a", "b")
+	docs[1].WithDenseVector([]float64{0, 1, 0})
+	docs[1].MetaData[vector.MetaCategory] = "soup"
+	if err := s.Upsert(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Search(ctx, []float64{0, 1, 0}, vector.SearchOptions{TopK: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "b" || got[0].Score() != 1 || got[0].Content != "番茄炒蛋 b" {
+		t.Fatalf("search = %+v", got)
+	}
+	if _, ok := got[0].MetaData[FieldContent]; ok || got[0].MetaData[vector.MetaName] != "番茄炒蛋" {
+		t.Fatalf("meta = %v", got[0].MetaData)
+	}
+	got, _ = s.Search(ctx, []float64{1, 0, 0}, vector.SearchOptions{TopK: 2, Filter: vector.Filter{Terms: map[string][]string{vector.MetaCategory: {"soup"}}}})
+	if len(got) != 1 || got[0].ID != "b" {
+		t.Fatalf("filtered = %+v", got)
+	}
+}
+
+func TestFilterQuery(t *testing.T) {
+	lo, hi := 1.0, 30.0
+	f := vector.Filter{
+		Terms:       map[string][]string{vector.MetaCategory: {"meat_dish"}},
+		All:         map[string][]string{vector.MetaDiets: {"低脂"}},
+		Not:         map[string][]string{vector.MetaAllergens: {"花生"}},
+		Ranges:      map[string]vector.Range{vector.MetaDifficulty: {Min: &lo}, vector.MetaMinutes: {Max: &hi}},
+		Contains:    map[string][]string{vector.MetaIngredients: {"鸡"}},
+		NotContains: map[string][]string{vector.MetaIngredients: {"香*菜"}},
+	}
+	b, _ := json.Marshal(filterQuery(f))
+	want := `{"bool":{"filter":[{"terms":{"category":["meat_dish"]}},{"term":{"diets":"低脂"}},` +
+		`{"range":{"difficulty":{"gte":1}}},{"range":{"total_minutes":{"lte":30}}},` +
+		`{"wildcard":{"ingredients":{"value":"*鸡*"}}}],` +
+		`"must_not":[{"terms":{"allergens":["花生"]}},{"wildcard":{"ingredients":{"value":"*香\\*菜*"}}}]}}`
+	if string(b) != want {
+		t.Fatalf("filter query =\n%s\nwant\n%s", b, want)
+	}
+	if filterQuery(vector.Filter{Ranges: map[string]vector.Range{vector.MetaKcal: {}}}) != nil {
+		t.Fatal("empty range produced a query")
+	}
+}
+
+func TestClientError(t *testing.T) {
+	f := newFakeES()
+	s := newTestStore(t, f, 0)
+	_, _, err := s.c.do(context.Background(), http.MethodGet, "/nope/_mapping", nil, "")
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusNotFound {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := NewClient(Options{Address: "localhost:9200"}); err == nil {
+		t.Fatal("address without scheme should fail")
+	}
+}
+
+func TestEncodeBulk(t *testing.T) {
+	b, err := encodeBulk("r", []action{{op: "delete", id: "a"}, {op: "index", id: "b", source: map[string]any{"content": "<汤>"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	if len(lines) != 3 || !bytes.Contains(lines[2], []byte("<汤>")) {
+		t.Fatalf("bulk body = %s", b)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	f := newFakeES()
+	s := newTestStore(t, f, 0)
+	ctx := context.Background()
+	v := NewVersions(s.c, "recipes", 1)
+	if cur, err := v.Current(ctx); err != nil || cur != "" {
+		t.Fatalf("current before any version = %q, %v", cur, err)
+	}
+	base := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	var names []string
+	for i, ids := range [][]string{{"a"}, {"a", "b"}, {"a", "b", "c"}} {
+		name := v.Name(base.Add(time.Duration(i) * time.Hour))
+		names = append(names, name)
+		st := NewStore(s.c, name, 0)
+		if err := st.Ensure(ctx, 3); err != nil {
+			t.Fatal(err)
+		}
+		docs := testDocs(ids...)
+		docs[0].WithDenseVector([]float64{0, 1, 0})
+		if err := st.Upsert(ctx, docs); err != nil {
+			t.Fatal(err)
+		}
+		if err := v.Check(ctx, name, len(ids)+1, "", nil); err == nil {
+			t.Fatal("check accepted a wrong document count")
+		}
+		if err := v.Check(ctx, name, len(ids), "a", []float64{0, 1, 0}); err != nil {
+			t.Fatalf("check %s: %v", name, err)
+		}
+		if err := v.Check(ctx, name, len(ids), "missing", []float64{0, 1, 0}); err == nil {
+			t.Fatal("smoke query accepted a missing probe")
+		}
+		if err := v.Swap(ctx, name, false); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := v.Trim(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if names[0] != "recipes-20261001-080000.000" {
+		t.Fatalf("version name = %s", names[0])
+	}
+	if f.aliases["recipes"] != names[2] {
+		t.Fatalf("alias = %v", f.aliases)
+	}
+	list, err := v.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Index != names[2] || !list[0].Current || list[1].Index != names[1] || list[1].Docs != 2 {
+		t.Fatalf("list = %+v", list)
+	}
+	if _, ok := f.mappings[names[0]]; ok {
+		t.Fatal("trim kept more than Keep old versions")
+	}
+
+	// 回滚到上一版本。
+	prev, err := v.Previous(ctx)
+	if err != nil || prev.Index != names[1] {
+		t.Fatalf("previous = %+v, %v", prev, err)
+	}
+	if err := v.Swap(ctx, prev.Index, false); err != nil || f.aliases["recipes"] != names[1] {
+		t.Fatalf("rollback: %v, alias = %v", err, f.aliases)
+	}
+	if _, err := v.Previous(ctx); err == nil {
+		t.Fatal("previous of the oldest version")
+	}
+	if err := v.Drop(ctx, names[1]); err == nil {
+		t.Fatal("dropped the current version")
+	}
+	if err := v.Swap(ctx, "other-20261001-080000", false); err == nil {
+		t.Fatal("swapped to a foreign index")
+	}
+}
+
+func TestVersionsNewStore(t *testing.T) {
+	f := newFakeES()
+	s := newTestStore(t, f, 0)
+	ctx := context.Background()
+	v := NewVersions(s.c, "recipes", 0)
+	at := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	if a, b := v.Name(at), v.Name(at.Add(time.Millisecond)); a == b {
+		t.Fatalf("runs in the same second share version %s", a)
+	}
+	name := v.Name(at)
+	st := v.NewStore(name, 0)
+	if err := st.Ensure(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Ensure(ctx, 3); err != nil {
+		t.Fatalf("ensure again on the index this store created: %v", err)
+	}
+	if err := v.NewStore(name, 0).Ensure(ctx, 3); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("second store reused %s: %v", name, err)
+	}
+
+	// 早期精确到秒的版本名仍可列出与切换。
+	legacy := "recipes-20260901-080000"
+	if err := NewStore(s.c, legacy, 0).Ensure(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Swap(ctx, legacy, false); err != nil {
+		t.Fatalf("swap to a legacy version: %v", err)
+	}
+	list, err := v.List(ctx)
+	if err != nil || len(list) != 2 || list[0].Index != name || list[1].Index != legacy || !list[1].Current {
+		t.Fatalf("list = %+v, %v", list, err)
+	}
+}
+
+func TestVersionsReplaceConcrete(t *testing.T) {
+	f := newFakeES()
+	s := newTestStore(t, f, 0)
+	ctx := context.Background()
+	if err := s.Ensure(ctx, 3); err != nil { // 启用版本化之前的具体索引 recipes
+		t.Fatal(err)
+	}
+	v := NewVersions(s.c, "recipes", 0)
+	name := v.Name(time.Now())
+	if err := NewStore(s.c, name, 0).Ensure(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Swap(ctx, name, false); err == nil || !strings.Contains(err.Error(), "concrete index") {
+		t.Fatalf("swap over a concrete index: %v", err)
+	}
+	if err := v.Swap(ctx, name, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.mappings["recipes"]; ok || f.aliases["recipes"] != name {
+		t.Fatalf("concrete index not replaced: alias = %v", f.aliases)
+	}
+}
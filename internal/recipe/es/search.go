@@ -0,0 +1,152 @@
+// 文件功能：Elasticsearch 向量检索；实现 vector.Searcher：kNN 检索 content_vector，元数据过滤下推为 kNN 的 filter。
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"cook/internal/recipe/vector"
+)
+
+var _ vector.Searcher = (*Store)(nil)
+
+// Search：kNN 检索。
+// 功能说明：候选数取 TopK 的 10 倍（至少 100）；过滤条件在近邻搜索过程中生效（而非取回后再过滤），
+// 因此过滤后仍能返回足量结果。返回的文档不含向量。
+// 返回值说明：
+//   - []*vector.Document：按相似度降序的文档，Score 为 ES 得分（余弦相似度映射到 0～1）；
+//   - error：请求或解码失败时返回错误。
+func (s *Store) Search(ctx context.Context, vec []float64, opts vector.SearchOptions) ([]*vector.Document, error) {
+	if opts.TopK <= 0 || len(vec) == 0 {
+		return nil, nil
+	}
+	knn := map[string]any{
+		"field":          FieldVector,
+		"query_vector":   vec,
+		"k":              opts.TopK,
+		"num_candidates": max(opts.TopK*10, 100),
+	}
+	if f := filterQuery(opts.Filter); f != nil {
+		knn["filter"] = f
+	}
+	body, _ := json.Marshal(map[string]any{
+		"knn":     knn,
+		"size":    opts.TopK,
+		"_source": map[string]any{"excludes": []string{FieldVector}},
+	})
+	_, b, err := s.c.do(ctx, http.MethodPost, "/"+url.PathEscape(s.index)+"/_search", body, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	return decodeHits(b)
+}
+
+// filterQuery：将元数据过滤转换为 bool 查询；没有条件时返回 nil。
+// 功能说明：Terms 为 terms；All 为逐值 term；Ranges 为 range；Contains 为逐个 *子串* 的 wildcard（keyword 字段按元素匹配），
+// 以上放入 filter；Not 与 NotContains 放入 must_not。语义与 vector.Filter.Match 一致。
+func filterQuery(f vector.Filter) map[string]any {
+	if f.Empty() {
+		return nil
+	}
+	var filter, mustNot []any
+	for _, field := range sortedKeys(f.Terms) {
+		if vs := f.Terms[field]; len(vs) > 0 {
+			filter = append(filter, map[string]any{"terms": map[string]any{field: vs}})
+		}
+	}
+	for _, field := range sortedKeys(f.All) {
+		for _, v := range f.All[field] {
+			filter = append(filter, map[string]any{"term": map[string]any{field: v}})
+		}
+	}
+	for _, field := range sortedKeys(f.Ranges) {
+		r := f.Ranges[field]
+		if r.Empty() {
+			continue
+		}
+		bounds := map[string]any{}
+		if r.Min != nil {
+			bounds["gte"] = *r.Min
+		}
+		if r.Max != nil {
+			bounds["lte"] = *r.Max
+		}
+		filter = append(filter, map[string]any{"range": map[string]any{field: bounds}})
+	}
+	for _, field := range sortedKeys(f.Contains) {
+		for _, sub := range f.Contains[field] {
+			filter = append(filter, wildcard(field, sub))
+		}
+	}
+	for _, field := range sortedKeys(f.Not) {
+		if vs := f.Not[field]; len(vs) > 0 {
+			mustNot = append(mustNot, map[string]any{"terms": map[string]any{field: vs}})
+		}
+	}
+	for _, field := range sortedKeys(f.NotContains) {
+		for _, sub := range f.NotContains[field] {
+			mustNot = append(mustNot, wildcard(field, sub))
+		}
+	}
+	b := map[string]any{}
+	if len(filter) > 0 {
+		b["filter"] = filter
+	}
+	if len(mustNot) > 0 {
+		b["must_not"] = mustNot
+	}
+	return map[string]any{"bool": b}
+}
+
+// wildcard：子串匹配查询；子串中的通配符按字面匹配。
+func wildcard(field, sub string) map[string]any {
+	sub = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`).Replace(sub)
+	return map[string]any{"wildcard": map[string]any{field: map[string]any{"value": "*" + sub + "*"}}}
+}
+
+// sortedKeys：按字典序返回映射的键，使生成的查询稳定。
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// searchResponse：_search 接口响应。
+type searchResponse struct {
+	Hits struct {
+		Hits []struct {
+			ID     string         `json:"_id"`
+			Score  float64        `json:"_score"`
+			Source map[string]any `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// decodeHits：将命中转换为文档；content 字段还原为 Content，其余字段作为元数据。
+func decodeHits(b []byte) ([]*vector.Document, error) {
+	var resp searchResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, fmt.Errorf("es: decode search response: %w", err)
+	}
+	out := make([]*vector.Document, 0, len(resp.Hits.Hits))
+	for _, h := range resp.Hits.Hits {
+		meta := h.Source
+		if meta == nil {
+			meta = map[string]any{}
+		}
+		content, _ := meta[FieldContent].(string)
+		delete(meta, FieldContent)
+		delete(meta, FieldVector)
+		d := &vector.Document{ID: h.ID, Content: content, MetaData: meta}
+		out = append(out, d.WithScore(h.Score))
+	}
+	return out, nil
+}
@@ -0,0 +1,336 @@
+// 文件功能：Elasticsearch 向量存储；实现 vector.Store：按向量维度建立索引映射，分批 bulk 写入与删除，清理过期文档。
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"cook/internal/recipe/vector"
+)
+
+// 索引字段；元数据字段名与 vector.Meta* 一致，平铺在文档顶层。
+const (
+	FieldContent = "content"        // 正文（cjk 分词）
+	FieldVector  = "content_vector" // 稠密向量
+)
+
+// DefaultBulkSize：单次 bulk 请求的文档数。
+const DefaultBulkSize = 200
+
+// Store：Elasticsearch 向量存储。
+type Store struct {
+	c        *Client
+	index    string
+	bulkSize int
+	fresh    bool // 只写入本存储新建的索引（版本索引）；索引已存在时 Ensure 返回错误
+	created  bool // 索引由本存储的 Ensure 创建
+}
+
+var _ vector.Store = (*Store)(nil)
+
+// NewStore：构造写入 index 的存储；bulkSize ≤ 0 时使用 DefaultBulkSize。
+func NewStore(c *Client, index string, bulkSize int) *Store {
+	if bulkSize <= 0 {
+		bulkSize = DefaultBulkSize
+	}
+	return &Store{c: c, index: index, bulkSize: bulkSize}
+}
+
+// Index：索引名称。
+func (s *Store) Index() string { return s.index }
+
+// Mapping：索引映射。
+// 功能说明：正文与标题使用内置 cjk 分析器（中文按二元组切分）；向量字段为 dense_vector（余弦相似度，可 kNN 检索）；
+// 分类、名称、路径、章节、过敏原、饮食标签、原料名等为 keyword，便于过滤与聚合；难度、总时长与每份营养数值为数值字段，
+// 供范围过滤；营养成分原文仅保存在 _source 中；未声明的字段不建索引。
+func Mapping(dims int) map[string]any {
+	keyword := map[string]any{"type": "keyword"}
+	integer := map[string]any{"type": "integer"}
+	float := map[string]any{"type": "float"}
+	return map[string]any{
+		"mappings": map[string]any{
+			"dynamic": "false",
+			"properties": map[string]any{
+				FieldContent: map[string]any{"type": "text", "analyzer": "cjk"},
+				FieldVector: map[string]any{
+					"type":       "dense_vector",
+					"dims":       dims,
+					"index":      true,
+					"similarity": "cosine",
+				},
+				vector.MetaDocID:    keyword,
+				vector.MetaIndex:    integer,
+				vector.MetaCategory: keyword,
+				vector.MetaName:     keyword,
+				vector.MetaPath:     keyword,
+				vector.MetaHeader: map[string]any{
+					"type": "text", "analyzer": "cjk",
+					"fields": map[string]any{"raw": keyword},
+				},
+				vector.MetaSection:   keyword,
+				vector.MetaSource:    keyword,
+				vector.MetaServings:  integer,
+				vector.MetaAllergens: keyword,
+				vector.MetaNutrition: map[string]any{"type": "object", "enabled": false},
+
+				vector.MetaDifficulty:  integer,
+				vector.MetaMinutes:     integer,
+				vector.MetaDiets:       keyword,
+				vector.MetaIngredients: keyword,
+				vector.MetaHeat:        keyword,
+				vector.MetaKcal:        float,
+				vector.MetaProtein:     float,
+				vector.MetaFat:         float,
+				vector.MetaCarbs:       float,
+			},
+		},
+	}
+}
+
+// Ensure：索引不存在时按 dims 创建；已存在时校验向量维度一致，并补充映射中新增的字段（已有字段不变；
+// 补充的字段对此后写入的文档生效）。
+// 版本索引的存储（Versions.NewStore）不沿用已存在的索引：同名索引可能正是别名当前指向的版本。
+// 返回值说明：
+//   - error：创建失败、已有索引的维度与 dims 不同（需重建索引），或版本索引已被他处创建时返回错误。
+func (s *Store) Ensure(ctx context.Context, dims int) error {
+	if dims <= 0 {
+		return fmt.Errorf("es: ensure %s: bad dims %d", s.index, dims)
+	}
+	path := "/" + url.PathEscape(s.index)
+	status, _, err := s.c.do(ctx, http.MethodHead, path, nil, "")
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		body, _ := json.Marshal(Mapping(dims))
+		status, b, err := s.c.do(ctx, http.MethodPut, path, body, "application/json")
+		if err != nil && !(status == http.StatusBadRequest && bytes.Contains(b, []byte("resource_already_exists_exception"))) {
+			return err
+		}
+		if err == nil {
+			s.created = true
+			return nil
+		}
+	}
+	if s.fresh && !s.created {
+		return fmt.Errorf("es: version index %s already exists; refusing to write into it", s.index)
+	}
+	have, err := s.dims(ctx)
+	if err != nil {
+		return err
+	}
+	if have != dims {
+		return fmt.Errorf("es: index %s has %d-dim vectors but the embedder produces %d; index into a new index", s.index, have, dims)
+	}
+	props := Mapping(dims)["mappings"].(map[string]any)["properties"].(map[string]any)
+	delete(props, FieldVector)
+	body, _ := json.Marshal(map[string]any{"properties": props})
+	_, _, err = s.c.do(ctx, http.MethodPut, path+"/_mapping", body, "application/json")
+	return err
+}
+
+// dims：读取已有索引映射中的向量维度（索引名可以是别名）。
+func (s *Store) dims(ctx context.Context) (int, error) {
+	_, b, err := s.c.do(ctx, http.MethodGet, "/"+url.PathEscape(s.index)+"/_mapping", nil, "")
+	if err != nil {
+		return 0, err
+	}
+	var resp map[string]struct {
+		Mappings struct {
+			Properties map[string]struct {
+				Dims int `json:"dims"`
+			} `json:"properties"`
+		} `json:"mappings"`
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return 0, fmt.Errorf("es: decode mapping of %s: %w", s.index, err)
+	}
+	for _, m := range resp {
+		if p, ok := m.Mappings.Properties[FieldVector]; ok {
+			return p.Dims, nil
+		}
+	}
+	return 0, fmt.Errorf("es: index %s has no %s field", s.index, FieldVector)
+}
+
+// Upsert：分批写入文档（bulk index，按 ID 覆盖）。
+// 返回值说明：
+//   - error：文档缺少向量时立即返回；部分文档失败时返回 *vector.BulkError（其余批次继续写入）；请求失败时返回错误。
+func (s *Store) Upsert(ctx context.Context, docs []*vector.Document) error {
+	acts := make([]action, 0, len(docs))
+	for _, d := range docs {
+		v := d.DenseVector()
+		if len(v) == 0 {
+			return fmt.Errorf("es: upsert %s: document has no dense vector", d.ID)
+		}
+		acts = append(acts, action{op: "index", id: d.ID, source: source(d, v)})
+	}
+	return s.run(ctx, acts)
+}
+
+// Delete：分批删除文档；不存在的 ID 不视为失败。
+func (s *Store) Delete(ctx context.Context, ids []string) error {
+	acts := make([]action, 0, len(ids))
+	for _, id := range ids {
+		acts = append(acts, action{op: "delete", id: id})
+	}
+	return s.run(ctx, acts)
+}
+
+// Prune：删除不在 keep 中的全部文档（delete_by_query），返回删除条数；完成后刷新索引。
+func (s *Store) Prune(ctx context.Context, keep []string) (int, error) {
+	if keep == nil {
+		keep = []string{}
+	}
+	q := map[string]any{"query": map[string]any{"bool": map[string]any{
+		"must_not": []any{map[string]any{"ids": map[string]any{"values": keep}}},
+	}}}
+	body, _ := json.Marshal(q)
+	path := "/" + url.PathEscape(s.index) + "/_delete_by_query?conflicts=proceed&refresh=true"
+	_, b, err := s.c.do(ctx, http.MethodPost, path, body, "application/json")
+	if err != nil {
+		return 0, err
+	}
+	var resp struct {
+		Deleted int `json:"deleted"`
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return 0, fmt.Errorf("es: decode delete_by_query: %w", err)
+	}
+	return resp.Deleted, nil
+}
+
+// Flush：刷新索引，使已写入的文档立即可检索。
+func (s *Store) Flush(ctx context.Context) error {
+	_, _, err := s.c.do(ctx, http.MethodPost, "/"+url.PathEscape(s.index)+"/_refresh", nil, "")
+	return err
+}
+
+// action：单条 bulk 操作。
+type action struct {
+	op     string // index/delete
+	id     string
+	source map[string]any
+}
+
+// run：按 bulkSize 分批执行；汇总各批的单条失败。
+func (s *Store) run(ctx context.Context, acts []action) error {
+	var failures []vector.Failure
+	for start := 0; start < len(acts); start += s.bulkSize {
+		end := min(start+s.bulkSize, len(acts))
+		f, err := s.bulk(ctx, acts[start:end])
+		if err != nil {
+			return err
+		}
+		failures = append(failures, f...)
+	}
+	if len(failures) > 0 {
+		return &vector.BulkError{Failures: failures}
+	}
+	return nil
+}
+
+// bulkResponse：bulk 接口响应；每个条目以操作名为键。
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string `json:"_id"`
+		Status int    `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// bulk：发送一批操作。
+// 功能说明：整批请求的网络错误与 429/5xx 由 Client 重试；单条 429（队列拒绝）的操作按退避重新提交，
+// 重试耗尽或其他单条错误记为失败；删除不存在的文档（404）视为成功。
+func (s *Store) bulk(ctx context.Context, acts []action) ([]vector.Failure, error) {
+	backoff := s.c.opts.Backoff
+	var failures []vector.Failure
+	for attempt := 0; ; attempt++ {
+		body, err := encodeBulk(s.index, acts)
+		if err != nil {
+			return nil, err
+		}
+		_, b, err := s.c.do(ctx, http.MethodPost, "/_bulk", body, "application/x-ndjson")
+		if err != nil {
+			return nil, err
+		}
+		var resp bulkResponse
+		if err := json.Unmarshal(b, &resp); err != nil {
+			return nil, fmt.Errorf("es: decode bulk response: %w", err)
+		}
+		if len(resp.Items) != len(acts) {
+			return nil, fmt.Errorf("es: bulk returned %d items for %d actions", len(resp.Items), len(acts))
+		}
+		if !resp.Errors {
+			return failures, nil
+		}
+		var retry []action
+		for i, item := range resp.Items {
+			for op, r := range item {
+				switch {
+				case r.Error == nil && r.Status < 300, op == "delete" && r.Status == http.StatusNotFound:
+				case r.Status == http.StatusTooManyRequests && attempt < max(s.c.opts.MaxRetries, 0):
+					retry = append(retry, acts[i])
+				default:
+					reason := http.StatusText(r.Status)
+					if r.Error != nil {
+						reason = r.Error.Type + ": " + truncate(r.Error.Reason, 200)
+					}
+					failures = append(failures, vector.Failure{ID: acts[i].id, Status: r.Status, Reason: reason})
+				}
+			}
+		}
+		if len(retry) == 0 {
+			return failures, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		acts = retry
+	}
+}
+
+// encodeBulk：编码 NDJSON bulk 请求体。
+func encodeBulk(index string, acts []action) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for _, a := range acts {
+		if err := enc.Encode(map[string]any{a.op: map[string]any{"_index": index, "_id": a.id}}); err != nil {
+			return nil, fmt.Errorf("es: encode bulk: %w", err)
+		}
+		if a.source == nil {
+			continue
+		}
+		if err := enc.Encode(a.source); err != nil {
+			return nil, fmt.Errorf("es: encode bulk %s: %w", a.id, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// source：文档 _source；元数据平铺，去掉得分与向量等内部键后写入 content 与 content_vector。
+func source(d *vector.Document, v []float64) map[string]any {
+	src := make(map[string]any, len(d.MetaData)+2)
+	for k, val := range d.MetaData {
+		if len(k) > 0 && k[0] == '_' {
+			continue
+		}
+		src[k] = val
+	}
+	src[FieldContent] = d.Content
+	src[FieldVector] = v
+	return src
+}
@@ -0,0 +1,283 @@
+// 文件功能：版本化索引；每次全量索引写入带时间戳的新索引，校验文档数与冒烟查询后原子切换别名，
+// 保留若干旧版本供回滚，超出的旧版本删除。
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cook/internal/recipe/vector"
+)
+
+// versionLayout：版本索引名中的时间戳格式（UTC，精确到毫秒，ES 索引名须小写）；legacyVersionLayout 为早期精确到秒的格式，仅用于解析。
+const (
+	versionLayout       = "20060102-150405.000"
+	legacyVersionLayout = "20060102-150405"
+)
+
+// DefaultKeepVersions：切换别名后保留的旧版本数。
+const DefaultKeepVersions = 2
+
+// Version：别名下的一个版本索引。
+type Version struct {
+	Index   string    `json:"index"`   // 索引名：<别名>-<时间戳>
+	Created time.Time `json:"created"` // 由索引名解析的创建时间
+	Docs    int       `json:"docs"`    // 文档数
+	Current bool      `json:"current"` // 是否为别名当前指向的版本
+}
+
+// Versions：别名与其版本索引的管理。
+//   - Alias：检索使用的别名（如 recipes）；版本索引名为 Alias-时间戳；
+//   - Keep：切换后保留的旧版本数；≤0 时为 DefaultKeepVersions。
+type Versions struct {
+	c     *Client
+	Alias string
+	Keep  int
+}
+
+// NewVersions：构造别名 alias 的版本管理。
+func NewVersions(c *Client, alias string, keep int) *Versions {
+	if keep <= 0 {
+		keep = DefaultKeepVersions
+	}
+	return &Versions{c: c, Alias: alias, Keep: keep}
+}
+
+// Name：返回 t 时刻的新版本索引名。
+func (v *Versions) Name(t time.Time) string {
+	return v.Alias + "-" + t.UTC().Format(versionLayout)
+}
+
+// NewStore：构造写入版本索引 index 的存储；与 es.NewStore 不同，索引已存在时 Ensure 返回错误而不沿用，
+// 避免同一时刻的两次索引写入同一个（可能已是别名当前指向的）版本。
+func (v *Versions) NewStore(index string, bulkSize int) *Store {
+	s := NewStore(v.c, index, bulkSize)
+	s.fresh = true
+	return s
+}
+
+// parse：解析版本索引名中的时间戳；不是该别名的版本索引时返回 false。
+func (v *Versions) parse(index string) (time.Time, bool) {
+	ts, ok := strings.CutPrefix(index, v.Alias+"-")
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(versionLayout, ts)
+	if err != nil {
+		t, err = time.Parse(legacyVersionLayout, ts)
+	}
+	return t, err == nil
+}
+
+// List：列出全部版本，按创建时间降序。
+// 返回值说明：
+//   - error：请求或解码失败时返回错误；尚无版本时返回空列表。
+func (v *Versions) List(ctx context.Context) ([]Version, error) {
+	path := "/_cat/indices/" + url.PathEscape(v.Alias+"-*") + "?format=json&h=index,docs.count&expand_wildcards=open"
+	_, b, err := v.c.do(ctx, http.MethodGet, path, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Index string `json:"index"`
+		Docs  string `json:"docs.count"`
+	}
+	if err := json.Unmarshal(b, &rows); err != nil {
+		return nil, fmt.Errorf("es: decode indices of %s: %w", v.Alias, err)
+	}
+	current, err := v.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Version
+	for _, r := range rows {
+		t, ok := v.parse(r.Index)
+		if !ok {
+			continue
+		}
+		n, _ := strconv.Atoi(r.Docs)
+		out = append(out, Version{Index: r.Index, Created: t, Docs: n, Current: r.Index == current})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Index > out[j].Index })
+	return out, nil
+}
+
+// Current：别名当前指向的索引；别名不存在时返回空串。
+func (v *Versions) Current(ctx context.Context) (string, error) {
+	indices, err := v.holders(ctx)
+	if err != nil || len(indices) == 0 {
+		return "", err
+	}
+	sort.Strings(indices)
+	return indices[len(indices)-1], nil
+}
+
+// holders：持有别名的全部索引；别名不存在时为空。
+func (v *Versions) holders(ctx context.Context) ([]string, error) {
+	_, b, err := v.c.do(ctx, http.MethodGet, "/_alias/"+url.PathEscape(v.Alias), nil, "")
+	var e *Error
+	if errors.As(err, &e) && e.Status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, fmt.Errorf("es: decode alias %s: %w", v.Alias, err)
+	}
+	out := make([]string, 0, len(resp))
+	for index := range resp {
+		out = append(out, index)
+	}
+	return out, nil
+}
+
+// Count：索引中的文档数。
+func (v *Versions) Count(ctx context.Context, index string) (int, error) {
+	_, b, err := v.c.do(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_count", nil, "")
+	if err != nil {
+		return 0, err
+	}
+	var resp struct {
+		Count int `json:"count"`
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return 0, fmt.Errorf("es: decode count of %s: %w", index, err)
+	}
+	return resp.Count, nil
+}
+
+// Swap：原子地将别名切换到 index（单个 _aliases 请求：移除其他持有者并添加 index）。
+// 功能说明：别名同名的具体索引（启用版本化之前建立的索引）无法与别名共存；replace 为 true 时在同一请求中删除该索引，
+// 否则返回错误。
+// 返回值说明：
+//   - error：index 不是该别名的版本索引、别名被具体索引占用且 replace 为 false，或请求失败时返回错误。
+func (v *Versions) Swap(ctx context.Context, index string, replace bool) error {
+	if _, ok := v.parse(index); !ok {
+		return fmt.Errorf("es: %s is not a version of %s", index, v.Alias)
+	}
+	var actions []any
+	holders, err := v.holders(ctx)
+	if err != nil {
+		return err
+	}
+	if len(holders) == 0 {
+		status, _, err := v.c.do(ctx, http.MethodHead, "/"+url.PathEscape(v.Alias), nil, "")
+		if err != nil {
+			return err
+		}
+		if status != http.StatusNotFound {
+			if !replace {
+				return fmt.Errorf("es: %s is a concrete index, not an alias; rerun with --replace-index to delete it in the swap", v.Alias)
+			}
+			actions = append(actions, map[string]any{"remove_index": map[string]any{"index": v.Alias}})
+		}
+	}
+	for _, h := range holders {
+		if h != index {
+			actions = append(actions, map[string]any{"remove": map[string]any{"index": h, "alias": v.Alias}})
+		}
+	}
+	actions = append(actions, map[string]any{"add": map[string]any{"index": index, "alias": v.Alias}})
+	body, _ := json.Marshal(map[string]any{"actions": actions})
+	_, _, err = v.c.do(ctx, http.MethodPost, "/_aliases", body, "application/json")
+	return err
+}
+
+// Trim：删除超出保留数的旧版本（当前版本之外按创建时间保留最近 Keep 个），返回删除的索引名。
+func (v *Versions) Trim(ctx context.Context) ([]string, error) {
+	versions, err := v.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	kept := 0
+	for _, ver := range versions {
+		if ver.Current {
+			continue
+		}
+		if kept < v.Keep {
+			kept++
+			continue
+		}
+		if err := v.Drop(ctx, ver.Index); err != nil {
+			return removed, err
+		}
+		removed = append(removed, ver.Index)
+	}
+	return removed, nil
+}
+
+// Drop：删除一个版本索引；拒绝删除别名当前指向的版本。
+func (v *Versions) Drop(ctx context.Context, index string) error {
+	if _, ok := v.parse(index); !ok {
+		return fmt.Errorf("es: %s is not a version of %s", index, v.Alias)
+	}
+	current, err := v.Current(ctx)
+	if err != nil {
+		return err
+	}
+	if index == current {
+		return fmt.Errorf("es: refusing to delete %s: %s points to it", index, v.Alias)
+	}
+	_, _, err = v.c.do(ctx, http.MethodDelete, "/"+url.PathEscape(index), nil, "")
+	return err
+}
+
+// Previous：当前版本之前最近的一个版本；没有当前版本或更早的版本时返回错误。
+func (v *Versions) Previous(ctx context.Context) (Version, error) {
+	versions, err := v.List(ctx)
+	if err != nil {
+		return Version{}, err
+	}
+	for i, ver := range versions {
+		if !ver.Current {
+			continue
+		}
+		if i+1 < len(versions) {
+			return versions[i+1], nil
+		}
+		break
+	}
+	return Version{}, fmt.Errorf("es: %s has no version older than the current one", v.Alias)
+}
+
+// smokeDepth：冒烟查询要求探针文档出现在前几条结果中。
+const smokeDepth = 5
+
+// Check：切换前校验版本索引。
+// 功能说明：文档数须等于 want；以探针文档自身的向量做 kNN 查询（冒烟查询），探针须出现在前 smokeDepth 条结果中，
+// 以确认映射、向量与检索链路可用。probe 为空时只校验文档数。
+// 返回值说明：
+//   - error：文档数不符、查询失败或探针未命中时返回错误。
+func (v *Versions) Check(ctx context.Context, index string, want int, probeID string, probe []float64) error {
+	n, err := v.Count(ctx, index)
+	if err != nil {
+		return err
+	}
+	if n != want {
+		return fmt.Errorf("es: %s has %d documents, want %d", index, n, want)
+	}
+	if len(probe) == 0 {
+		return nil
+	}
+	hits, err := NewStore(v.c, index, 0).Search(ctx, probe, vector.SearchOptions{TopK: smokeDepth})
+	if err != nil {
+		return fmt.Errorf("es: smoke query on %s: %w", index, err)
+	}
+	for _, h := range hits {
+		if h.ID == probeID {
+			return nil
+		}
+	}
+	return fmt.Errorf("es: smoke query on %s: %s not in the top %d of %d hits", index, probeID, smokeDepth, len(hits))
+}
@@ -0,0 +1,4 @@
+// 文件功能：查询扩展包说明。
+// 包功能：expand 包，维护原料本体（同义词组与上下位关系，内嵌默认词表，可由菜谱原料的别称括注与 aquatic 分类补充），
+// 并将查询中的原料词改写为带权重的扩展词：同义词与下位词（如「海鲜」扩展为「虾」「蟹」「鱼」），权重低于原词。
+package expand
@@ -0,0 +1,175 @@
+// 文件功能：查询扩展；在查询中按最长匹配识别本体词条，生成同义词与下位词扩展词，权重随关系与层级递减。
+package expand
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"cook/internal/recipe/textnorm"
+)
+
+// 扩展关系。
+const (
+	RelSynonym  = "synonym"  // 同义词
+	RelNarrower = "narrower" // 下位词（更具体的原料）
+)
+
+// Options：扩展参数。
+//   - SynonymWeight：同义词权重；≤0 时为 0.8；
+//   - NarrowerWeight：直接下位词权重；≤0 时为 0.6；
+//   - Decay：下位词每深一层的权重衰减系数（0～1）；≤0 或 >1 时为 0.75；
+//   - MaxDepth：下位词最大层级；≤0 时为 3；
+//   - MaxTerms：扩展词上限（按权重保留）；≤0 时为 24。
+type Options struct {
+	SynonymWeight  float64
+	NarrowerWeight float64
+	Decay          float64
+	MaxDepth       int
+	MaxTerms       int
+}
+
+// DefaultOptions：默认扩展参数。
+func DefaultOptions() Options {
+	return Options{SynonymWeight: 0.8, NarrowerWeight: 0.6, Decay: 0.75, MaxDepth: 3, MaxTerms: 24}
+}
+
+// withDefaults：填充未设置的参数。
+func (o Options) withDefaults() Options {
+	d := DefaultOptions()
+	if o.SynonymWeight <= 0 {
+		o.SynonymWeight = d.SynonymWeight
+	}
+	if o.NarrowerWeight <= 0 {
+		o.NarrowerWeight = d.NarrowerWeight
+	}
+	if o.Decay <= 0 || o.Decay > 1 {
+		o.Decay = d.Decay
+	}
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = d.MaxDepth
+	}
+	if o.MaxTerms <= 0 {
+		o.MaxTerms = d.MaxTerms
+	}
+	return o
+}
+
+// Term：一个扩展词。
+type Term struct {
+	Term     string  `json:"term"`     // 扩展词
+	From     string  `json:"from"`     // 来源：查询中匹配的本体词条
+	Relation string  `json:"relation"` // 关系：RelSynonym/RelNarrower
+	Weight   float64 `json:"weight"`   // 权重（0～1，原查询词为 1）
+}
+
+// Expansion：查询扩展结果。
+type Expansion struct {
+	Matched []string `json:"matched"` // 查询中匹配的本体词条（规范化后，按出现顺序）
+	Terms   []Term   `json:"terms"`   // 扩展词（按权重降序）
+}
+
+// Expander：查询扩展器；构建后只读，可并发使用。
+type Expander struct {
+	o       *Ontology
+	protect map[string]bool
+	maxLen  int
+	opts    Options
+}
+
+// New：构造扩展器。
+// 参数说明：
+//   - o：原料本体；
+//   - protect：受保护的词（如菜名）；查询中按最长匹配命中受保护词时，其中包含的本体词条不扩展，
+//     避免「鱼香肉丝」中的「鱼」扩展出各种鱼；
+//   - opts：扩展参数；零值使用默认值。
+func New(o *Ontology, protect []string, opts Options) *Expander {
+	e := &Expander{o: o, protect: make(map[string]bool, len(protect)), opts: opts.withDefaults()}
+	for t := range o.canon {
+		e.maxLen = max(e.maxLen, utf8.RuneCountInString(t))
+	}
+	for _, p := range protect {
+		p = textnorm.Fold(strings.TrimSpace(p))
+		if p != "" && !o.Has(p) {
+			e.protect[p] = true
+			e.maxLen = max(e.maxLen, utf8.RuneCountInString(p))
+		}
+	}
+	return e
+}
+
+// Ontology：扩展器使用的本体。
+func (e *Expander) Ontology() *Ontology { return e.o }
+
+// Match：查询中的本体词条。
+// 算法说明：查询经 textnorm.Fold 规范化后自左向右扫描，每个位置取最长的本体词条或受保护词；
+// 命中受保护词时整体跳过，命中本体词条时记录并跳过，都不命中时前进一个字。
+func (e *Expander) Match(query string) []string {
+	rs := []rune(textnorm.Fold(query))
+	var out []string
+	seen := map[string]bool{}
+	for i := 0; i < len(rs); {
+		step := 1
+		for l := min(e.maxLen, len(rs)-i); l > 0; l-- {
+			s := string(rs[i : i+l])
+			if e.o.Has(s) {
+				if !seen[s] {
+					seen[s] = true
+					out = append(out, s)
+				}
+				step = l
+				break
+			}
+			if e.protect[s] {
+				step = l
+				break
+			}
+		}
+		i += step
+	}
+	return out
+}
+
+// Expand：扩展查询。
+// 功能说明：对每个匹配的词条，同组的其他词条以 SynonymWeight 加入；第 d 层下位组的全部词条以
+// NarrowerWeight × Decay^(d−1) 加入。已出现在查询中的词不再加入；同一扩展词多次出现时取最高权重；
+// 按权重降序（同权重按生成顺序）保留至多 MaxTerms 个。
+// 返回值说明：
+//   - Expansion：没有匹配或扩展词时 Terms 为空。
+func (e *Expander) Expand(query string) Expansion {
+	folded := textnorm.Fold(query)
+	exp := Expansion{Matched: e.Match(query)}
+	idx := map[string]int{}
+	add := func(term, from, rel string, w float64) {
+		if term == from || strings.Contains(folded, term) {
+			return
+		}
+		w = math.Round(w*1000) / 1000
+		if i, ok := idx[term]; ok {
+			if w > exp.Terms[i].Weight {
+				exp.Terms[i] = Term{Term: term, From: from, Relation: rel, Weight: w}
+			}
+			return
+		}
+		idx[term] = len(exp.Terms)
+		exp.Terms = append(exp.Terms, Term{Term: term, From: from, Relation: rel, Weight: w})
+	}
+	for _, m := range exp.Matched {
+		for _, s := range e.o.Synonyms(m) {
+			add(s, m, RelSynonym, e.opts.SynonymWeight)
+		}
+		groups, depths := e.o.Narrower(m, e.opts.MaxDepth)
+		for i, g := range groups {
+			w := e.opts.NarrowerWeight * math.Pow(e.opts.Decay, float64(depths[i]-1))
+			for _, s := range e.o.members[g] {
+				add(s, m, RelNarrower, w)
+			}
+		}
+	}
+	sort.SliceStable(exp.Terms, func(i, j int) bool { return exp.Terms[i].Weight > exp.Terms[j].Weight })
+	if len(exp.Terms) > e.opts.MaxTerms {
+		exp.Terms = exp.Terms[:e.opts.MaxTerms]
+	}
+	return exp
+}
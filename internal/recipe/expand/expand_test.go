@@ -0,0 +1,96 @@
+package expand
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"cook/internal/recipe/parser"
+)
+
+func TestDefault(t *testing.T) {
+	o := NewOntology()
+	if err := o.Parse(strings.NewReader(defaultOntology)); err != nil {
+		t.Fatalf("embedded ontology: %v", err)
+	}
+	if got := o.Canonical("西紅柿"); got != "番茄" {
+		t.Fatalf("Canonical(西紅柿) = %q", got)
+	}
+	if got := fmt.Sprint(o.Broader("鸡胸")); got != "[鸡肉 禽肉 肉类]" {
+		t.Fatalf("Broader(鸡胸) = %s", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	o := NewOntology()
+	err := o.Parse(strings.NewReader("# 注释\n\n甲 = 乙\n丙 = 丁\n乙 = 丁\n戊 < 甲\n甲 < 戊\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(o.Synonyms("丁")); got != "[甲 乙 丙 丁]" || o.Len() != 2 {
+		t.Fatalf("merged group = %s, len %d", got, o.Len())
+	}
+	if got := fmt.Sprint(o.Broader("甲")); got != "[]" {
+		t.Fatalf("cycle accepted: Broader(甲) = %s", got)
+	}
+	for _, bad := range []string{"甲 =", "甲 < 乙 < 丙", "甲 乙"} {
+		if err := NewOntology().Parse(strings.NewReader(bad)); err == nil || !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("Parse(%q) err = %v", bad, err)
+		}
+	}
+}
+
+func testExpander() *Expander {
+	o := NewOntology()
+	_ = o.Parse(strings.NewReader("番茄 = 西红柿\n水产 = 海鲜\n鱼 < 水产\n虾 < 水产\n鲈鱼 < 鱼\n"))
+	return New(o, []string{"鱼香肉丝"}, Options{})
+}
+
+func TestExpand(t *testing.T) {
+	e := testExpander()
+	exp := e.Expand("番茄炒蛋")
+	if fmt.Sprint(exp.Matched) != "[番茄]" || fmt.Sprint(exp.Terms) != "[{西红柿 番茄 synonym 0.8}]" {
+		t.Fatalf("番茄炒蛋 = %+v", exp)
+	}
+	exp = e.Expand("海鲜有哪些做法")
+	want := "[{水产 海鲜 synonym 0.8} {鱼 海鲜 narrower 0.6} {虾 海鲜 narrower 0.6} {鲈鱼 海鲜 narrower 0.45}]"
+	if fmt.Sprint(exp.Terms) != want {
+		t.Fatalf("海鲜 = %v", exp.Terms)
+	}
+	if exp := e.Expand("鱼香肉丝怎么做"); len(exp.Matched) != 0 || len(exp.Terms) != 0 {
+		t.Fatalf("protected dish expanded: %+v", exp)
+	}
+	if exp := e.Expand("番茄和西红柿"); len(exp.Terms) != 0 {
+		t.Fatalf("term already in query: %+v", exp)
+	}
+	small := New(e.Ontology(), nil, Options{MaxTerms: 2, MaxDepth: 1})
+	if got := small.Expand("水产"); fmt.Sprint(got.Terms) != "[{海鲜 水产 synonym 0.8} {鱼 水产 narrower 0.6}]" {
+		t.Fatalf("capped = %v", got.Terms)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	o := NewOntology()
+	_ = o.Parse(strings.NewReader("鱼 < 水产\n蒸鱼豉油 < 调味料\n"))
+	o.Seed([]*parser.Recipe{{
+		Title:       "清蒸鳕鱼",
+		Category:    "aquatic",
+		Ingredients: []string{"黑鳕鱼", "蒸鱼豉油", "青蟹（别称：肉蟹）"},
+	}, {
+		Title:       "剁椒鱼头",
+		Category:    "meat_dish",
+		Ingredients: []string{"鲢鱼"},
+	}}, "aquatic", "水产")
+	if got := fmt.Sprint(o.Broader("黑鳕鱼")); got != "[鱼 水产]" {
+		t.Fatalf("Broader(黑鳕鱼) = %s", got)
+	}
+	if got := fmt.Sprint(o.Broader("蒸鱼豉油")); got != "[调味料]" {
+		t.Fatalf("Broader(蒸鱼豉油) = %s", got)
+	}
+	if o.Has("鲢鱼") {
+		t.Fatal("seeded an ingredient outside the category")
+	}
+	if o.Canonical("肉蟹") != "青蟹" {
+		t.Fatalf("alias not seeded: %v", o.Synonyms("肉蟹"))
+	}
+}
@@ -0,0 +1,315 @@
+// 文件功能：原料本体；同义词组以规范名标识，上下位关系建立在同义词组之间；支持从文本词表加载与从菜谱补充。
+package expand
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/textnorm"
+)
+
+//go:embed ontology.txt
+var defaultOntology string
+
+// Ontology：原料本体；非并发安全，构建完成后只读使用。
+// 词条统一经 textnorm.Fold 规范化（繁体转简体、小写）。
+type Ontology struct {
+	canon    map[string]string   // 词条 -> 所在同义词组的规范名
+	members  map[string][]string // 规范名 -> 组内词条（规范名在前）
+	parents  map[string][]string // 规范名 -> 上位组规范名
+	children map[string][]string // 规范名 -> 下位组规范名
+}
+
+// NewOntology：返回空本体。
+func NewOntology() *Ontology {
+	return &Ontology{
+		canon:    map[string]string{},
+		members:  map[string][]string{},
+		parents:  map[string][]string{},
+		children: map[string][]string{},
+	}
+}
+
+// Default：返回内嵌默认词表构成的本体（每次调用返回新实例，可继续补充）。
+func Default() *Ontology {
+	o := NewOntology()
+	if err := o.Parse(strings.NewReader(defaultOntology)); err != nil {
+		panic(err) // 内嵌词表由测试保证格式正确
+	}
+	return o
+}
+
+// Parse：加载词表并合并到本体。
+// 功能说明：每行「A = B = C」为同义词组（A 为规范名），「A < B」为 A 是 B 的一种；空行与 # 开头的行忽略。
+// 返回值说明：
+//   - error：读取失败或行格式无法识别时返回错误（含行号）。
+func (o *Ontology) Parse(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		switch {
+		case strings.Contains(line, "="):
+			terms := splitTerms(line, "=")
+			if len(terms) < 2 {
+				return fmt.Errorf("expand: line %d: synonym group needs two terms: %q", n, line)
+			}
+			o.AddSynonyms(terms...)
+		case strings.Contains(line, "<"):
+			terms := splitTerms(line, "<")
+			if len(terms) != 2 {
+				return fmt.Errorf("expand: line %d: want \"child < parent\": %q", n, line)
+			}
+			o.AddIsA(terms[0], terms[1])
+		default:
+			return fmt.Errorf("expand: line %d: want \"a = b\" or \"child < parent\": %q", n, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("expand: read ontology: %w", err)
+	}
+	return nil
+}
+
+// splitTerms：按分隔符拆分并去掉空项。
+func splitTerms(line, sep string) []string {
+	var out []string
+	for _, t := range strings.Split(line, sep) {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// group：返回词条所在组的规范名；不存在时新建单词条组。
+func (o *Ontology) group(term string) string {
+	term = textnorm.Fold(strings.TrimSpace(term))
+	if c, ok := o.canon[term]; ok {
+		return c
+	}
+	o.canon[term] = term
+	o.members[term] = []string{term}
+	return term
+}
+
+// AddSynonyms：将 terms 并入同一同义词组；涉及多个已有组时合并，规范名取第一个词条所在组的规范名。
+func (o *Ontology) AddSynonyms(terms ...string) {
+	if len(terms) == 0 {
+		return
+	}
+	root := o.group(terms[0])
+	for _, t := range terms[1:] {
+		g := o.group(t)
+		if g == root {
+			continue
+		}
+		for _, m := range o.members[g] {
+			o.canon[m] = root
+		}
+		o.members[root] = append(o.members[root], o.members[g]...)
+		for _, p := range o.parents[g] {
+			o.children[p] = replace(o.children[p], g, root)
+			o.parents[root] = appendNew(o.parents[root], p)
+		}
+		for _, c := range o.children[g] {
+			o.parents[c] = replace(o.parents[c], g, root)
+			o.children[root] = appendNew(o.children[root], c)
+		}
+		delete(o.members, g)
+		delete(o.parents, g)
+		delete(o.children, g)
+		o.parents[root] = slices.DeleteFunc(o.parents[root], func(s string) bool { return s == root })
+		o.children[root] = slices.DeleteFunc(o.children[root], func(s string) bool { return s == root })
+	}
+}
+
+// AddIsA：登记 child 是 parent 的一种；两者同组或会形成环时忽略。
+func (o *Ontology) AddIsA(child, parent string) {
+	c, p := o.group(child), o.group(parent)
+	if c == p || o.reaches(p, c) {
+		return
+	}
+	o.parents[c] = appendNew(o.parents[c], p)
+	o.children[p] = appendNew(o.children[p], c)
+}
+
+// reaches：from 是否可经上位关系到达 to（即 from 是 to 的下位组）。
+func (o *Ontology) reaches(from, to string) bool {
+	seen := map[string]bool{}
+	stack := []string{from}
+	for len(stack) > 0 {
+		g := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if g == to {
+			return true
+		}
+		if seen[g] {
+			continue
+		}
+		seen[g] = true
+		stack = append(stack, o.parents[g]...)
+	}
+	return false
+}
+
+// replace：将切片中的 old 替换为 new（new 已存在时删除 old）。
+func replace(s []string, old, new string) []string {
+	if slices.Contains(s, new) {
+		return slices.DeleteFunc(s, func(x string) bool { return x == old })
+	}
+	for i, x := range s {
+		if x == old {
+			s[i] = new
+		}
+	}
+	return s
+}
+
+// appendNew：追加不在切片中的元素。
+func appendNew(s []string, v string) []string {
+	if slices.Contains(s, v) {
+		return s
+	}
+	return append(s, v)
+}
+
+// Has：词条是否在本体中。
+func (o *Ontology) Has(term string) bool {
+	_, ok := o.canon[textnorm.Fold(term)]
+	return ok
+}
+
+// Canonical：词条所在同义词组的规范名；不在本体中时返回空串。
+func (o *Ontology) Canonical(term string) string {
+	return o.canon[textnorm.Fold(term)]
+}
+
+// Synonyms：词条所在组的全部词条（规范名在前，含词条本身）；不在本体中时返回 nil。
+func (o *Ontology) Synonyms(term string) []string {
+	c, ok := o.canon[textnorm.Fold(term)]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(o.members[c])
+}
+
+// Narrower：词条的下位组规范名，按层级广度优先；depth 为层级（直接下位为 1），maxDepth ≤ 0 时不限层级。
+func (o *Ontology) Narrower(term string, maxDepth int) (groups []string, depths []int) {
+	c, ok := o.canon[textnorm.Fold(term)]
+	if !ok {
+		return nil, nil
+	}
+	seen := map[string]bool{c: true}
+	level := []string{c}
+	for d := 1; len(level) > 0 && (maxDepth <= 0 || d <= maxDepth); d++ {
+		var next []string
+		for _, g := range level {
+			for _, ch := range o.children[g] {
+				if !seen[ch] {
+					seen[ch] = true
+					groups = append(groups, ch)
+					depths = append(depths, d)
+					next = append(next, ch)
+				}
+			}
+		}
+		level = next
+	}
+	return groups, depths
+}
+
+// Broader：词条的全部上位组规范名（广度优先）。
+func (o *Ontology) Broader(term string) []string {
+	c, ok := o.canon[textnorm.Fold(term)]
+	if !ok {
+		return nil
+	}
+	var out []string
+	seen := map[string]bool{c: true}
+	level := []string{c}
+	for len(level) > 0 {
+		var next []string
+		for _, g := range level {
+			for _, p := range o.parents[g] {
+				if !seen[p] {
+					seen[p] = true
+					out = append(out, p)
+					next = append(next, p)
+				}
+			}
+		}
+		level = next
+	}
+	return out
+}
+
+// Terms：全部词条。
+func (o *Ontology) Terms() []string {
+	out := make([]string, 0, len(o.canon))
+	for t := range o.canon {
+		out = append(out, t)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Len：同义词组数。
+func (o *Ontology) Len() int { return len(o.members) }
+
+// maxSeedRunes：补充为品种的原料名的最大字数；更长的通常是带说明的整句。
+const maxSeedRunes = 6
+
+// Seed：由菜谱补充本体。
+// 功能说明：
+//  1. 原料清单中的别称括注（parser.IngredientAliases）登记为同义词；
+//  2. 分类为 category 的菜谱中，尚无上位词的原料名若以 root 的某个下位词条结尾（取最长者，如「黑鳕鱼」以「鳕鱼」结尾），
+//     登记为该词条的下位词；「蒸鱼豉油」等已登记的原料不受影响。
+//
+// 参数说明：
+//   - recipes：菜谱；
+//   - category/root：补充品种的分类与上位词（如 aquatic 与 水产）；root 不在本体中时只登记别称。
+func (o *Ontology) Seed(recipes []*parser.Recipe, category, root string) {
+	for _, r := range recipes {
+		for _, p := range parser.IngredientAliases(r.Ingredients) {
+			if utf8.RuneCountInString(p[0]) <= maxSeedRunes && utf8.RuneCountInString(p[1]) <= maxSeedRunes {
+				o.AddSynonyms(p[0], p[1])
+			}
+		}
+	}
+	if !o.Has(root) {
+		return
+	}
+	groups, _ := o.Narrower(root, 0)
+	var classes []string
+	for _, g := range groups {
+		classes = append(classes, o.members[g]...)
+	}
+	// 长词条优先匹配。
+	slices.SortStableFunc(classes, func(a, b string) int { return len(b) - len(a) })
+	for _, r := range recipes {
+		if r.Category != category {
+			continue
+		}
+		for _, name := range parser.IngredientNames(r.Ingredients) {
+			name = textnorm.Fold(name)
+			if utf8.RuneCountInString(name) > maxSeedRunes || len(o.parents[o.canon[name]]) > 0 {
+				continue
+			}
+			for _, cls := range classes {
+				if name != cls && strings.HasSuffix(name, cls) {
+					o.AddIsA(name, cls)
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,3 @@
+// 文件功能：语料导出包说明。
+// 包功能：export 包，将解析得到的 Chunk 与 Recipe 写出为 JSONL、CSV、Eino Document、schema.org JSON-LD 与结构化 NDJSON 等格式。
+package export
@@ -0,0 +1,266 @@
+// 文件功能：多格式导出实现；面向表格、向量工具与 Web 前端等不同消费方写出语料。
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cook/internal/recipe/heat"
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/units"
+	"cook/internal/recipe/vector"
+)
+
+// 支持的导出格式名称。
+const (
+	FormatJSONL   = "jsonl"   // 每行一个 types.Chunk
+	FormatCSV     = "csv"     // Chunk 表格（带 UTF-8 BOM，便于表格软件识别中文）
+	FormatEino    = "eino"    // Eino schema.Document JSON 数组
+	FormatJSONLD  = "jsonld"  // 每行一个 schema.org Recipe JSON-LD
+	FormatRecipes = "recipes" // 每行一个结构化 Recipe 记录（字段固定，便于列式工具加载）
+)
+
+// Formats：全部支持的格式名称。
+var Formats = []string{FormatJSONL, FormatCSV, FormatEino, FormatJSONLD, FormatRecipes}
+
+var minutesRegex = regexp.MustCompile(`(\d+)\s*分钟`)
+
+// IsRecipeFormat：判断格式是否以 Recipe（而非 Chunk）为输出单位。
+func IsRecipeFormat(format string) bool {
+	return format == FormatJSONLD || format == FormatRecipes
+}
+
+// DefaultFileName：格式对应的默认输出文件名。
+func DefaultFileName(format string) string {
+	switch format {
+	case FormatCSV:
+		return "chunks.csv"
+	case FormatEino:
+		return "documents.json"
+	case FormatJSONLD:
+		return "recipes.jsonld"
+	case FormatRecipes:
+		return "recipes.ndjson"
+	default:
+		return "chunks.jsonl"
+	}
+}
+
+// WriteChunks：按格式写出 Chunk 集合。
+// 参数说明：
+//   - w：输出目标；函数内部带缓冲并在返回前刷新；
+//   - format：jsonl、csv 或 eino；
+//   - chunks：待写出的分块。
+//
+// 返回值说明：
+//   - error：格式不支持或写出失败时返回错误。
+func WriteChunks(w io.Writer, format string, chunks []types.Chunk) error {
+	bw := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case FormatJSONL, "":
+		err = writeChunkJSONL(bw, chunks)
+	case FormatCSV:
+		err = writeChunkCSV(bw, chunks)
+	case FormatEino:
+		err = writeDocuments(bw, chunks)
+	default:
+		return fmt.Errorf("export: format %q does not take chunks", format)
+	}
+	if err != nil {
+		return fmt.Errorf("export %s: %w", format, err)
+	}
+	return bw.Flush()
+}
+
+// WriteRecipes：按格式写出 Recipe 集合。
+// 参数说明：
+//   - w：输出目标；
+//   - format：jsonld 或 recipes；
+//   - recipes：待写出的结构化菜谱。
+//
+// 返回值说明：
+//   - error：格式不支持或写出失败时返回错误。
+func WriteRecipes(w io.Writer, format string, recipes []*parser.Recipe) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	for _, r := range recipes {
+		var v any
+		switch format {
+		case FormatJSONLD:
+			v = toJSONLD(r)
+		case FormatRecipes:
+			v = toRecord(r)
+		default:
+			return fmt.Errorf("export: format %q does not take recipes", format)
+		}
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("export %s: %w", format, err)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeChunkJSONL：每行一个 Chunk。
+func writeChunkJSONL(w io.Writer, chunks []types.Chunk) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, c := range chunks {
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkColumns：CSV 表头；与 types.Chunk 的 JSON 字段名一致。
+var chunkColumns = []string{"id", "doc_id", "index", "header", "text", "source", "category", "name", "path"}
+
+// writeChunkCSV：写出带表头的 CSV；首部写入 UTF-8 BOM。
+func writeChunkCSV(w io.Writer, chunks []types.Chunk) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(chunkColumns); err != nil {
+		return err
+	}
+	for _, c := range chunks {
+		row := []string{c.ID, c.DocID, strconv.Itoa(c.Index), c.Header, c.Text, c.Source, c.Category, c.Name, c.Path}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeDocuments：写出 Eino Document JSON 数组；转换规则见 vector.FromChunk。
+func writeDocuments(w io.Writer, chunks []types.Chunk) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(vector.FromChunks(chunks))
+}
+
+// toJSONLD：将 Recipe 映射为 schema.org Recipe 对象；空字段省略。
+func toJSONLD(r *parser.Recipe) map[string]any {
+	obj := map[string]any{
+		"@context": "https://schema.org",
+		"@type":    "Recipe",
+		"name":     r.Title,
+	}
+	if r.Notes != "" {
+		obj["description"] = r.Notes
+	}
+	if r.Category != "" {
+		obj["recipeCategory"] = r.Category
+	}
+	if r.Path != "" {
+		obj["url"] = r.Path
+	}
+	if r.Servings > 0 {
+		obj["recipeYield"] = strconv.Itoa(r.Servings)
+	}
+	if m := minutesRegex.FindStringSubmatch(r.CookingTime); m != nil {
+		obj["totalTime"] = "PT" + m[1] + "M"
+	}
+	if len(r.Tags) > 0 {
+		obj["keywords"] = strings.Join(r.Tags, ",")
+	}
+	if len(r.Ingredients) > 0 {
+		obj["recipeIngredient"] = r.Ingredients
+	}
+	if len(r.Steps) > 0 {
+		steps := make([]map[string]any, 0, len(r.Steps))
+		for _, s := range r.Steps {
+			steps = append(steps, map[string]any{"@type": "HowToStep", "text": s})
+		}
+		obj["recipeInstructions"] = steps
+	}
+	if len(r.Nutrition) > 0 {
+		n := map[string]any{"@type": "NutritionInformation"}
+		for k, v := range r.Nutrition {
+			n[k] = v
+		}
+		obj["nutrition"] = n
+	}
+	return obj
+}
+
+// record：结构化 Recipe 导出记录；字段始终输出（切片与映射不为 null），保证每行模式一致。
+type record struct {
+	Title       string            `json:"title"`
+	Category    string            `json:"category"`
+	Path        string            `json:"path"`
+	Servings    int               `json:"servings"`
+	CookingTime string            `json:"cooking_time"`
+	Difficulty  string            `json:"difficulty"`
+	Tags        []string          `json:"tags"`
+	Ingredients []string          `json:"ingredients"`
+	Steps       []string          `json:"steps"`
+	Notes       string            `json:"notes"`
+	Nutrition   map[string]string `json:"nutrition"`
+	Quantities  []units.Quantity  `json:"quantities"`
+	Oven        []heat.Heat       `json:"oven"`
+
+	StepQuantities [][]units.Quantity `json:"step_quantities"` // 与 steps 一一对应
+	StepHeat       [][]heat.Heat      `json:"step_heat"`       // 与 steps 一一对应
+}
+
+// toRecord：将 Recipe 转换为导出记录。
+func toRecord(r *parser.Recipe) record {
+	rec := record{
+		Title:       r.Title,
+		Category:    r.Category,
+		Path:        r.Path,
+		Servings:    r.Servings,
+		CookingTime: r.CookingTime,
+		Difficulty:  r.Difficulty,
+		Tags:        nonNil(r.Tags),
+		Ingredients: nonNil(r.Ingredients),
+		Steps:       nonNil(r.Steps),
+		Notes:       r.Notes,
+		Nutrition:   r.Nutrition,
+		Quantities:  r.Quantities,
+	}
+	if rec.Oven = heat.Oven(r.StepHeat); rec.Oven == nil {
+		rec.Oven = []heat.Heat{}
+	}
+	if rec.Quantities == nil {
+		rec.Quantities = []units.Quantity{}
+	}
+	// 关键逻辑：逐步骤输出，未识别的步骤为 []，使下标始终与 steps 对应；
+	rec.StepQuantities = make([][]units.Quantity, len(rec.Steps))
+	rec.StepHeat = make([][]heat.Heat, len(rec.Steps))
+	for i := range rec.Steps {
+		rec.StepQuantities[i] = []units.Quantity{}
+		rec.StepHeat[i] = []heat.Heat{}
+		if i < len(r.StepQuantities) && r.StepQuantities[i] != nil {
+			rec.StepQuantities[i] = r.StepQuantities[i]
+		}
+		if i < len(r.StepHeat) && r.StepHeat[i] != nil {
+			rec.StepHeat[i] = r.StepHeat[i]
+		}
+	}
+	if rec.Nutrition == nil {
+		rec.Nutrition = map[string]string{}
+	}
+	return rec
+}
+
+// nonNil：nil 切片转换为空切片，使 JSON 输出为 []。
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
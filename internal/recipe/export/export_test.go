@@ -0,0 +1,66 @@
+// 文件功能：导出格式的单元测试；验证 CSV 表头、Eino Document 结构与结构化记录的字段稳定性。
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/vector"
+)
+
+func TestWriteChunks(t *testing.T) {
+	chunks := []types.Chunk{{ID: "chunk-1", DocID: "doc-0", Header: "## 操作", Text: "切块, \"备用\"", Category: "aquatic", Name: "红烧鱼", Path: "aquatic/红烧鱼.md"}}
+
+	var buf bytes.Buffer
+	if err := WriteChunks(&buf, FormatCSV, chunks); err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "\ufeffid,doc_id,index,header,text") {
+		t.Fatalf("csv header = %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteChunks(&buf, FormatEino, chunks); err != nil {
+		t.Fatalf("eino: %v", err)
+	}
+	var docs []vector.Document
+	if err := json.Unmarshal(buf.Bytes(), &docs); err != nil {
+		t.Fatalf("decode eino: %v", err)
+	}
+	if len(docs) != 1 || docs[0].ID != "chunk-1" || docs[0].MetaData["category"] != "aquatic" {
+		t.Fatalf("unexpected docs: %+v", docs)
+	}
+
+	if err := WriteChunks(&buf, FormatRecipes, chunks); err == nil {
+		t.Fatalf("expected error for recipe format")
+	}
+}
+
+func TestWriteRecipes(t *testing.T) {
+	recipes := []*parser.Recipe{{Title: "红烧鱼", Servings: 2, CookingTime: "30 分钟", Steps: []string{"煎鱼"}}}
+
+	var buf bytes.Buffer
+	if err := WriteRecipes(&buf, FormatRecipes, recipes); err != nil {
+		t.Fatalf("recipes: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"tags":[]`) || !strings.Contains(buf.String(), `"nutrition":{}`) || !strings.Contains(buf.String(), `"oven":[]`) ||
+		!strings.Contains(buf.String(), `"step_quantities":[[]]`) || !strings.Contains(buf.String(), `"step_heat":[[]]`) {
+		t.Fatalf("record not stable: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteRecipes(&buf, FormatJSONLD, recipes); err != nil {
+		t.Fatalf("jsonld: %v", err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("decode jsonld: %v", err)
+	}
+	if obj["@type"] != "Recipe" || obj["totalTime"] != "PT30M" || obj["recipeYield"] != "2" {
+		t.Fatalf("unexpected jsonld: %v", obj)
+	}
+}
@@ -0,0 +1,4 @@
+// 文件功能：火候与温度包说明。
+// 包功能：heat 包，从步骤正文中识别火力（大火、中小火……）、油温（七成热）与明确温度（180℃、上下火 170 度、350°F），
+// 统一为近似摄氏温度区间与命名火候，供按步骤展示火候与查询烤箱设置使用。
+package heat
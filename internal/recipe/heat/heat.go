@@ -0,0 +1,319 @@
+// 文件功能：火候识别；从步骤正文中识别火力、油温与明确温度，统一为近似摄氏温度区间与命名火候。
+package heat
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"cook/internal/recipe/textnorm"
+	"cook/internal/recipe/units"
+)
+
+// 热源类型。
+const (
+	KindFlame = "flame" // 灶具火力（大火、中小火）
+	KindOil   = "oil"   // 油温（七成热、油温 180℃）
+	KindOven  = "oven"  // 烤箱、空气炸锅
+	KindWater = "water" // 水温
+	KindTemp  = "temp"  // 其他明确温度
+)
+
+// Heat：一处火候描述。
+//   - Kind：热源类型；
+//   - Level：命名火候；灶具为微火…大火，油温为温油/热油/旺油，烤箱为低温…高温，水温为冷水…沸水；
+//     其他明确温度 100℃ 以内按水温、以上按烤箱阶梯命名；
+//   - Min/Max：近似摄氏温度区间；
+//   - Element：烤箱加热管（上下火/上火/下火）；
+//   - Cue：原文给出的判断依据（「七成热（竹筷子起泡）」中的「竹筷子起泡」）；
+//   - Text：原文片段。
+type Heat struct {
+	Kind    string  `json:"kind"`              // 热源类型
+	Level   string  `json:"level"`             // 命名火候
+	Min     float64 `json:"min_c"`             // 温度下限（℃）
+	Max     float64 `json:"max_c"`             // 温度上限（℃）
+	Element string  `json:"element,omitempty"` // 加热管
+	Cue     string  `json:"cue,omitempty"`     // 判断依据
+	Text    string  `json:"text"`              // 原文
+}
+
+// level：命名火候及其近似温度区间。
+type level struct {
+	name     string
+	min, max float64
+}
+
+// 火候阶梯；灶具火力取锅面近似温度，油温取「一成约 30℃」的经验换算。
+var (
+	flameLevels = []level{{"微火", 90, 120}, {"小火", 120, 150}, {"中小火", 150, 170}, {"中火", 170, 200}, {"中大火", 200, 230}, {"大火", 230, 260}}
+	oilLevels   = []level{{"温油", 60, 130}, {"热油", 130, 190}, {"旺油", 190, 250}}
+	ovenLevels  = []level{{"低温", 0, 140}, {"中低温", 140, 160}, {"中温", 160, 190}, {"中高温", 190, 220}, {"高温", 220, 300}}
+	waterLevels = []level{{"冷水", 0, 25}, {"温水", 25, 60}, {"热水", 60, 95}, {"沸水", 95, 100}}
+)
+
+// flameAliases：火力写法 → 规范火候。
+var flameAliases = map[string]string{
+	"微火": "微火", "最小火": "微火",
+	"小火": "小火", "文火": "小火", "慢火": "小火",
+	"中小火": "中小火", "中低火": "中小火",
+	"中火":  "中火",
+	"中大火": "中大火", "中高火": "中大火",
+	"大火": "大火", "旺火": "大火", "猛火": "大火", "武火": "大火", "急火": "大火", "最大火": "大火",
+}
+
+// oilDegree：一成油温对应的摄氏度。
+const oilDegree = 30
+
+var (
+	flameRegex = func() *regexp.Regexp {
+		names := make([]string, 0, len(flameAliases))
+		for k := range flameAliases {
+			names = append(names, k)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return len(names[i]) > len(names[j]) || len(names[i]) == len(names[j]) && names[i] < names[j]
+		})
+		return regexp.MustCompile(`(` + strings.Join(names, "|") + `)([锅腿龙车山柴鸡]?)`)
+	}()
+	num       = `\d+(?:\.\d+)?|[零一二三四五六七八九十百两]+`
+	oilRegex  = regexp.MustCompile(`(油温\s*(?:烧|升|加热)?\s*(?:至|到)?\s*)?(` + num + `)(?:\s*(?:-|~|到|至)\s*(` + num + `))?\s*成\s*(热|油温|温)?`)
+	tempRegex = regexp.MustCompile(`(?:(上下火|上火|下火)\s*各?\s*)?(?:摄氏\s*)?(` + num + `)(?:\s*(-|~|到|至|±)\s*(` + num + `))?\s*(°c|℃|摄氏度|°f|℉|华氏度|度|°)`)
+	cueRegex  = regexp.MustCompile(`^\s*\(([^()]{1,30})\)`)
+
+	ovenCue  = regexp.MustCompile(`烤箱|预热|烘烤|烤盘|空气炸锅|上下火|上火|下火|烤|oven|bake|preheat`)
+	oilCue   = regexp.MustCompile(`油温|油`)
+	waterCue = regexp.MustCompile(`水温|水`)
+	// degreeCue：裸写「度」时须有温度语境；「切成 45 度角」「9 度的米醋」不是温度。
+	degreeCue = regexp.MustCompile(`温|烤|热|火|油|水|冷藏|冰箱|烘|炸|煮|蒸|沸`)
+)
+
+// Extract：识别步骤正文中的火候，按出现顺序返回。
+// 功能说明：
+//  1. 火力写法（大火、旺火、中小火……）映射为规范火候，附近似锅面温度；
+//  2. 「N 成热」「油温六七成」按一成约 30℃ 换算，括号内的判断依据写入 Cue；
+//  3. 明确温度（180℃、350°F、上下火 170 度、67±1°C、一百八十度）换算为摄氏度，按上下文判定为烤箱、油温、水温或其他；
+//  4. 文本先经 textnorm.Fold 折叠，全角数字、繁体与全角括号均可识别；Text 取原文片段。
+func Extract(step string) []Heat {
+	orig := []rune(step)
+	key := textnorm.Fold(step)
+	type hit struct {
+		start, end int // rune 位置
+		h          Heat
+	}
+	var hits []hit
+	add := func(startB, endB int, h Heat) {
+		s := utf8.RuneCountInString(key[:startB])
+		e := s + utf8.RuneCountInString(key[startB:endB])
+		hits = append(hits, hit{s, e, h})
+	}
+	oven := ovenCue.MatchString(key)
+
+	for _, m := range flameRegex.FindAllStringSubmatchIndex(key, -1) {
+		if m[5] > m[4] {
+			continue // 小火锅、火腿等
+		}
+		name := flameAliases[key[m[2]:m[3]]]
+		lv := find(flameLevels, name)
+		add(m[0], m[1], Heat{Kind: KindFlame, Level: name, Min: lv.min, Max: lv.max})
+	}
+	for _, m := range oilRegex.FindAllStringSubmatchIndex(key, -1) {
+		if m[3] <= m[2] && m[9] <= m[8] {
+			continue // 既无「油温」前缀也无「热」后缀：「七成熟」「擀成」
+		}
+		lo, hi, ok := amount(key, m[4], m[5], m[6], m[7])
+		if !ok || lo <= 0 || hi > 10 {
+			continue
+		}
+		h := Heat{Kind: KindOil, Min: lo*oilDegree - 10, Max: hi*oilDegree + 10}
+		end := m[1]
+		if c := cueRegex.FindStringSubmatchIndex(key[end:]); c != nil {
+			h.Cue = strings.TrimSpace(key[end+c[2] : end+c[3]])
+			end += c[1]
+		}
+		h.Level = byTemp(oilLevels, h.Min, h.Max)
+		add(m[0], end, h)
+	}
+	for _, m := range tempRegex.FindAllStringSubmatchIndex(key, -1) {
+		unit := key[m[10]:m[11]]
+		if (unit == "度" || unit == "°") && !plausibleDegree(key, m[0], m[1]) {
+			continue
+		}
+		lo, hi, ok := amount(key, m[4], m[5], -1, -1)
+		if !ok {
+			continue
+		}
+		if m[8] >= 0 {
+			b, ok := units.ParseAmount(key[m[8]:m[9]])
+			if !ok {
+				continue
+			}
+			if key[m[6]:m[7]] == "±" {
+				lo, hi = lo-b.Value, lo+b.Value
+			} else {
+				hi = b.Max
+			}
+		}
+		if unit == "°f" || unit == "℉" || unit == "华氏度" {
+			lo, hi = fahrenheit(lo), fahrenheit(hi)
+		}
+		if lo > hi || hi > 500 {
+			continue
+		}
+		h := Heat{Min: lo, Max: hi}
+		if m[2] >= 0 {
+			h.Element = key[m[2]:m[3]]
+		}
+		// 关键逻辑：油温、水温语境取温度前 6 字与后 4 字（「油温升至 180℃」「40℃ 的温水」）
+		near := lastRunes(key[:m[0]], 6) + firstRunes(key[m[1]:], 4)
+		switch {
+		case h.Element != "" || oven:
+			h.Kind, h.Level = KindOven, byTemp(ovenLevels, lo, hi)
+		case oilCue.MatchString(near):
+			h.Kind, h.Level = KindOil, byTemp(oilLevels, lo, hi)
+		case waterCue.MatchString(near):
+			h.Kind, h.Level = KindWater, byTemp(waterLevels, lo, hi)
+		case hi <= 100:
+			h.Kind, h.Level = KindTemp, byTemp(waterLevels, lo, hi)
+		default:
+			h.Kind, h.Level = KindTemp, byTemp(ovenLevels, lo, hi)
+		}
+		add(m[0], m[1], h)
+	}
+
+	// 关键逻辑：同一片段只保留最先识别的一处（油温括号中的「240℃」已作为 Cue，不再单列）
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].start < hits[j].start })
+	out := make([]Heat, 0, len(hits))
+	last := -1
+	for _, h := range hits {
+		if h.start < last {
+			continue
+		}
+		h.h.Text = strings.TrimSpace(string(orig[h.start:h.end]))
+		if h.h.Cue != "" {
+			h.h.Cue = cueText(orig[h.start:h.end])
+		}
+		out = append(out, h.h)
+		last = h.end
+	}
+	return out
+}
+
+// Oven：从各步骤的火候中取出烤箱设置（去重，保持顺序）。
+func Oven(steps [][]Heat) []Heat {
+	var out []Heat
+	seen := make(map[Heat]bool)
+	for _, hs := range steps {
+		for _, h := range hs {
+			k := h
+			k.Text = ""
+			if h.Kind == KindOven && !seen[k] {
+				seen[k] = true
+				out = append(out, h)
+			}
+		}
+	}
+	return out
+}
+
+// Levels：各步骤火候的命名火候（去重，保持首次出现的顺序），用作分块元数据。
+func Levels(steps [][]Heat) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, hs := range steps {
+		for _, h := range hs {
+			if h.Level != "" && !seen[h.Level] {
+				seen[h.Level] = true
+				out = append(out, h.Level)
+			}
+		}
+	}
+	return out
+}
+
+// amount：解析数值（可含范围第二段）；中文数字经 units.ParseAmount 解析。
+func amount(s string, a0, a1, b0, b1 int) (float64, float64, bool) {
+	a, ok := units.ParseAmount(s[a0:a1])
+	if !ok {
+		return 0, 0, false
+	}
+	lo, hi := a.Min, a.Max
+	if b0 >= 0 {
+		b, ok := units.ParseAmount(s[b0:b1])
+		if !ok || b.Max < lo {
+			return 0, 0, false
+		}
+		hi = b.Max
+	}
+	return lo, hi, true
+}
+
+// plausibleDegree：裸写「度」是否表示温度；排除角度（「转 90 度」「45 度角」「斜切」）与无温度语境的用法。
+func plausibleDegree(s string, start, end int) bool {
+	after := s[end:]
+	if strings.HasPrefix(after, "角") || strings.HasPrefix(after, "斜") {
+		return false
+	}
+	before := lastRunes(s[:start], 3)
+	if strings.ContainsAny(before, "转斜角旋") {
+		return false
+	}
+	return degreeCue.MatchString(s)
+}
+
+// find：按名称查找火候阶梯。
+func find(levels []level, name string) level {
+	for _, l := range levels {
+		if l.name == name {
+			return l
+		}
+	}
+	return level{}
+}
+
+// byTemp：按温度区间中点选择命名火候；超出阶梯时取两端。
+func byTemp(levels []level, lo, hi float64) string {
+	mid := (lo + hi) / 2
+	for _, l := range levels {
+		if mid < l.max {
+			return l.name
+		}
+	}
+	return levels[len(levels)-1].name
+}
+
+// fahrenheit：华氏度换算摄氏度，保留一位小数。
+func fahrenheit(f float64) float64 {
+	return math.Round((f-32)*5/9*10) / 10
+}
+
+// lastRunes：s 末尾的 n 个字符。
+func lastRunes(s string, n int) string {
+	rs := []rune(s)
+	if len(rs) > n {
+		rs = rs[len(rs)-n:]
+	}
+	return string(rs)
+}
+
+// firstRunes：s 开头的 n 个字符。
+func firstRunes(s string, n int) string {
+	rs := []rune(s)
+	if len(rs) > n {
+		rs = rs[:n]
+	}
+	return string(rs)
+}
+
+// cueText：从原文片段中取括号内的判断依据。
+func cueText(rs []rune) string {
+	s := string(rs)
+	open := strings.LastIndexAny(s, "(（")
+	if open < 0 {
+		return ""
+	}
+	s = s[open:]
+	_, size := utf8.DecodeRuneInString(s)
+	return strings.TrimSpace(strings.TrimRight(s[size:], ")）"))
+}
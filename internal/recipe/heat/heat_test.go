@@ -0,0 +1,55 @@
+// 文件功能：火候识别的单元测试；验证火力、油温、明确温度、烤箱上下火与排除规则。
+package heat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	cases := map[string]string{
+		"锅中倒油，烧至 7 成热（竹筷子起泡）后下入鸡块":  "oil/旺油/200-220/竹筷子起泡",
+		"先大火烧开，再转中小火炖 40 分钟":        "flame/大火/230-260 flame/中小火/150-170",
+		"烤箱上下火 170 度预热 10 分钟":       "oven/中温/170-170/上下火",
+		"上火 200℃，下火 180℃ 烤 15 分钟":   "oven/中高温/200-200/上火 oven/中温/180-180/下火",
+		"Preheat the oven to 350°F": "oven/中温/176.7-176.7",
+		"油温升至一百八十度时下锅":              "oil/热油/180-180",
+		"加入 40~50℃ 的温水":             "water/温水/40-50",
+		"低温慢煮，保持 67±1°C":            "temp/热水/66-68",
+		"油溫六七成熱":                    "oil/旺油/170-220",
+		"用小火锅煮火腿，牛排煎至七成熟":           "",
+		"将黄瓜转 90 度斜切":               "",
+	}
+	for in, want := range cases {
+		var got []string
+		for _, h := range Extract(in) {
+			s := fmt.Sprintf("%s/%s/%v-%v", h.Kind, h.Level, h.Min, h.Max)
+			if h.Element != "" {
+				s += "/" + h.Element
+			}
+			if h.Cue != "" {
+				s += "/" + h.Cue
+			}
+			got = append(got, s)
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("Extract(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestOven(t *testing.T) {
+	steps := [][]Heat{
+		Extract("烤箱 200℃ 预热"),
+		Extract("中火煎至两面金黄"),
+		Extract("放入烤箱，200℃ 烤 20 分钟，再转 180℃ 烤 10 分钟"),
+	}
+	var got []string
+	for _, h := range Oven(steps) {
+		got = append(got, h.Text)
+	}
+	if strings.Join(got, ",") != "200℃,180℃" {
+		t.Fatalf("oven: %q", got)
+	}
+}
@@ -0,0 +1,4 @@
+// 文件功能：索引器包说明。
+// 包功能：indexer 包，将语料变更（corpus.Delta）转换为检索文档，分批向量化后写入向量存储（vector.Store），
+// 并汇报进度与写入失败；全量索引与监听模式下的增量索引共用同一流程。
+package indexer
@@ -0,0 +1,177 @@
+// 文件功能：向量索引 Sink；实现 corpus.Sink：按顺序将连续的同类变更合批，upsert 批次向量化后写入存储，delete 批次按 ID 删除。
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"cook/internal/recipe/corpus"
+	"cook/internal/recipe/embedding"
+	"cook/internal/recipe/vector"
+)
+
+// DefaultBatchSize：单批向量化与写入的文档数。
+const DefaultBatchSize = 64
+
+// Progress：索引进度；每批写入后回调一次。
+//   - Done：本次 Apply 已处理的变更数；
+//   - Total：本次 Apply 的变更总数；
+//   - Upserted/Deleted/Failed：累计写入、删除与失败的文档数（跨多次 Apply）。
+type Progress struct {
+	Done     int
+	Total    int
+	Upserted int
+	Deleted  int
+	Failed   int
+}
+
+// Options：索引器参数。
+//   - BatchSize：单批文档数；≤0 时使用 DefaultBatchSize；
+//   - OnProgress：进度回调；为 nil 时不汇报。
+type Options struct {
+	BatchSize  int
+	OnProgress func(Progress)
+}
+
+// Sink：向量索引 Sink；并发安全（Apply 串行执行）。
+type Sink struct {
+	emb   embedding.Embedder
+	store vector.Store
+	opts  Options
+
+	mu       sync.Mutex
+	ensured  bool
+	stats    Progress
+	failures []vector.Failure
+}
+
+var _ corpus.Sink = (*Sink)(nil)
+
+// New：构造写入 store 的索引器；首次写入时按向量维度调用 store.Ensure。
+func New(emb embedding.Embedder, store vector.Store, opts Options) *Sink {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	return &Sink{emb: emb, store: store, opts: opts}
+}
+
+// Apply：按顺序应用变更。
+// 功能说明：连续的同类变更合为一批（不跨越类型边界，保证同一 ID 的先后顺序）；upsert 批次将分块转换为文档、
+// 向量化正文后写入，delete 批次按 ID 删除；全部批次完成后 Flush 存储。单个文档写入失败（*vector.BulkError）只记录，不中断后续批次。
+// 返回值说明：
+//   - error：向量化失败、存储准备失败或请求失败时返回错误；已写入的批次不回滚。
+func (s *Sink) Apply(ctx context.Context, deltas []corpus.Delta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for start := 0; start < len(deltas); {
+		end := start + 1
+		for end < len(deltas) && end-start < s.opts.BatchSize && deltas[end].Op == deltas[start].Op {
+			end++
+		}
+		var err error
+		switch op := deltas[start].Op; op {
+		case corpus.OpUpsert:
+			err = s.upsert(ctx, deltas[start:end])
+		case corpus.OpDelete:
+			err = s.delete(ctx, deltas[start:end])
+		default:
+			err = fmt.Errorf("index: unknown op %q for %s", op, deltas[start].ID)
+		}
+		if err != nil {
+			return err
+		}
+		start = end
+		if s.opts.OnProgress != nil {
+			p := s.stats
+			p.Done, p.Total = end, len(deltas)
+			s.opts.OnProgress(p)
+		}
+	}
+	if len(deltas) == 0 || !s.ensured {
+		return nil
+	}
+	if err := s.store.Flush(ctx); err != nil {
+		return fmt.Errorf("index: flush: %w", err)
+	}
+	return nil
+}
+
+// upsert：向量化并写入一批分块。
+func (s *Sink) upsert(ctx context.Context, batch []corpus.Delta) error {
+	docs := make([]*vector.Document, 0, len(batch))
+	texts := make([]string, 0, len(batch))
+	for _, d := range batch {
+		if d.Chunk == nil {
+			return fmt.Errorf("index: upsert %s has no chunk", d.ID)
+		}
+		doc := vector.FromChunk(*d.Chunk)
+		docs = append(docs, doc)
+		texts = append(texts, doc.Content)
+	}
+	vecs, err := s.emb.EmbedStrings(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("index: embed: %w", err)
+	}
+	if len(vecs) != len(docs) {
+		return fmt.Errorf("index: embedder returned %d vectors for %d documents", len(vecs), len(docs))
+	}
+	if !s.ensured {
+		if err := s.store.Ensure(ctx, len(vecs[0])); err != nil {
+			return fmt.Errorf("index: %w", err)
+		}
+		s.ensured = true
+	}
+	for i, d := range docs {
+		d.WithDenseVector(vecs[i])
+	}
+	failed, err := s.record(s.store.Upsert(ctx, docs))
+	if err != nil {
+		return err
+	}
+	s.stats.Upserted += len(docs) - failed
+	return nil
+}
+
+// delete：删除一批文档。
+func (s *Sink) delete(ctx context.Context, batch []corpus.Delta) error {
+	ids := make([]string, 0, len(batch))
+	for _, d := range batch {
+		ids = append(ids, d.ID)
+	}
+	failed, err := s.record(s.store.Delete(ctx, ids))
+	if err != nil {
+		return err
+	}
+	s.stats.Deleted += len(ids) - failed
+	return nil
+}
+
+// record：记录单条失败并返回失败数；其他错误加前缀后返回。
+func (s *Sink) record(err error) (int, error) {
+	var be *vector.BulkError
+	if errors.As(err, &be) {
+		s.failures = append(s.failures, be.Failures...)
+		s.stats.Failed += len(be.Failures)
+		return len(be.Failures), nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("index: %w", err)
+	}
+	return 0, nil
+}
+
+// Stats：累计写入、删除与失败的文档数。
+func (s *Sink) Stats() Progress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Failures：累计的单条写入失败（按发生顺序）。
+func (s *Sink) Failures() []vector.Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]vector.Failure(nil), s.failures...)
+}
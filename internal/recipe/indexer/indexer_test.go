@@ -0,0 +1,101 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"cook/internal/recipe/corpus"
+	"cook/internal/recipe/embedding"
+	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/vector"
+)
+
+// memStore：记录调用的内存存储；fail 中的 ID 写入时报告单条失败。
+type memStore struct {
+	dims  int
+	docs  map[string]*vector.Document
+	calls []string
+	fail  map[string]bool
+}
+
+func (m *memStore) Ensure(_ context.Context, dims int) error {
+	m.dims = dims
+	m.calls = append(m.calls, "ensure")
+	return nil
+}
+
+func (m *memStore) Upsert(_ context.Context, docs []*vector.Document) error {
+	m.calls = append(m.calls, fmt.Sprintf("upsert %d", len(docs)))
+	var be vector.BulkError
+	for _, d := range docs {
+		if m.fail[d.ID] {
+			be.Failures = append(be.Failures, vector.Failure{ID: d.ID, Status: 400, Reason: "bad"})
+			continue
+		}
+		m.docs[d.ID] = d
+	}
+	if len(be.Failures) > 0 {
+		return &be
+	}
+	return nil
+}
+
+func (m *memStore) Delete(_ context.Context, ids []string) error {
+	m.calls = append(m.calls, fmt.Sprintf("delete %d", len(ids)))
+	for _, id := range ids {
+		delete(m.docs, id)
+	}
+	return nil
+}
+
+func (m *memStore) Prune(context.Context, []string) (int, error) { return 0, nil }
+
+func (m *memStore) Flush(context.Context) error {
+	m.calls = append(m.calls, "flush")
+	return nil
+}
+
+func upsert(id, text string) corpus.Delta {
+	c := types.Chunk{ID: id, DocID: "d", Header: "做法", Text: text, Name: "番茄炒蛋", Category: "meat_dish"}
+	return corpus.Delta{Op: corpus.OpUpsert, ID: id, Chunk: &c}
+}
+
+func TestApply(t *testing.T) {
+	st := &memStore{docs: map[string]*vector.Document{}, fail: map[string]bool{"c": true}}
+	var progress []Progress
+	s := New(embedding.NewHash(16), st, Options{BatchSize: 2, OnProgress: func(p Progress) { progress = append(progress, p) }})
+	deltas := []corpus.Delta{
+		upsert("a", "番茄切块"), upsert("b", "鸡蛋打散"), upsert("c", "热锅下油"),
+		{Op: corpus.OpDelete, ID: "a"},
+		upsert("a", "番茄去皮切块"),
+	}
+	if err := s.Apply(context.Background(), deltas); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"ensure", "upsert 2", "upsert 1", "delete 1", "upsert 1", "flush"}
+	if fmt.Sprint(st.calls) != fmt.Sprint(want) {
+		t.Fatalf("calls = %v, want %v", st.calls, want)
+	}
+	if st.dims != 16 {
+		t.Fatalf("dims = %d", st.dims)
+	}
+	d := st.docs["a"]
+	if d == nil || d.Content != "做法\n\n番茄去皮切块" || len(d.DenseVector()) != 16 || d.MetaData[vector.MetaName] != "番茄炒蛋" {
+		t.Fatalf("doc a = %+v", d)
+	}
+	got := s.Stats()
+	if got.Upserted != 3 || got.Deleted != 1 || got.Failed != 1 {
+		t.Fatalf("stats = %+v", got)
+	}
+	if f := s.Failures(); len(f) != 1 || f[0].ID != "c" {
+		t.Fatalf("failures = %+v", f)
+	}
+	if len(progress) != 4 || progress[3].Done != 5 || progress[3].Total != 5 {
+		t.Fatalf("progress = %+v", progress)
+	}
+
+	if err := s.Apply(context.Background(), []corpus.Delta{{Op: corpus.OpUpsert, ID: "x"}}); err == nil {
+		t.Fatal("upsert without chunk should fail")
+	}
+}
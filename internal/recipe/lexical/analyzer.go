@@ -0,0 +1,140 @@
+// 文件功能：中文分析器；检索键折叠后，汉字片段切分为二元组并补充词典中的长词，字母数字片段按词切分。
+package lexical
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/textnorm"
+)
+
+// 词典词长度范围（按 rune 计）；两字词已由二元组覆盖，只收录更长的词。
+const (
+	minWordLen = 3
+	maxWordLen = 8
+)
+
+// Analyzer：分析器；构建后只读，可并发使用。
+type Analyzer struct {
+	dict map[string]bool
+}
+
+// NewAnalyzer：以 words 为词典构造分析器；词条经检索键折叠，长度不在 [minWordLen, maxWordLen] 内或含非汉字的词条忽略。
+func NewAnalyzer(words []string) *Analyzer {
+	a := &Analyzer{dict: make(map[string]bool, len(words))}
+	for _, w := range words {
+		w = textnorm.Fold(strings.TrimSpace(w))
+		n := utf8.RuneCountInString(w)
+		if n < minWordLen || n > maxWordLen || !allHan(w) {
+			continue
+		}
+		a.dict[w] = true
+	}
+	return a
+}
+
+// Len：词典词条数。
+func (a *Analyzer) Len() int { return len(a.dict) }
+
+// Tokens：切分文本。
+// 功能说明：
+//  1. 文本按检索键折叠（繁简、全半角、大小写不敏感）；
+//  2. 连续汉字输出相邻二元组（单字片段输出单字），并输出片段中出现的全部词典词（允许重叠），
+//     使「柱候牛腩」「二荆条」等整词命中获得额外得分；
+//  3. 连续字母或数字输出为一个词。
+//
+// 返回值说明：
+//   - []string：按出现顺序的词元，可能重复（用于词频统计）。
+func (a *Analyzer) Tokens(s string) []string {
+	s = textnorm.Fold(s)
+	var out []string
+	var run []rune
+	var word strings.Builder
+	flushHan := func() {
+		out = a.han(run, out)
+		run = run[:0]
+	}
+	flushWord := func() {
+		if word.Len() > 0 {
+			out = append(out, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word.WriteRune(unicode.ToLower(r))
+		default:
+			flushHan()
+			flushWord()
+		}
+	}
+	flushHan()
+	flushWord()
+	return out
+}
+
+// han：切分一个汉字片段。
+func (a *Analyzer) han(run []rune, out []string) []string {
+	switch len(run) {
+	case 0:
+		return out
+	case 1:
+		return append(out, string(run))
+	}
+	for i := 0; i+1 < len(run); i++ {
+		out = append(out, string(run[i:i+2]))
+	}
+	if len(a.dict) == 0 || len(run) < minWordLen {
+		return out
+	}
+	for i := 0; i+minWordLen <= len(run); i++ {
+		for n := minWordLen; n <= maxWordLen && i+n <= len(run); n++ {
+			if w := string(run[i : i+n]); a.dict[w] {
+				out = append(out, w)
+			}
+		}
+	}
+	return out
+}
+
+// allHan：是否全部为汉字。
+func allHan(s string) bool {
+	for _, r := range s {
+		if !unicode.Is(unicode.Han, r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Dictionary：由菜谱构建词典。
+// 功能说明：收录菜名与原料名（parser.IngredientNames：去掉括注、收录别称、拆分并列项），
+// 另收录「计算」章节用量识别出的原料名。长度与字符过滤由 NewAnalyzer 完成。
+func Dictionary(recipes []*parser.Recipe) []string {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(w string) {
+		w = strings.TrimSpace(w)
+		if w != "" && !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	for _, r := range recipes {
+		add(r.Title)
+		for _, ing := range parser.IngredientNames(r.Ingredients) {
+			add(ing)
+		}
+		for _, q := range r.Quantities {
+			add(q.Ingredient)
+		}
+	}
+	return out
+}
@@ -0,0 +1,4 @@
+// 文件功能：词法检索包说明。
+// 包功能：lexical 包，面向中文菜谱的 BM25 全文检索：分析器输出汉字二元组与词典词（菜名、原料），
+// 对菜名、标题与正文分字段加权评分；可单独使用，也可作为混合检索的一路召回。
+package lexical
@@ -0,0 +1,249 @@
+// 文件功能：BM25 倒排索引；菜名、标题、正文分字段统计词频与长度，按 BM25F 合并字段得分。
+package lexical
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"cook/internal/recipe/expand"
+	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/retrieval"
+	"cook/internal/recipe/vector"
+)
+
+// 字段。
+const (
+	fieldName = iota
+	fieldHeader
+	fieldText
+	numFields
+)
+
+// Options：评分参数。
+//   - K1：词频饱和参数；≤0 时为 1.2；
+//   - B：长度归一化强度（0～1）；≤0 或 >1 时为 0.75；
+//   - NameBoost/HeaderBoost/TextBoost：菜名、标题、正文字段权重；≤0 时依次为 3、1.5、1。
+type Options struct {
+	K1          float64
+	B           float64
+	NameBoost   float64
+	HeaderBoost float64
+	TextBoost   float64
+}
+
+// DefaultOptions：默认评分参数。
+func DefaultOptions() Options {
+	return Options{K1: 1.2, B: 0.75, NameBoost: 3, HeaderBoost: 1.5, TextBoost: 1}
+}
+
+// withDefaults：填充未设置的参数。
+func (o Options) withDefaults() Options {
+	d := DefaultOptions()
+	if o.K1 <= 0 {
+		o.K1 = d.K1
+	}
+	if o.B <= 0 || o.B > 1 {
+		o.B = d.B
+	}
+	if o.NameBoost <= 0 {
+		o.NameBoost = d.NameBoost
+	}
+	if o.HeaderBoost <= 0 {
+		o.HeaderBoost = d.HeaderBoost
+	}
+	if o.TextBoost <= 0 {
+		o.TextBoost = d.TextBoost
+	}
+	return o
+}
+
+// posting：词元在单个文档中的分字段词频。
+type posting struct {
+	doc int32
+	tf  [numFields]uint16
+}
+
+// Index：BM25 索引；构建后只读，可并发查询。
+type Index struct {
+	a     *Analyzer
+	opts  Options
+	boost [numFields]float64
+	docs  []*vector.Document
+	lens  [][numFields]int32
+	avg   [numFields]float64
+	terms map[string][]posting
+}
+
+var (
+	_ retrieval.Retriever         = (*Index)(nil)
+	_ retrieval.ExpandedRetriever = (*Index)(nil)
+)
+
+// Build：由分块构建索引。
+// 参数说明：
+//   - chunks：分块；文档内容与元数据按 vector.FromChunk 转换，检索结果与稠密检索格式一致；
+//   - a：分析器；为 nil 时不使用词典（仅二元组）；
+//   - opts：评分参数；零值使用默认值。
+func Build(chunks []types.Chunk, a *Analyzer, opts Options) *Index {
+	if a == nil {
+		a = NewAnalyzer(nil)
+	}
+	opts = opts.withDefaults()
+	ix := &Index{
+		a:     a,
+		opts:  opts,
+		boost: [numFields]float64{fieldName: opts.NameBoost, fieldHeader: opts.HeaderBoost, fieldText: opts.TextBoost},
+		docs:  make([]*vector.Document, 0, len(chunks)),
+		lens:  make([][numFields]int32, 0, len(chunks)),
+		terms: make(map[string][]posting),
+	}
+	var total [numFields]int64
+	for i, c := range chunks {
+		fields := [numFields]string{fieldName: c.Name, fieldHeader: c.Header, fieldText: c.Text}
+		tf := make(map[string]*[numFields]uint16)
+		var lens [numFields]int32
+		for f, text := range fields {
+			for _, t := range a.Tokens(text) {
+				p := tf[t]
+				if p == nil {
+					p = new([numFields]uint16)
+					tf[t] = p
+				}
+				if p[f] < math.MaxUint16 {
+					p[f]++
+				}
+				lens[f]++
+			}
+			total[f] += int64(lens[f])
+		}
+		for t, p := range tf {
+			ix.terms[t] = append(ix.terms[t], posting{doc: int32(i), tf: *p})
+		}
+		ix.docs = append(ix.docs, vector.FromChunk(c))
+		ix.lens = append(ix.lens, lens)
+	}
+	for f := range total {
+		if len(chunks) > 0 {
+			ix.avg[f] = float64(total[f]) / float64(len(chunks))
+		}
+		if ix.avg[f] == 0 {
+			ix.avg[f] = 1
+		}
+	}
+	return ix
+}
+
+// Len：索引的文档数。
+func (ix *Index) Len() int { return len(ix.docs) }
+
+// Analyzer：索引使用的分析器。
+func (ix *Index) Analyzer() *Analyzer { return ix.a }
+
+// Search：BM25F 检索。
+// 算法说明：查询切分后去重；每个词元的各字段词频按字段长度归一化、乘以字段权重后相加得到合并词频 tf，
+// 得分为 Σ idf × tf / (k1 + tf)，idf = ln(1 + (N − df + 0.5) / (df + 0.5))。过滤条件在评分时生效。
+// 返回值说明：
+//   - []*vector.Document：按得分降序的文档副本（得分写入 Score）；无命中时为空。
+func (ix *Index) Search(q string, opts vector.SearchOptions) []*vector.Document {
+	return ix.SearchExpanded(q, nil, opts)
+}
+
+// SearchExpanded：带扩展词的 BM25F 检索。
+// 功能说明：扩展词切分后的词元按扩展词权重计分（原查询词元权重为 1；同一词元取最高权重），其余同 Search。
+func (ix *Index) SearchExpanded(q string, terms []expand.Term, opts vector.SearchOptions) []*vector.Document {
+	if opts.TopK <= 0 || len(ix.docs) == 0 {
+		return nil
+	}
+	var order []string
+	weight := make(map[string]float64)
+	addTokens := func(text string, w float64) {
+		for _, t := range ix.a.Tokens(text) {
+			if old, ok := weight[t]; !ok {
+				weight[t] = w
+				order = append(order, t)
+			} else if w > old {
+				weight[t] = w
+			}
+		}
+	}
+	addTokens(q, 1)
+	for _, t := range terms {
+		if t.Weight > 0 {
+			addTokens(t.Term, min(t.Weight, 1))
+		}
+	}
+	n := float64(len(ix.docs))
+	scores := make(map[int32]float64)
+	allowed := make(map[int32]bool)
+	for _, t := range order {
+		ps := ix.terms[t]
+		if len(ps) == 0 {
+			continue
+		}
+		idf := weight[t] * math.Log(1+(n-float64(len(ps))+0.5)/(float64(len(ps))+0.5))
+		for _, p := range ps {
+			ok, checked := allowed[p.doc]
+			if !checked {
+				ok = opts.Filter.Empty() || opts.Filter.Match(ix.docs[p.doc].MetaData)
+				allowed[p.doc] = ok
+			}
+			if !ok {
+				continue
+			}
+			var tf float64
+			for f := 0; f < numFields; f++ {
+				if p.tf[f] == 0 {
+					continue
+				}
+				norm := 1 - ix.opts.B + ix.opts.B*float64(ix.lens[p.doc][f])/ix.avg[f]
+				tf += ix.boost[f] * float64(p.tf[f]) / norm
+			}
+			scores[p.doc] += idf * tf / (ix.opts.K1 + tf)
+		}
+	}
+	hits := make([]int32, 0, len(scores))
+	for d := range scores {
+		hits = append(hits, d)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if scores[hits[i]] != scores[hits[j]] {
+			return scores[hits[i]] > scores[hits[j]]
+		}
+		return hits[i] < hits[j]
+	})
+	if len(hits) > opts.TopK {
+		hits = hits[:opts.TopK]
+	}
+	out := make([]*vector.Document, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, clone(ix.docs[h]).WithScore(scores[h]))
+	}
+	return out
+}
+
+// Retrieve：实现 retrieval.Retriever；空查询返回空结果。
+func (ix *Index) Retrieve(_ context.Context, query string, opts vector.SearchOptions) ([]*vector.Document, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	return ix.Search(query, opts), nil
+}
+
+// RetrieveExpanded：实现 retrieval.ExpandedRetriever；扩展词按权重参与计分。
+func (ix *Index) RetrieveExpanded(_ context.Context, query string, terms []expand.Term, opts vector.SearchOptions) ([]*vector.Document, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	return ix.SearchExpanded(query, terms, opts), nil
+}
+
+// clone：复制文档与顶层元数据，使调用方写入得分不影响索引。
+func clone(d *vector.Document) *vector.Document {
+	meta := make(map[string]any, len(d.MetaData)+1)
+	for k, v := range d.MetaData {
+		meta[k] = v
+	}
+	return &vector.Document{ID: d.ID, Content: d.Content, MetaData: meta}
+}
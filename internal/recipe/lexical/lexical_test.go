@@ -0,0 +1,133 @@
+package lexical
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"cook/internal/recipe/expand"
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/vector"
+)
+
+func TestTokens(t *testing.T) {
+	a := NewAnalyzer([]string{"柱候牛腩", "二荆条", "牛腩", "x光", "这是一个非常长的词典词条"})
+	if a.Len() != 2 {
+		t.Fatalf("dict len = %d, want 2", a.Len())
+	}
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"柱候牛腩", []string{"柱候", "候牛", "牛腩", "柱候牛腩"}},
+		{"二荆條 200g", []string{"二荆", "荆条", "二荆条", "200g"}},
+		{"盐、糖", []string{"盐", "糖"}},
+		{"Air Fryer 空气炸锅", []string{"air", "fryer", "空气", "气炸", "炸锅"}},
+		{"", nil},
+	}
+	for _, c := range cases {
+		if got := a.Tokens(c.in); fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("Tokens(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	recipes := []*parser.Recipe{{
+		Title:       "咖喱炒蟹",
+		Ingredients: []string{"青蟹（别称：肉蟹）", "咖喱块（推介乐惠蟹黄咖喱）", "葱、姜、蒜", "二荆条/小米辣"},
+	}}
+	got := Dictionary(recipes)
+	for _, w := range []string{"咖喱炒蟹", "青蟹", "肉蟹", "咖喱块", "葱", "二荆条", "小米辣"} {
+		if !slices.Contains(got, w) {
+			t.Errorf("dictionary %v lacks %q", got, w)
+		}
+	}
+	if slices.Contains(got, "推介乐惠蟹黄咖喱") {
+		t.Errorf("dictionary kept a parenthetical note: %v", got)
+	}
+}
+
+func testIndex() *Index {
+	chunks := []types.Chunk{
+		{ID: "beef-1", Name: "柱候牛腩", Header: "## 必备原料和工具", Text: "牛腩、柱候酱、姜、蒜", Category: "meat_dish"},
+		{ID: "beef-2", Name: "萝卜牛腩", Header: "## 操作", Text: "牛腩切块焯水，加入萝卜炖煮一小时", Category: "meat_dish"},
+		{ID: "chicken", Name: "宫保鸡丁", Header: "## 必备原料和工具", Text: "鸡胸肉、花生、二荆条、花椒", Category: "meat_dish"},
+		{ID: "soup", Name: "番茄牛腩汤", Header: "## 操作", Text: "番茄去皮，与牛腩同煮", Category: "soup"},
+	}
+	return Build(chunks, NewAnalyzer([]string{"柱候牛腩", "二荆条"}), Options{})
+}
+
+func ids(docs []*vector.Document) []string {
+	out := make([]string, len(docs))
+	for i, d := range docs {
+		out[i] = d.ID
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	ix := testIndex()
+	got := ix.Search("柱候牛腩怎么做", vector.SearchOptions{TopK: 3})
+	if len(got) == 0 || got[0].ID != "beef-1" {
+		t.Fatalf("柱候牛腩 = %v", ids(got))
+	}
+	if got[0].Score() <= got[1].Score() {
+		t.Fatalf("scores not descending: %v, %v", got[0].Score(), got[1].Score())
+	}
+	if got := ix.Search("二荆条", vector.SearchOptions{TopK: 3}); fmt.Sprint(ids(got)) != "[chicken]" {
+		t.Fatalf("二荆条 = %v", ids(got))
+	}
+	if got := ix.Search("牛腩", vector.SearchOptions{TopK: 4}); len(got) != 3 {
+		t.Fatalf("牛腩 = %v", ids(got))
+	}
+	got = ix.Search("牛腩", vector.SearchOptions{TopK: 4, Filter: vector.Filter{Terms: map[string][]string{vector.MetaCategory: {"soup"}}}})
+	if fmt.Sprint(ids(got)) != "[soup]" {
+		t.Fatalf("filtered = %v", ids(got))
+	}
+	if got := ix.Search("巧克力", vector.SearchOptions{TopK: 3}); len(got) != 0 {
+		t.Fatalf("no-match query = %v", ids(got))
+	}
+	hits := ix.Search("牛腩", vector.SearchOptions{TopK: 1})
+	hits[0].WithScore(-1)
+	if again := ix.Search("牛腩", vector.SearchOptions{TopK: 1}); again[0].Score() == -1 {
+		t.Fatal("search results alias index documents")
+	}
+	if docs, err := ix.Retrieve(context.Background(), " ", vector.SearchOptions{TopK: 3}); err != nil || docs != nil {
+		t.Fatalf("blank retrieve = %v, %v", docs, err)
+	}
+}
+
+func TestBoosts(t *testing.T) {
+	chunks := []types.Chunk{
+		{ID: "name", Name: "红烧肉", Text: "五花肉切块"},
+		{ID: "text", Name: "家常菜", Text: "红烧肉是一道家常菜"},
+	}
+	a := NewAnalyzer(nil)
+	if got := Build(chunks, a, Options{}).Search("红烧肉", vector.SearchOptions{TopK: 2}); got[0].ID != "name" {
+		t.Fatalf("default boosts = %v", ids(got))
+	}
+	if got := Build(chunks, a, Options{NameBoost: 0.1, TextBoost: 5}).Search("红烧肉", vector.SearchOptions{TopK: 2}); got[0].ID != "text" {
+		t.Fatalf("text boost = %v", ids(got))
+	}
+}
+
+func TestSearchExpanded(t *testing.T) {
+	chunks := []types.Chunk{
+		{ID: "yangyu", Name: "酸辣洋芋丝", Text: "洋芋切丝，泡水去淀粉"},
+		{ID: "tudou", Name: "酸辣土豆丝", Text: "土豆切丝，泡水去淀粉"},
+	}
+	ix := Build(chunks, NewAnalyzer(nil), Options{})
+	if got := ix.Search("土豆", vector.SearchOptions{TopK: 2}); fmt.Sprint(ids(got)) != "[tudou]" {
+		t.Fatalf("plain = %v", ids(got))
+	}
+	got := ix.SearchExpanded("土豆", []expand.Term{{Term: "洋芋", Weight: 0.8}}, vector.SearchOptions{TopK: 2})
+	if fmt.Sprint(ids(got)) != "[tudou yangyu]" {
+		t.Fatalf("expanded = %v", ids(got))
+	}
+	if r := got[1].Score() / got[0].Score(); r < 0.79 || r > 0.81 {
+		t.Fatalf("synonym weight 0.8 gave score ratio %.3f", r)
+	}
+}
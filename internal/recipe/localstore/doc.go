@@ -0,0 +1,4 @@
+// 文件功能：本地向量存储包说明。
+// 包功能：localstore 包，纯 Go 实现的单文件向量存储：余弦相似度、HNSW 近似检索、元数据过滤与原子快照，
+// 与 Elasticsearch 后端实现同一组 vector.Store / vector.Searcher 接口，使索引与问答流程可完全离线运行。
+package localstore
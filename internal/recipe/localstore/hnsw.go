@@ -0,0 +1,233 @@
+// 文件功能：HNSW 近似最近邻图；向量预先归一化，距离为 1 − 点积（即余弦距离）。
+package localstore
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+)
+
+// HNSW 参数。
+const (
+	defaultM              = 16  // 每层每个节点的邻居上限（第 0 层为 2M）
+	defaultEfConstruction = 200 // 建图时的候选集大小
+	defaultEfSearch       = 64  // 检索时的最小候选集大小
+)
+
+// graph：分层可导航小世界图。
+// 功能说明：节点按插入顺序编号，与 Store 的文档槽位一一对应；删除的节点仍参与导航但不出现在结果中，
+// 墓碑比例过高时由 Store 重建整张图。
+type graph struct {
+	m     int
+	efC   int
+	vecs  [][]float32 // 节点向量（已归一化；由 Store 持有，图只读）
+	level []int       // 节点所在最高层
+	links [][][]int32 // 节点 -> 层 -> 邻居
+	entry int32       // 入口节点；-1 表示空图
+	maxLv int
+	rng   *rand.Rand
+	ml    float64
+}
+
+// newGraph：构造空图；随机层级使用固定种子，相同输入得到相同的图。
+func newGraph() *graph {
+	return &graph{
+		m:     defaultM,
+		efC:   defaultEfConstruction,
+		entry: -1,
+		rng:   rand.New(rand.NewSource(1)),
+		ml:    1 / math.Log(defaultM),
+	}
+}
+
+// dist：余弦距离。
+func dist(a, b []float32) float32 {
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return 1 - dot
+}
+
+// candidate：候选节点与其到查询向量的距离。
+type candidate struct {
+	id int32
+	d  float32
+}
+
+// minHeap / maxHeap：按距离排序的候选堆。
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].d < h[j].d }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+type maxHeap []candidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].d > h[j].d }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// insert：插入节点 id（其向量已在 vecs[id]）。
+// 算法说明：随机选取层级；自顶层贪心下降到节点层级之上，再在每层以 efConstruction 搜索候选并按启发式选邻居，
+// 建立双向连接；邻居数超限时对被连接方重新裁剪。
+func (g *graph) insert(id int32) {
+	lv := int(-math.Log(1-g.rng.Float64()) * g.ml)
+	for int(id) >= len(g.level) {
+		g.level = append(g.level, 0)
+		g.links = append(g.links, nil)
+	}
+	g.level[id] = lv
+	g.links[id] = make([][]int32, lv+1)
+	if g.entry < 0 {
+		g.entry, g.maxLv = id, lv
+		return
+	}
+	q := g.vecs[id]
+	ep := candidate{id: g.entry, d: dist(q, g.vecs[g.entry])}
+	for l := g.maxLv; l > lv; l-- {
+		ep = g.greedy(q, ep, l)
+	}
+	eps := []candidate{ep}
+	for l := min(lv, g.maxLv); l >= 0; l-- {
+		found := g.searchLayer(q, eps, g.efC, l)
+		neigh := g.selectNeighbors(found, g.m)
+		g.links[id][l] = neigh
+		for _, n := range neigh {
+			g.connect(n, id, l)
+		}
+		eps = found
+	}
+	if lv > g.maxLv {
+		g.entry, g.maxLv = id, lv
+	}
+}
+
+// connect：将 to 加入 from 在第 l 层的邻居；超限时按启发式重新选择。
+func (g *graph) connect(from, to int32, l int) {
+	limit := g.m
+	if l == 0 {
+		limit = 2 * g.m
+	}
+	ls := append(g.links[from][l], to)
+	if len(ls) > limit {
+		cands := make([]candidate, len(ls))
+		for i, n := range ls {
+			cands[i] = candidate{id: n, d: dist(g.vecs[from], g.vecs[n])}
+		}
+		ls = g.selectNeighbors(cands, limit)
+	}
+	g.links[from][l] = ls
+}
+
+// greedy：在第 l 层从 ep 出发贪心移动到最近节点。
+func (g *graph) greedy(q []float32, ep candidate, l int) candidate {
+	for changed := true; changed; {
+		changed = false
+		for _, n := range g.links[ep.id][l] {
+			if d := dist(q, g.vecs[n]); d < ep.d {
+				ep, changed = candidate{id: n, d: d}, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer：在第 l 层做宽度为 ef 的最佳优先搜索，返回按距离升序的候选。
+func (g *graph) searchLayer(q []float32, eps []candidate, ef, l int) []candidate {
+	visited := make(map[int32]bool, ef*4)
+	cand := &minHeap{}
+	best := &maxHeap{}
+	for _, e := range eps {
+		visited[e.id] = true
+		heap.Push(cand, e)
+		heap.Push(best, e)
+		if best.Len() > ef {
+			heap.Pop(best)
+		}
+	}
+	for cand.Len() > 0 {
+		c := heap.Pop(cand).(candidate)
+		if best.Len() >= ef && c.d > (*best)[0].d {
+			break
+		}
+		for _, n := range g.links[c.id][l] {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+			d := dist(q, g.vecs[n])
+			if best.Len() < ef || d < (*best)[0].d {
+				heap.Push(cand, candidate{id: n, d: d})
+				heap.Push(best, candidate{id: n, d: d})
+				if best.Len() > ef {
+					heap.Pop(best)
+				}
+			}
+		}
+	}
+	out := make([]candidate, best.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(best).(candidate)
+	}
+	return out
+}
+
+// selectNeighbors：启发式选邻居。
+// 算法说明：按距离升序遍历候选，仅保留比已选邻居都更接近基准点的候选（保持图的多方向连通）；
+// 不足 m 个时用被跳过的最近候选补足。
+func (g *graph) selectNeighbors(cands []candidate, m int) []int32 {
+	sorted := append([]candidate(nil), cands...)
+	h := minHeap(sorted)
+	heap.Init(&h)
+	out := make([]int32, 0, m)
+	var skipped []int32
+	for h.Len() > 0 && len(out) < m {
+		c := heap.Pop(&h).(candidate)
+		keep := true
+		for _, s := range out {
+			if dist(g.vecs[c.id], g.vecs[s]) < c.d {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			out = append(out, c.id)
+		} else {
+			skipped = append(skipped, c.id)
+		}
+	}
+	for _, s := range skipped {
+		if len(out) >= m {
+			break
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// search：检索与 q 最近的 ef 个节点（按距离升序，可能包含已删除节点，由调用方过滤）。
+func (g *graph) search(q []float32, ef int) []candidate {
+	if g.entry < 0 {
+		return nil
+	}
+	ep := candidate{id: g.entry, d: dist(q, g.vecs[g.entry])}
+	for l := g.maxLv; l > 0; l-- {
+		ep = g.greedy(q, ep, l)
+	}
+	return g.searchLayer(q, []candidate{ep}, ef, 0)
+}
@@ -0,0 +1,245 @@
+package localstore
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cook/internal/recipe/vector"
+)
+
+func randVec(r *rand.Rand, dims int) []float64 {
+	v := make([]float64, dims)
+	for i := range v {
+		v[i] = r.NormFloat64()
+	}
+	return v
+}
+
+func doc(id, category string, v []float64) *vector.Document {
+	d := &vector.Document{ID: id, Content: "正文 " + id, MetaData: map[string]any{
+		vector.MetaCategory:  category,
+		vector.MetaIndex:     1,
+		vector.MetaAllergens: []string{"花生"},
+	}}
+	return d.WithScore(0.5).WithDenseVector(v)
+}
+
+func TestGraphRecall(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	const n, dims, k = 3000, 24, 10
+	g := newGraph()
+	for i := 0; i < n; i++ {
+		g.vecs = append(g.vecs, normalize(randVec(r, dims)))
+		g.insert(int32(i))
+	}
+	hit, total := 0, 0
+	for q := 0; q < 50; q++ {
+		qv := normalize(randVec(r, dims))
+		brute := &Store{slots: make([]slot, n), vecs: g.vecs}
+		truth := map[int32]bool{}
+		for _, c := range brute.exact(qv, func(int32) bool { return true })[:k] {
+			truth[c.id] = true
+		}
+		for _, c := range g.search(qv, defaultEfSearch)[:k] {
+			if truth[c.id] {
+				hit++
+			}
+		}
+		total += k
+	}
+	if recall := float64(hit) / float64(total); recall < 0.9 {
+		t.Fatalf("recall@%d = %.2f, want ≥ 0.9", k, recall)
+	}
+}
+
+func TestStoreSearchAndFilter(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(filepath.Join(t.TempDir(), "v.snap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Ensure(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+	docs := []*vector.Document{
+		doc("a", "soup", []float64{1, 0, 0, 0}),
+		doc("b", "meat_dish", []float64{0.9, 0.1, 0, 0}),
+		doc("c", "soup", []float64{0, 1, 0, 0}),
+	}
+	if err := s.Upsert(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Search(ctx, []float64{1, 0, 0, 0}, vector.SearchOptions{TopK: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" || got[0].Score() != 1 {
+		t.Fatalf("search = %v", ids(got))
+	}
+	if got[0].DenseVector() != nil || got[0].MetaData[vector.MetaCategory] != "soup" {
+		t.Fatalf("hit meta = %v", got[0].MetaData)
+	}
+	got, _ = s.Search(ctx, []float64{1, 0, 0, 0}, vector.SearchOptions{TopK: 5, Filter: vector.Filter{Terms: map[string][]string{vector.MetaCategory: {"soup"}}}})
+	if fmt.Sprint(ids(got)) != "[a c]" {
+		t.Fatalf("filtered = %v", ids(got))
+	}
+	got, _ = s.Search(ctx, []float64{1, 0, 0, 0}, vector.SearchOptions{TopK: 5, Filter: vector.Filter{Terms: map[string][]string{vector.MetaAllergens: {"花生"}, vector.MetaCategory: {"meat_dish"}}}})
+	if fmt.Sprint(ids(got)) != "[b]" {
+		t.Fatalf("array filter = %v", ids(got))
+	}
+	if _, err := s.Search(ctx, []float64{1}, vector.SearchOptions{TopK: 1}); err == nil {
+		t.Fatal("query with wrong dims should fail")
+	}
+	if err := s.Ensure(ctx, 8); err == nil {
+		t.Fatal("ensure with other dims should fail on a non-empty store")
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sub", "v.snap")
+	s, _ := Open(path)
+	r := rand.New(rand.NewSource(1))
+	var docs []*vector.Document
+	for i := 0; i < 200; i++ {
+		docs = append(docs, doc(fmt.Sprintf("d%03d", i), "soup", randVec(r, 8)))
+	}
+	if err := s.Upsert(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, []string{"d000", "missing"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	matches, _ := filepath.Glob(path + ".tmp-*")
+	if len(matches) != 0 {
+		t.Fatalf("temp files left behind: %v", matches)
+	}
+
+	s2, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s2.Len() != 199 || s2.Dims() != 8 {
+		t.Fatalf("reloaded len = %d, dims = %d", s2.Len(), s2.Dims())
+	}
+	q := docs[5].DenseVector()
+	a, _ := s.Search(ctx, q, vector.SearchOptions{TopK: 5})
+	b, _ := s2.Search(ctx, q, vector.SearchOptions{TopK: 5})
+	if fmt.Sprint(ids(a)) != fmt.Sprint(ids(b)) || b[0].ID != "d005" {
+		t.Fatalf("before = %v, after = %v", ids(a), ids(b))
+	}
+
+	// 未变化的文档不产生写入；Flush 无变更时不重写文件。
+	info, _ := os.Stat(path)
+	if err := s2.Upsert(ctx, docs[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if s2.dirty || s2.dead != 1 {
+		t.Fatalf("unchanged upsert: dirty = %v, dead = %d", s2.dirty, s2.dead)
+	}
+	if err := s2.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if info2, _ := os.Stat(path); !info2.ModTime().Equal(info.ModTime()) {
+		t.Fatal("clean flush rewrote the snapshot")
+	}
+
+	n, err := s2.Prune(ctx, []string{"d001", "d002"})
+	if err != nil || n != 197 {
+		t.Fatalf("prune = %d, %v", n, err)
+	}
+	if s2.dead != 0 || len(s2.slots) != 2 {
+		t.Fatalf("prune did not compact: dead = %d, slots = %d", s2.dead, len(s2.slots))
+	}
+	s3, _ := Open(path)
+	got, _ := s3.Search(ctx, q, vector.SearchOptions{TopK: 5})
+	if len(got) != 2 {
+		t.Fatalf("after prune = %v", ids(got))
+	}
+}
+
+func TestSearchLargeFiltered(t *testing.T) {
+	ctx := context.Background()
+	s, _ := Open(filepath.Join(t.TempDir(), "v.snap"))
+	r := rand.New(rand.NewSource(3))
+	var docs []*vector.Document
+	for i := 0; i < DefaultExactLimit+500; i++ {
+		cat := "soup"
+		if i%10 == 0 {
+			cat = "dessert"
+		}
+		docs = append(docs, doc(fmt.Sprintf("d%d", i), cat, randVec(r, 16)))
+	}
+	if err := s.Upsert(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.Search(ctx, docs[3].DenseVector(), vector.SearchOptions{TopK: 10})
+	if len(got) != 10 || got[0].ID != "d3" {
+		t.Fatalf("graph search = %v", ids(got))
+	}
+	got, _ = s.Search(ctx, docs[3].DenseVector(), vector.SearchOptions{TopK: 10, Filter: vector.Filter{Terms: map[string][]string{vector.MetaCategory: {"soup"}}}})
+	if len(got) != 10 || got[0].ID != "d3" {
+		t.Fatalf("filtered graph search = %v", ids(got))
+	}
+	for _, d := range got {
+		if d.MetaData[vector.MetaCategory] != "soup" {
+			t.Fatalf("filter leaked %s", d.ID)
+		}
+	}
+}
+
+// 语料规模（约 2000 个分块）下 Search 走近似图，召回率与精确计算对比。
+func TestSearchRecallCorpusScale(t *testing.T) {
+	ctx := context.Background()
+	s, _ := Open(filepath.Join(t.TempDir(), "v.snap"))
+	exact, _ := Open(filepath.Join(t.TempDir(), "exact.snap"))
+	exact.SetExactLimit(1 << 30)
+	r := rand.New(rand.NewSource(11))
+	const n, dims, k = 2000, 64, 10
+	var docs []*vector.Document
+	for i := 0; i < n; i++ {
+		docs = append(docs, doc(fmt.Sprintf("d%d", i), "soup", randVec(r, dims)))
+	}
+	for _, st := range []*Store{s, exact} {
+		if err := st.Upsert(ctx, docs); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n <= DefaultExactLimit {
+		t.Fatalf("corpus of %d documents would not use the graph", n)
+	}
+	hit, total := 0, 0
+	for q := 0; q < 100; q++ {
+		qv := randVec(r, dims)
+		want, _ := exact.Search(ctx, qv, vector.SearchOptions{TopK: k})
+		got, _ := s.Search(ctx, qv, vector.SearchOptions{TopK: k})
+		truth := map[string]bool{}
+		for _, d := range want {
+			truth[d.ID] = true
+		}
+		for _, d := range got {
+			if truth[d.ID] {
+				hit++
+			}
+		}
+		total += k
+	}
+	if recall := float64(hit) / float64(total); recall < 0.95 {
+		t.Fatalf("recall@%d = %.3f, want ≥ 0.95", k, recall)
+	}
+}
+
+func ids(docs []*vector.Document) []string {
+	out := make([]string, len(docs))
+	for i, d := range docs {
+		out[i] = d.ID
+	}
+	return out
+}
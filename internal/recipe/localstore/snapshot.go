@@ -0,0 +1,140 @@
+// 文件功能：快照读写；gob 编码文档、向量与 HNSW 图结构，写入同目录临时文件后重命名，保证读者只会看到完整快照。
+package localstore
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// snapshotVersion：快照格式版本；不兼容的变更需递增。
+const snapshotVersion = 1
+
+// snapshot：快照文件内容。
+type snapshot struct {
+	Version  int
+	Dims     int
+	Docs     []snapDoc
+	Level    []int
+	Links    [][][]int32
+	Entry    int32
+	MaxLevel int
+}
+
+// snapDoc：单个槽位；元数据以 JSON 保存（gob 无法直接编码任意类型的 map 值）。
+type snapDoc struct {
+	ID      string
+	Content string
+	Meta    []byte
+	Vec     []float32
+	Deleted bool
+}
+
+// save：原子写出快照。
+func (s *Store) save() error {
+	snap := snapshot{
+		Version:  snapshotVersion,
+		Dims:     s.dims,
+		Docs:     make([]snapDoc, len(s.slots)),
+		Level:    s.g.level,
+		Links:    s.g.links,
+		Entry:    s.g.entry,
+		MaxLevel: s.g.maxLv,
+	}
+	for i, sl := range s.slots {
+		d := snapDoc{ID: sl.id, Content: sl.content, Vec: s.vecs[i], Deleted: sl.deleted}
+		if !sl.deleted {
+			b, err := json.Marshal(sl.meta)
+			if err != nil {
+				return fmt.Errorf("localstore: encode %s: %w", sl.id, err)
+			}
+			d.Meta = b
+		}
+		snap.Docs[i] = d
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("localstore: %w", err)
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("localstore: %w", err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // 重命名成功后为空操作
+	w := bufio.NewWriter(f)
+	err = f.Chmod(0o644)
+	if err == nil {
+		err = gob.NewEncoder(w).Encode(&snap)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		return fmt.Errorf("localstore: write snapshot: %w", err)
+	}
+	return nil
+}
+
+// load：读取快照；文件不存在时保持空存储。
+func (s *Store) load() error {
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("localstore: %w", err)
+	}
+	defer f.Close()
+	var snap snapshot
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&snap); err != nil {
+		return fmt.Errorf("localstore: read snapshot %s: %w", s.path, err)
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("localstore: %s has snapshot version %d, want %d", s.path, snap.Version, snapshotVersion)
+	}
+	n := len(snap.Docs)
+	if len(snap.Level) != n || len(snap.Links) != n {
+		return fmt.Errorf("localstore: %s: graph has %d nodes for %d documents", s.path, len(snap.Level), n)
+	}
+	s.dims = snap.Dims
+	s.slots = make([]slot, n)
+	s.vecs = make([][]float32, n)
+	for i, d := range snap.Docs {
+		if len(d.Vec) != s.dims {
+			return fmt.Errorf("localstore: %s: %s has %d dimensions, want %d", s.path, d.ID, len(d.Vec), s.dims)
+		}
+		sl := slot{id: d.ID, content: d.Content, deleted: d.Deleted}
+		if d.Deleted {
+			s.dead++
+		} else {
+			if err := json.Unmarshal(d.Meta, &sl.meta); err != nil {
+				return fmt.Errorf("localstore: %s: decode %s: %w", s.path, d.ID, err)
+			}
+			s.byID[d.ID] = int32(i)
+		}
+		s.slots[i] = sl
+		s.vecs[i] = d.Vec
+	}
+	s.g.vecs = s.vecs
+	s.g.level = snap.Level
+	s.g.links = snap.Links
+	s.g.entry = snap.Entry
+	s.g.maxLv = snap.MaxLevel
+	return nil
+}
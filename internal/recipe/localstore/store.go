@@ -0,0 +1,345 @@
+// 文件功能：本地向量存储；实现 vector.Store 与 vector.Searcher：文档与向量常驻内存，HNSW 近似检索，
+// Flush 时将全部文档与图结构原子写入单个快照文件。
+package localstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+
+	"cook/internal/recipe/vector"
+)
+
+// DefaultExactLimit：过滤后候选不超过该数量时直接精确计算，不走近似图；可由 SetExactLimit 调整。
+const DefaultExactLimit = 512
+
+// slot：文档槽位；删除后保留为墓碑，直到重建图时压缩。
+type slot struct {
+	id      string
+	content string
+	meta    map[string]any
+	deleted bool
+}
+
+// Store：本地向量存储；并发安全。
+type Store struct {
+	path       string
+	exactLimit int // 精确计算的候选数上限
+
+	mu    sync.RWMutex
+	dims  int
+	slots []slot
+	vecs  [][]float32 // 与 slots 下标对应，已归一化
+	byID  map[string]int32
+	dead  int
+	g     *graph
+	dirty bool
+}
+
+var (
+	_ vector.Store    = (*Store)(nil)
+	_ vector.Searcher = (*Store)(nil)
+)
+
+// Open：打开快照文件 path；文件不存在时返回空存储，首次 Flush 时创建。
+// 返回值说明：
+//   - error：快照读取或解码失败时返回错误。
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, exactLimit: DefaultExactLimit, byID: map[string]int32{}, g: newGraph()}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Path：快照文件路径。
+func (s *Store) Path() string { return s.path }
+
+// SetExactLimit：设定精确计算的候选数上限；n < 0 时始终走近似图，n 为 0 时使用 DefaultExactLimit。
+func (s *Store) SetExactLimit(n int) {
+	if n == 0 {
+		n = DefaultExactLimit
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exactLimit = n
+}
+
+// Len：有效文档数。
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.slots) - s.dead
+}
+
+// Dims：向量维度；空存储为 0。
+func (s *Store) Dims() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dims
+}
+
+// Ensure：设定向量维度；已有文档且维度不同时返回错误（需删除快照后重建）。
+func (s *Store) Ensure(_ context.Context, dims int) error {
+	if dims <= 0 {
+		return fmt.Errorf("localstore: bad dims %d", dims)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dims == dims {
+		return nil
+	}
+	if len(s.slots)-s.dead > 0 {
+		return fmt.Errorf("localstore: %s has %d-dim vectors but the embedder produces %d; remove it and index again", s.path, s.dims, dims)
+	}
+	s.reset(dims)
+	return nil
+}
+
+// reset：清空存储并设定维度。
+func (s *Store) reset(dims int) {
+	s.dims = dims
+	s.slots, s.vecs, s.dead = nil, nil, 0
+	s.byID = map[string]int32{}
+	s.g = newGraph()
+	s.dirty = true
+}
+
+// Upsert：写入或覆盖文档。
+// 功能说明：内容、元数据与向量均未变化的文档跳过（重复全量索引不会产生墓碑）；变化的文档旧槽位记为墓碑后追加新槽位并插入图。
+// 返回值说明：
+//   - error：文档缺少向量或维度不符时返回错误（此前的文档已写入）。
+func (s *Store) Upsert(_ context.Context, docs []*vector.Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range docs {
+		v := d.DenseVector()
+		if len(v) == 0 {
+			return fmt.Errorf("localstore: upsert %s: document has no dense vector", d.ID)
+		}
+		if s.dims == 0 {
+			s.reset(len(v))
+		}
+		if len(v) != s.dims {
+			return fmt.Errorf("localstore: upsert %s: %d dimensions, want %d", d.ID, len(v), s.dims)
+		}
+		meta, err := cleanMeta(d.MetaData)
+		if err != nil {
+			return fmt.Errorf("localstore: upsert %s: %w", d.ID, err)
+		}
+		vec := normalize(v)
+		if i, ok := s.byID[d.ID]; ok {
+			old := s.slots[i]
+			if old.content == d.Content && reflect.DeepEqual(old.meta, meta) && reflect.DeepEqual(s.vecs[i], vec) {
+				continue
+			}
+			s.tombstone(i)
+		}
+		id := int32(len(s.slots))
+		s.slots = append(s.slots, slot{id: d.ID, content: d.Content, meta: meta})
+		s.vecs = append(s.vecs, vec)
+		s.byID[d.ID] = id
+		s.g.vecs = s.vecs
+		s.g.insert(id)
+		s.dirty = true
+	}
+	return nil
+}
+
+// Delete：删除文档；不存在的 ID 忽略。
+func (s *Store) Delete(_ context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if i, ok := s.byID[id]; ok {
+			s.tombstone(i)
+		}
+	}
+	return nil
+}
+
+// Prune：删除不在 keep 中的全部文档并写出快照，返回删除条数。
+func (s *Store) Prune(ctx context.Context, keep []string) (int, error) {
+	s.mu.Lock()
+	want := make(map[string]bool, len(keep))
+	for _, id := range keep {
+		want[id] = true
+	}
+	n := 0
+	for id, i := range s.byID {
+		if !want[id] {
+			s.tombstone(i)
+			n++
+		}
+	}
+	s.mu.Unlock()
+	if n == 0 {
+		return 0, nil
+	}
+	return n, s.Flush(ctx)
+}
+
+// tombstone：将槽位 i 记为删除。
+func (s *Store) tombstone(i int32) {
+	delete(s.byID, s.slots[i].id)
+	s.slots[i].deleted = true
+	s.slots[i].meta = nil
+	s.dead++
+	s.dirty = true
+}
+
+// Flush：写出快照；无变更时跳过。墓碑超过有效文档的 1/4 时先压缩槽位并重建图。
+func (s *Store) Flush(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	if s.dead > 0 && s.dead*4 > len(s.slots)-s.dead {
+		s.compact()
+	}
+	if err := s.save(); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// compact：丢弃墓碑并按原顺序重建图。
+func (s *Store) compact() {
+	slots, vecs := s.slots, s.vecs
+	s.slots, s.vecs, s.dead = nil, nil, 0
+	s.byID = map[string]int32{}
+	s.g = newGraph()
+	for i, sl := range slots {
+		if sl.deleted {
+			continue
+		}
+		id := int32(len(s.slots))
+		s.slots = append(s.slots, sl)
+		s.vecs = append(s.vecs, vecs[i])
+		s.byID[sl.id] = id
+		s.g.vecs = s.vecs
+		s.g.insert(id)
+	}
+}
+
+// Search：近似最近邻检索。
+// 功能说明：过滤后的候选不超过精确计算上限（见 SetExactLimit）时精确计算；否则在 HNSW 图上以逐步扩大的候选集搜索并过滤，
+// 候选集覆盖全部节点仍不足 TopK 时退回精确计算。得分为 (1 + 余弦相似度) / 2，与 Elasticsearch 的 cosine 得分一致。
+// 返回值说明：
+//   - []*vector.Document：按得分降序的文档（不含向量）；
+//   - error：查询向量维度不符时返回错误。
+func (s *Store) Search(_ context.Context, vec []float64, opts vector.SearchOptions) ([]*vector.Document, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if opts.TopK <= 0 || len(s.slots) == s.dead {
+		return nil, nil
+	}
+	if len(vec) != s.dims {
+		return nil, fmt.Errorf("localstore: query has %d dimensions, want %d", len(vec), s.dims)
+	}
+	q := normalize(vec)
+	ok := func(i int32) bool { return !s.slots[i].deleted && opts.Filter.Match(s.slots[i].meta) }
+
+	var hits []candidate
+	if matched := s.count(opts.Filter); matched <= s.exactLimit {
+		hits = s.exact(q, ok)
+	} else {
+		for ef := max(opts.TopK*4, defaultEfSearch); ; ef *= 2 {
+			hits = hits[:0]
+			for _, c := range s.g.search(q, ef) {
+				if ok(c.id) {
+					hits = append(hits, c)
+				}
+			}
+			if len(hits) >= opts.TopK {
+				break
+			}
+			if ef >= len(s.slots) {
+				hits = s.exact(q, ok)
+				break
+			}
+		}
+	}
+	if len(hits) > opts.TopK {
+		hits = hits[:opts.TopK]
+	}
+	out := make([]*vector.Document, 0, len(hits))
+	for _, h := range hits {
+		sl := s.slots[h.id]
+		meta := make(map[string]any, len(sl.meta)+1)
+		for k, v := range sl.meta {
+			meta[k] = v
+		}
+		d := &vector.Document{ID: sl.id, Content: sl.content, MetaData: meta}
+		out = append(out, d.WithScore(float64(2-h.d)/2))
+	}
+	return out, nil
+}
+
+// count：满足过滤条件的有效文档数。
+func (s *Store) count(f vector.Filter) int {
+	if f.Empty() {
+		return len(s.slots) - s.dead
+	}
+	n := 0
+	for _, sl := range s.slots {
+		if !sl.deleted && f.Match(sl.meta) {
+			n++
+		}
+	}
+	return n
+}
+
+// exact：对满足 ok 的全部槽位精确计算距离，按距离升序返回。
+func (s *Store) exact(q []float32, ok func(int32) bool) []candidate {
+	var out []candidate
+	for i := range s.slots {
+		if ok(int32(i)) {
+			out = append(out, candidate{id: int32(i), d: dist(q, s.vecs[i])})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].d < out[j].d })
+	return out
+}
+
+// cleanMeta：去掉得分、向量等内部键，并经 JSON 往返统一取值类型（数字为 float64、数组为 []any），
+// 使内存中的元数据与快照加载后的一致。
+func cleanMeta(m map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if len(k) > 0 && k[0] == '_' {
+			continue
+		}
+		out[k] = v
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	out = nil
+	err = json.Unmarshal(b, &out)
+	return out, err
+}
+
+// normalize：转换为单位长度的 float32 向量；零向量原样返回。
+func normalize(v []float64) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	out := make([]float32, len(v))
+	if sum == 0 {
+		return out
+	}
+	n := math.Sqrt(sum)
+	for i, x := range v {
+		out[i] = float32(x / n)
+	}
+	return out
+}
@@ -0,0 +1,3 @@
+// 文件功能：菜名检索包说明。
+// 包功能：lookup 包，基于解析得到的菜谱标题构建菜名索引，支持全拼、拼音首字母、同音字与编辑距离模糊匹配；拼音表随包内嵌，离线可用。
+package lookup
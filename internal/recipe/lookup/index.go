@@ -0,0 +1,333 @@
+// 文件功能：菜名索引；按汉字、全拼、首字母、同音字与编辑距离为查询打分，返回最可能的菜谱。
+package lookup
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/textnorm"
+)
+
+// 匹配方式；分值依次降低。
+const (
+	MatchExact    = "exact"    // 菜名完全相同（按检索键）
+	MatchPinyin   = "pinyin"   // 全拼相同，或汉字查询与菜名同音（如「宫爆鸡丁」）
+	MatchPrefix   = "prefix"   // 菜名以查询开头
+	MatchContains = "contains" // 菜名包含查询，或查询包含菜名（如「红烧肉怎么做」）
+	MatchInitials = "initials" // 拼音首字母相同或为前缀
+	MatchFuzzy    = "fuzzy"    // 编辑距离在容忍范围内
+)
+
+// Match：查询结果。
+type Match struct {
+	ID    string  `json:"id"`    // 菜谱标识（相对路径）
+	Name  string  `json:"name"`  // 菜名（原文）
+	Score float64 `json:"score"` // 匹配得分（0~1）
+	Kind  string  `json:"match"` // 匹配方式
+}
+
+// entry：索引条目；同一菜谱的每个别名各占一条。
+type entry struct {
+	id       string
+	name     string
+	key      string     // 检索键（textnorm.Fold，去空白）
+	syl      [][]string // 逐字候选读音
+	full     []string   // 全拼组合
+	initials []string   // 首字母组合
+}
+
+// Index：菜名索引；构建后只读，可并发查询。
+type Index struct {
+	entries []entry
+}
+
+// NewIndex：构造空索引。
+func NewIndex() *Index { return &Index{} }
+
+// FromRecipes：由结构化菜谱构建索引；标识为 Path，菜名取 Title，文件名与 Title 不同时作为别名。
+func FromRecipes(recipes []*parser.Recipe) *Index {
+	ix := NewIndex()
+	for _, r := range recipes {
+		base := r.Path
+		if i := strings.LastIndexAny(base, `/\`); i >= 0 {
+			base = base[i+1:]
+		}
+		if i := strings.LastIndexByte(base, '.'); i > 0 {
+			base = base[:i]
+		}
+		ix.Add(r.Path, r.Title, base)
+	}
+	return ix
+}
+
+// Add：添加菜谱；name 为展示用菜名，aliases 为其他可匹配的名称（空串与重复项忽略）。
+func (ix *Index) Add(id, name string, aliases ...string) {
+	seen := make(map[string]bool)
+	for _, n := range append([]string{name}, aliases...) {
+		key := searchKey(n)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		full, initials := Pinyin(key)
+		ix.entries = append(ix.entries, entry{id: id, name: name, key: key, syl: syllables(key), full: full, initials: initials})
+	}
+}
+
+// Len：索引中的菜谱别名条目数。
+func (ix *Index) Len() int { return len(ix.entries) }
+
+// Search：查询菜名。
+// 功能说明：
+//  1. 查询含汉字时：检索键完全相同、前缀、互相包含依次计分；读音相同视为同音错字；
+//     否则以编辑距离比较菜名与查询（及查询中与菜名等长的片段），容忍 1~2 个错字；
+//  2. 查询为字母时视为拼音：全拼相同、首字母相同、全拼或首字母前缀依次计分，否则按全拼编辑距离模糊匹配；
+//  3. 同一菜谱多个别名或读音命中时取最高分。
+//
+// 既可用于菜名查找接口，也可作为检索的候选生成器：用户问题中含有写错的菜名时仍能召回对应菜谱。
+// 参数说明：
+//   - q：查询；繁简、全半角与大小写不敏感；
+//   - limit：最多返回条数；≤0 表示不限。
+//
+// 返回值说明：
+//   - []Match：按得分降序（同分时菜名长度与查询更接近者优先）排列；无命中时为空。
+func (ix *Index) Search(q string, limit int) []Match {
+	key := searchKey(q)
+	if key == "" {
+		return nil
+	}
+	han := hasHan(key)
+	var qSyl [][]string
+	if han {
+		qSyl = syllables(key)
+	}
+	best := make(map[string]Match)
+	for _, e := range ix.entries {
+		var score float64
+		var kind string
+		if han {
+			score, kind = scoreHan(e, key, qSyl)
+		} else {
+			score, kind = scoreLatin(e, key)
+		}
+		if score == 0 {
+			continue
+		}
+		score = math.Round(score*100) / 100
+		if m, ok := best[e.id]; !ok || score > m.Score {
+			best[e.id] = Match{ID: e.id, Name: e.name, Score: score, Kind: kind}
+		}
+	}
+	out := make([]Match, 0, len(best))
+	for _, m := range best {
+		out = append(out, m)
+	}
+	qLen := utf8.RuneCountInString(key)
+	gap := func(m Match) int {
+		d := utf8.RuneCountInString(m.Name) - qLen
+		if !han {
+			d = utf8.RuneCountInString(m.Name)*3 - qLen // 拼音约 3 个字母对应一个汉字
+		}
+		return max(d, -d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if ga, gb := gap(a), gap(b); ga != gb {
+			return ga < gb
+		}
+		return a.ID < b.ID
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// scoreHan：汉字查询计分。
+func scoreHan(e entry, q string, qSyl [][]string) (float64, string) {
+	switch {
+	case e.key == q:
+		return 1, MatchExact
+	case len(e.syl) == len(qSyl) && homophone(e.syl, qSyl):
+		return 0.95, MatchPinyin
+	case strings.HasPrefix(e.key, q):
+		return 0.85, MatchPrefix
+	case strings.Contains(q, e.key) && utf8.RuneCountInString(e.key) >= 2:
+		return 0.8, MatchContains
+	case strings.Contains(e.key, q):
+		return 0.75, MatchContains
+	case len(e.syl) >= 3 && homophoneWindow(e.syl, qSyl):
+		return 0.75, MatchPinyin
+	}
+	name := []rune(e.key)
+	tol := tolerance(len(name))
+	d := windowDistance(name, []rune(q), tol)
+	if d <= tol {
+		return 0.7 - 0.1*float64(d), MatchFuzzy
+	}
+	return 0, ""
+}
+
+// scoreLatin：拼音（或英文）查询计分。
+func scoreLatin(e entry, q string) (float64, string) {
+	switch {
+	case e.key == q:
+		return 1, MatchExact
+	case contains(e.full, q, strings.EqualFold):
+		return 0.95, MatchPinyin
+	case contains(e.initials, q, strings.EqualFold) && len(q) >= 2:
+		return 0.85, MatchInitials
+	case len(q) >= 3 && contains(e.full, q, strings.HasPrefix):
+		return 0.8, MatchPrefix
+	case strings.Contains(e.key, q):
+		return 0.75, MatchContains
+	case len(q) >= 4 && contains(e.full, q, strings.Contains):
+		return 0.7, MatchContains
+	case len(q) >= 2 && contains(e.initials, q, strings.HasPrefix):
+		return 0.65, MatchInitials
+	case len(q) >= 3 && contains(e.initials, q, strings.Contains):
+		return 0.55, MatchInitials
+	}
+	if len(q) < 4 {
+		return 0, ""
+	}
+	tol := tolerance(len(q) / 3) // 拼音约 3 个字母对应一个汉字
+	bestD := tol + 1
+	for _, f := range e.full {
+		bestD = min(bestD, distance([]rune(f), []rune(q), tol))
+	}
+	if bestD <= tol {
+		return 0.6 - 0.1*float64(bestD), MatchFuzzy
+	}
+	return 0, ""
+}
+
+// tolerance：按长度（汉字数）确定容忍的编辑次数；过短的名称不做模糊匹配。
+func tolerance(n int) int {
+	switch {
+	case n < 3:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// windowDistance：菜名与查询的编辑距离；查询明显长于菜名时（如整句提问）取与菜名等长的各片段中的最小值。
+// 片段比较只对 4 字及以上的菜名生效，避免「姜炒鸡」之类的短菜名与长句中的任意片段误配。
+func windowDistance(name, q []rune, tol int) int {
+	if len(q) <= len(name)+tol {
+		return distance(name, q, tol)
+	}
+	if len(name) < 4 {
+		return tol + 1
+	}
+	best := tol + 1
+	for i := 0; i+len(name) <= len(q); i++ {
+		best = min(best, distance(name, q[i:i+len(name)], tol))
+	}
+	return best
+}
+
+// distance：受限 Damerau-Levenshtein（OSA）距离，相邻字符互换计 1 次；超过 limit 时提前返回 limit+1。
+func distance(a, b []rune, limit int) int {
+	if d := len(a) - len(b); d > limit || -d > limit {
+		return limit + 1
+	}
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return min(prev[len(b)], limit+1)
+}
+
+// searchKey：检索键；textnorm 折叠后去掉空白与撇号（xi'an）。
+func searchKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '\'' {
+			return -1
+		}
+		return r
+	}, textnorm.Fold(s))
+}
+
+// hasHan：是否含有汉字。
+func hasHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains：ss 中是否有元素与 q 满足 pred(元素, q)。
+func contains(ss []string, q string, pred func(s, q string) bool) bool {
+	for _, s := range ss {
+		if pred(s, q) {
+			return true
+		}
+	}
+	return false
+}
+
+// homophone：两个等长音节序列是否逐字同音（任一候选读音相同即可）。
+func homophone(a, b [][]string) bool {
+	for i := range a {
+		if !intersects(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// homophoneWindow：查询中是否有与菜名等长且逐字同音的片段。
+func homophoneWindow(name, q [][]string) bool {
+	for i := 0; i+len(name) <= len(q); i++ {
+		if homophone(name, q[i:i+len(name)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// intersects：两个集合是否有公共元素。
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
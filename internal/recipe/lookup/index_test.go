@@ -0,0 +1,80 @@
+// 文件功能：菜名索引的单元测试；覆盖全拼、首字母、同音错字、整句中的菜名与模糊匹配。
+package lookup
+
+import (
+	"testing"
+
+	"cook/internal/recipe/parser"
+)
+
+func testIndex() *Index {
+	return FromRecipes([]*parser.Recipe{
+		{Title: "宫保鸡丁", Path: "meat_dish/宫保鸡丁/宫保鸡丁.md"},
+		{Title: "南派红烧肉", Path: "meat_dish/红烧肉/南派红烧肉.md"},
+		{Title: "红烧鱼", Path: "aquatic/红烧鱼.md"},
+		{Title: "红烧鱼头", Path: "aquatic/红烧鱼头.md"},
+		{Title: "可乐鸡翅", Path: "meat_dish/可乐鸡翅.md"},
+		{Title: "豉汁蒸白鱔", Path: "meat_dish/豉汁蒸白鱔.md"},
+		{Title: "姜炒鸡", Path: "meat_dish/姜炒鸡.md"},
+	})
+}
+
+func TestPinyin(t *testing.T) {
+	full, initials := Pinyin("宫保鸡丁")
+	if len(full) != 1 || full[0] != "gongbaojiding" || initials[0] != "gbjd" {
+		t.Fatalf("pinyin: %q %q", full, initials)
+	}
+	// 多音字展开：「卜」读 bo（萝卜）与 bu
+	if full, _ := Pinyin("萝卜"); len(full) != 2 {
+		t.Fatalf("polyphone: %q", full)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ix := testIndex()
+	cases := []struct {
+		q, want, kind string
+	}{
+		{"gongbaojiding", "宫保鸡丁", MatchPinyin},
+		{"Gong Bao Ji Ding", "宫保鸡丁", MatchPinyin},
+		{"gbjd", "宫保鸡丁", MatchInitials},
+		{"宫爆鸡丁", "宫保鸡丁", MatchPinyin},
+		{"宫爆鸡丁怎么做", "宫保鸡丁", MatchPinyin},
+		{"宫保鸡肉丁", "宫保鸡丁", MatchFuzzy},
+		{"gongbaojidng", "宫保鸡丁", MatchFuzzy},
+		{"hongshaorou", "南派红烧肉", MatchContains},
+		{"红烧鱼", "红烧鱼", MatchExact},
+		{"紅燒魚頭", "红烧鱼头", MatchExact},
+		{"kelejichi", "可乐鸡翅", MatchPinyin},
+		{"白鳝", "豉汁蒸白鱔", MatchContains},
+	}
+	for _, c := range cases {
+		got := ix.Search(c.q, 3)
+		if len(got) == 0 || got[0].Name != c.want || got[0].Kind != c.kind {
+			t.Errorf("Search(%q) = %+v, want %s by %s", c.q, got, c.want, c.kind)
+		}
+	}
+	if got := ix.Search("西红柿炒鸡蛋", 0); len(got) != 0 {
+		t.Errorf("short names should not match sentence windows: %+v", got)
+	}
+	if got := ix.Search("红烧", 0); len(got) != 3 || got[0].Name != "红烧鱼" {
+		t.Errorf("prefix ranking: %+v", got)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"gongbao", "gongbao", 0},
+		{"gongbao", "gognbao", 1}, // 相邻互换
+		{"宫保鸡丁", "宫爆鸡丁", 1},
+		{"abc", "xyz", 3},
+	}
+	for _, c := range cases {
+		if got := distance([]rune(c.a), []rune(c.b), 5); got != c.want {
+			t.Errorf("distance(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// 文件功能：离线拼音表与汉字转拼音；多音字展开为多种读音组合。
+package lookup
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed pinyin.tsv
+var pinyinData string
+
+var (
+	pinyinOnce sync.Once
+	readings   map[rune][]string
+)
+
+// maxVariants：单个名称展开的读音组合上限；超过后其余多音字只取首个读音。
+const maxVariants = 16
+
+// loadPinyin：解析内嵌拼音表；仅在首次使用时执行。
+func loadPinyin() {
+	readings = make(map[rune][]string, 3000)
+	for _, line := range strings.Split(pinyinData, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		syl, chars, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		for _, r := range chars {
+			readings[r] = append(readings[r], syl)
+		}
+	}
+}
+
+// Readings：汉字的全部读音（不带声调）；表中没有的字符返回 nil。
+func Readings(r rune) []string {
+	pinyinOnce.Do(loadPinyin)
+	return readings[r]
+}
+
+// syllables：将文本切分为音节序列；每个位置为该字符的候选读音。
+// 汉字取拼音表读音（未收录的汉字保留原字）；连续的字母数字合并为一个小写片段；其余字符忽略。
+func syllables(s string) [][]string {
+	var out [][]string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			out = append(out, []string{word.String()})
+			word.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word.WriteRune(unicode.ToLower(r))
+		case unicode.Is(unicode.Han, r):
+			flush()
+			if rs := Readings(r); len(rs) > 0 {
+				out = append(out, rs)
+			} else {
+				out = append(out, []string{string(r)})
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+	return out
+}
+
+// Pinyin：文本的全拼（音节直接相连）与首字母串；多音字展开为多种组合，最多 maxVariants 种。
+// 示例：Pinyin("宫保鸡丁") 的全拼为 ["gongbaojiding"]，首字母为 ["gbjd"]。
+func Pinyin(s string) (full, initials []string) {
+	full, initials = []string{""}, []string{""}
+	for _, cands := range syllables(s) {
+		if len(full)*len(cands) > maxVariants {
+			cands = cands[:1]
+		}
+		nf := make([]string, 0, len(full)*len(cands))
+		ni := make([]string, 0, len(full)*len(cands))
+		for i := range full {
+			for _, c := range cands {
+				nf = append(nf, full[i]+c)
+				_, n := utf8.DecodeRuneInString(c)
+				ni = append(ni, initials[i]+c[:n])
+			}
+		}
+		full, initials = nf, ni
+	}
+	return dedupe(full), dedupe(initials)
+}
+
+// dedupe：保持顺序去重。
+func dedupe(ss []string) []string {
+	seen := make(map[string]bool, len(ss))
+	out := ss[:0]
+	for _, s := range ss {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
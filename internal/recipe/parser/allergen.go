@@ -0,0 +1,59 @@
+// 文件功能：常见过敏原识别；基于原料文本的关键词匹配，为分块标注过敏原元数据。
+package parser
+
+import (
+	"strings"
+
+	"cook/internal/recipe/textnorm"
+)
+
+// allergenRule：过敏原类别及其关键词；exclude 中的词先从文本中剔除，避免「鱼香肉丝」之类的误判。
+type allergenRule struct {
+	name     string
+	keywords []string
+	exclude  []string
+}
+
+// allergenRules：参照 GB 7718 推荐标示的致敏物质类别；顺序即输出顺序。
+var allergenRules = []allergenRule{
+	{name: "花生", keywords: []string{"花生"}},
+	{name: "坚果", keywords: []string{"核桃", "杏仁", "腰果", "榛子", "开心果", "松子", "碧根果", "夏威夷果"}},
+	{name: "蛋类", keywords: []string{"鸡蛋", "鸭蛋", "鹌鹑蛋", "皮蛋", "咸蛋", "蛋黄", "蛋白", "蛋清", "全蛋", "蛋液"}, exclude: []string{"蛋白质", "蟹黄"}},
+	{name: "乳制品", keywords: []string{"牛奶", "奶油", "黄油", "芝士", "奶酪", "酸奶", "炼乳", "奶粉", "淡奶", "乳酪"}, exclude: []string{"椰奶"}},
+	{name: "麸质", keywords: []string{"面粉", "小麦", "面条", "挂面", "馒头", "面包", "意大利面", "饺子皮", "馄饨皮", "面筋", "烤麸"}},
+	{name: "大豆", keywords: []string{"黄豆", "大豆", "豆腐", "豆浆", "腐竹", "豆皮", "酱油", "生抽", "老抽", "黄豆酱", "豆瓣酱"}},
+	{name: "鱼类", keywords: []string{"鱼", "鱼露"}, exclude: []string{"鱼香", "鱿鱼", "墨鱼", "章鱼", "鲍鱼", "甲鱼", "鱼腥草"}},
+	{name: "甲壳类", keywords: []string{"虾", "蟹", "龙虾", "小龙虾"}},
+	{name: "软体贝类", keywords: []string{"鱿鱼", "墨鱼", "章鱼", "鲍鱼", "扇贝", "蛤蜊", "花甲", "生蚝", "牡蛎", "青口", "蚬子", "蚝油"}},
+	{name: "芝麻", keywords: []string{"芝麻", "麻酱", "香油", "麻油"}},
+}
+
+// DetectAllergens：识别文本（通常为原料清单）中出现的过敏原类别；文本先经 textnorm.Fold 折叠，繁体原料名同样命中。
+// 参数说明：
+//   - text：原料文本。
+//
+// 返回值说明：
+//   - []string：命中的过敏原类别，按 allergenRules 顺序；未命中返回 nil。
+func DetectAllergens(text string) []string {
+	var out []string
+	text = textnorm.Fold(text)
+	for _, r := range allergenRules {
+		if r.match(text) {
+			out = append(out, r.name)
+		}
+	}
+	return out
+}
+
+// match：折叠后的文本剔除 exclude 后是否包含任一关键词。
+func (r allergenRule) match(text string) bool {
+	for _, ex := range r.exclude {
+		text = strings.ReplaceAll(text, ex, "")
+	}
+	for _, kw := range r.keywords {
+		if strings.Contains(text, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+// 文件功能：检索过滤用的菜谱属性：原料名拆分、饮食标签识别、总时长估算与营养数值解析。
+package parser
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cook/internal/recipe/textnorm"
+	"cook/internal/recipe/units"
+)
+
+// AnnotationVersion：分块元数据识别规则（原料名、过敏原、饮食标签、总时长、营养、用量与火候）的版本；
+// 规则变化会改变未修改文件的分块元数据，修改这些规则时递增，增量解析据此使旧清单中的分块全部失效。
+const AnnotationVersion = 1
+
+// parenRegex：原料中的括注（如「青蟹（别称：肉蟹）」）。
+var parenRegex = regexp.MustCompile(`[(（]([^)）]*)[)）]`)
+
+// aliasRegex：括注中的别称。
+var aliasRegex = regexp.MustCompile(`(?:别称|又称|又名|也叫)[:：]?\s*(\S+)`)
+
+// IngredientNames：由原料清单拆出原料名。
+// 功能说明：去掉括注（括注中的别称单独收录），按顿号、逗号、斜杠、分号、空格与「或」拆分并列项；
+// 结果去重并保持出现顺序，保留原文写法。
+func IngredientNames(items []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(w string) {
+		w = strings.TrimSpace(w)
+		if w != "" && !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	for _, ing := range items {
+		for _, m := range parenRegex.FindAllStringSubmatch(ing, -1) {
+			if a := aliasRegex.FindStringSubmatch(m[1]); a != nil {
+				add(a[1])
+			}
+		}
+		ing = parenRegex.ReplaceAllString(ing, " ")
+		for _, part := range strings.FieldsFunc(ing, splitIngredient) {
+			add(part)
+		}
+	}
+	return out
+}
+
+// IngredientAliases：由原料清单中的别称括注提取同义词对（原料名，别称），如「青蟹（别称：肉蟹）」得到（青蟹，肉蟹）。
+// 原料名取括注前最后一个并列项；结果按出现顺序，不去重。
+func IngredientAliases(items []string) [][2]string {
+	var out [][2]string
+	for _, ing := range items {
+		for _, loc := range parenRegex.FindAllStringSubmatchIndex(ing, -1) {
+			a := aliasRegex.FindStringSubmatch(ing[loc[2]:loc[3]])
+			if a == nil {
+				continue
+			}
+			parts := strings.FieldsFunc(parenRegex.ReplaceAllString(ing[:loc[0]], " "), splitIngredient)
+			if len(parts) > 0 {
+				out = append(out, [2]string{parts[len(parts)-1], a[1]})
+			}
+		}
+	}
+	return out
+}
+
+// splitIngredient：原料并列项分隔符。
+func splitIngredient(r rune) bool {
+	return strings.ContainsRune("、，,/／;；或 ", r)
+}
+
+// 饮食标签。
+const (
+	DietVegetarian  = "素食"  // 不含畜禽肉与水产
+	DietVegan       = "纯素"  // 素食且不含蛋、奶、蜂蜜
+	DietGlutenFree  = "无麸质" // 不含麸质过敏原
+	DietLowCalorie  = "低卡"  // 每份热量不超过 400 kcal
+	DietLowFat      = "低脂"  // 每份脂肪不超过 10 g
+	DietHighProtein = "高蛋白" // 每份蛋白质不少于 20 g
+)
+
+// meatRule：荤食原料关键词；exclude 中的词先从文本中剔除，避免「牛油果」「鸡蛋」「鱼香」之类的误判。
+var meatRule = allergenRule{
+	keywords: []string{"肉", "鸡", "鸭", "鹅", "牛", "猪", "羊", "排骨", "腊肠", "香肠", "培根", "火腿", "午餐肉",
+		"鱼", "虾", "蟹", "贝", "蛤", "蚝", "鱿", "墨鱼", "章鱼", "海参", "鲍鱼", "花甲", "骨头", "猪油", "高汤", "鱼露"},
+	exclude: []string{"牛奶", "牛油果", "蜗牛", "鸡蛋", "鸭蛋", "鹅蛋", "鸡精", "鸡粉", "鱼香", "肉桂", "肉豆蔻", "素肉",
+		"鸡腿菇", "鸡枞", "猪肉松饼", "羊肚菌", "牛肝菌", "鱼腥草"},
+}
+
+// animalRule：蛋奶与蜂蜜关键词（纯素判定）；蛋类与乳制品复用过敏原规则，此处补充蜂蜜与动物油脂。
+var animalRule = allergenRule{keywords: []string{"蜂蜜", "黄油", "猪油", "牛油", "鸡蛋", "蛋"}, exclude: []string{"牛油果", "蛋白质"}}
+
+// 营养阈值（每份）。
+const (
+	lowCalorieKcal = 400
+	lowFatGrams    = 10
+	highProteinG   = 20
+)
+
+// DetectDiets：识别菜谱的饮食标签。
+// 功能说明：素食、纯素由原料名关键词判定（原料清单为空时不判定）；无麸质由过敏原判定；
+// 低卡、低脂、高蛋白由营养成分判定（缺少对应营养数据时不标注）。
+// 参数说明：
+//   - r：菜谱；使用 Ingredients 与 Nutrition；
+//   - allergens：DetectAllergens 的结果。
+//
+// 返回值说明：
+//   - []string：饮食标签，按 Diet* 常量顺序；未命中返回 nil。
+func DetectDiets(r *Recipe, allergens []string) []string {
+	var out []string
+	if len(r.Ingredients) > 0 {
+		text := textnorm.Fold(strings.Join(r.Ingredients, "\n"))
+		if !meatRule.match(text) {
+			out = append(out, DietVegetarian)
+			if !animalRule.match(text) && !contains(allergens, "蛋类") && !contains(allergens, "乳制品") {
+				out = append(out, DietVegan)
+			}
+		}
+		if !contains(allergens, "麸质") {
+			out = append(out, DietGlutenFree)
+		}
+	}
+	if v, ok := NutritionValue(r.Nutrition, "calories"); ok && v <= lowCalorieKcal {
+		out = append(out, DietLowCalorie)
+	}
+	if v, ok := NutritionValue(r.Nutrition, "fatContent"); ok && v <= lowFatGrams {
+		out = append(out, DietLowFat)
+	}
+	if v, ok := NutritionValue(r.Nutrition, "proteinContent"); ok && v >= highProteinG {
+		out = append(out, DietHighProtein)
+	}
+	return out
+}
+
+// contains：ss 是否包含 s。
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// numberRegex：营养数值中的首个数字（「350 kcal」「12.5g」）。
+var numberRegex = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// NutritionValue：读取营养成分的数值部分；热量按 kcal，其余按克（毫克换算为克）。
+// 返回值说明：
+//   - float64：数值；
+//   - bool：字段缺失或无法识别数字时返回 false。
+func NutritionValue(n map[string]string, key string) (float64, bool) {
+	s := strings.ToLower(textnorm.Fold(n[key]))
+	m := numberRegex.FindString(s)
+	if m == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m, 64)
+	if err != nil {
+		return 0, false
+	}
+	switch {
+	case strings.Contains(s, "kj") || strings.Contains(s, "千焦"):
+		v /= 4.184
+	case strings.Contains(s, "mg") || strings.Contains(s, "毫克"):
+		v /= 1000
+	}
+	return v, true
+}
+
+// durationRegex：步骤中的时长（「焖 40 分钟」「炖 1~2 小时」「半小时」「三十秒」）；数量部分交给 units.ParseAmount。
+var durationRegex = regexp.MustCompile(`([0-9.~\-〜到至零〇一二两三四五六七八九十百半]+)\s*(?:个)?\s*(?:半)?\s*(分钟|小时|钟头|秒)`)
+
+// TotalMinutes：菜谱总时长（分钟）。
+// 功能说明：CookingTime 含「N 分钟」时直接采用；否则累加各步骤中出现的时长（范围取中点，「个半小时」加半小时），
+// 作为烹饪耗时的估计。无法识别时返回 0。
+func TotalMinutes(r *Recipe) int {
+	if m := durationRegex.FindStringSubmatch(textnorm.Fold(r.CookingTime)); m != nil {
+		if v := minutes(m); v > 0 {
+			return int(math.Round(v))
+		}
+	}
+	var total float64
+	for _, s := range r.Steps {
+		for _, m := range durationRegex.FindAllStringSubmatch(textnorm.Fold(s), -1) {
+			total += minutes(m)
+		}
+	}
+	return int(math.Round(total))
+}
+
+// minutes：将 durationRegex 的一次匹配换算为分钟；数量无法识别时返回 0。
+func minutes(m []string) float64 {
+	a, ok := units.ParseAmount(m[1])
+	if !ok {
+		return 0
+	}
+	v := a.Value
+	if strings.Contains(m[0], "半"+m[2]) && m[1] != "半" {
+		v += 0.5
+	}
+	switch m[2] {
+	case "小时", "钟头":
+		v *= 60
+	case "秒":
+		v /= 60
+	}
+	return v
+}
@@ -0,0 +1,192 @@
+// 文件功能：文档收集过滤规则；支持 include/exclude 通配模式、语料根目录下的忽略文件与确定性排序的目录遍历。
+// 包功能：parser 包，为各解析器实现提供统一的文件收集能力。
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IgnoreFileName：语料根目录下的默认忽略文件名；语法与 .gitignore 的常用子集一致。
+const IgnoreFileName = ".recipeignore"
+
+// DefaultExcludes：默认排除的模式；template/ 为 HowToCook 的示例菜模板目录，不应进入索引。
+var DefaultExcludes = []string{"template/"}
+
+// Filter：文件收集过滤规则。
+//   - Include：包含模式；非空时文件必须至少匹配其中之一；
+//   - Exclude：排除模式；匹配的文件或目录（及其子树）被跳过；
+//   - IgnoreFile：根目录下的忽略文件名；为空时不读取忽略文件。
+//
+// 模式语法：以 / 分隔的相对路径通配；支持 *、?、[...] 与跨目录的 **；
+// 不含 / 的模式匹配任意层级的单个路径段；以 / 结尾的模式仅匹配目录。
+type Filter struct {
+	Include    []string // 包含模式
+	Exclude    []string // 排除模式
+	IgnoreFile string   // 忽略文件名
+}
+
+// DefaultFilter：返回默认过滤规则（排除 template/ 并读取 .recipeignore）。
+func DefaultFilter() Filter {
+	return Filter{
+		Exclude:    append([]string(nil), DefaultExcludes...),
+		IgnoreFile: IgnoreFileName,
+	}
+}
+
+// Walk：按过滤规则递归收集 root 下被 accept 接受的文件路径。
+// 功能说明：校验 root 为可读目录；合并忽略文件中的排除模式；被排除的目录整体跳过；结果按路径字典序排序，保证多次运行输出一致。
+// 参数说明：
+//   - root：扫描根目录；为空、不存在或非目录时报错；
+//   - f：过滤规则；
+//   - accept：文件类型判定（如按扩展名）；为 nil 时接受全部文件。
+//
+// 返回值说明：
+//   - []string：排序后的文件路径（以 root 为前缀）；
+//   - error：参数非法、忽略文件读取失败或遍历 I/O 错误。
+func Walk(root string, f Filter, accept func(path string) bool) ([]string, error) {
+	if root == "" {
+		return nil, fmt.Errorf("collect: root is empty")
+	}
+	st, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("collect: stat root: %w", err)
+	}
+	if !st.IsDir() {
+		return nil, fmt.Errorf("collect: root not directory: %s", root)
+	}
+	excludes := append([]string(nil), f.Exclude...)
+	if f.IgnoreFile != "" {
+		ps, err := readIgnoreFile(filepath.Join(root, f.IgnoreFile))
+		if err != nil {
+			return nil, fmt.Errorf("collect: %w", err)
+		}
+		excludes = append(excludes, ps...)
+	}
+	out := make([]string, 0, 256)
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, e error) error {
+		if e != nil {
+			return e
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if matchAny(excludes, rel, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if f.IgnoreFile != "" && rel == f.IgnoreFile {
+			return nil
+		}
+		if matchAny(excludes, rel, false) {
+			return nil
+		}
+		if len(f.Include) > 0 && !matchAny(f.Include, rel, false) {
+			return nil
+		}
+		if accept == nil || accept(p) {
+			out = append(out, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collect: walk: %w", err)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// readIgnoreFile：读取忽略文件中的模式；忽略空行与 # 注释；文件不存在时返回空集合。
+func readIgnoreFile(name string) ([]string, error) {
+	fh, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read ignore file: %w", err)
+	}
+	defer fh.Close()
+	var out []string
+	sc := bufio.NewScanner(fh)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out = append(out, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read ignore file: %w", err)
+	}
+	return out, nil
+}
+
+// matchAny：判断相对路径是否匹配任一模式。
+func matchAny(patterns []string, rel string, isDir bool) bool {
+	for _, p := range patterns {
+		if matchPattern(p, rel, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern：按 Filter 约定的语法匹配单个模式。
+// 算法说明：
+//  1. 以 / 结尾的模式仅对目录生效；
+//  2. 不含 / 的模式逐段匹配路径中的任意一段；
+//  3. 其余模式从根开始逐段匹配，** 可匹配零个或多个路径段。
+func matchPattern(pattern, rel string, isDir bool) bool {
+	pattern = filepath.ToSlash(strings.TrimSpace(pattern))
+	pattern = strings.TrimPrefix(pattern, "./")
+	if pattern == "" {
+		return false
+	}
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if dirOnly && !isDir {
+		return false
+	}
+	segs := strings.Split(rel, "/")
+	if !strings.Contains(pattern, "/") {
+		for _, s := range segs {
+			if ok, _ := path.Match(pattern, s); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return matchSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), segs)
+}
+
+// matchSegments：逐段匹配模式与路径；处理 ** 的回溯。
+func matchSegments(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(pat[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], segs[0]); !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
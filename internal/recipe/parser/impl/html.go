@@ -0,0 +1,105 @@
+// 文件功能：普通 HTML 菜谱页面转换；页面不含 schema.org Recipe JSON-LD 时，将标题与列表转换为 HowToCook 风格的 Markdown。
+package impl
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/textnorm"
+	"cook/internal/recipe/units"
+)
+
+var (
+	htmlDropRegex    = regexp.MustCompile(`(?is)<(script|style|noscript|template|svg)\b[^>]*>.*?</(?:script|style|noscript|template|svg)>|<!--.*?-->`)
+	htmlTitleRegex   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlHeadRegex    = regexp.MustCompile(`(?is)<head\b[^>]*>.*?</head>`)
+	htmlHeadingRegex = regexp.MustCompile(`(?is)<h([1-6])\b[^>]*>(.*?)</h[1-6]>`)
+	htmlItemRegex    = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	htmlBlockRegex   = regexp.MustCompile(`(?i)<br\s*/?>|</?(?:p|div|section|article|ul|ol|tr|table|dl|dt|dd|blockquote)\b[^>]*>|</li>`)
+	htmlAnyTagRegex  = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// htmlSectionAliases：常见网页章节标题对应的 HowToCook 章节（按检索键匹配标题是否包含别称）。
+var htmlSectionAliases = []struct {
+	section string
+	words   []string
+}{
+	{"必备原料和工具", []string{"原料", "用料", "食材", "材料", "配料", "ingredients"}},
+	{"计算", []string{"计算", "用量", "份量", "分量"}},
+	{"操作", []string{"做法", "步骤", "操作", "制作", "instructions", "directions", "method"}},
+	{"附加内容", []string{"小贴士", "贴士", "技巧", "注意", "附加", "tips", "notes"}},
+}
+
+// htmlToMarkdown：将普通 HTML 页面转换为 Markdown。
+// 算法说明：去掉脚本、样式与注释；h1～h6 转为同级 Markdown 标题，二级及以下标题命中 htmlSectionAliases 时改写为
+// HowToCook 章节名（统一为二级标题）；li 转为「- 」列表项，段落与换行类标签转为换行，其余标签删除，实体反转义。
+// 页面没有 h1 时以 <title> 作为一级标题。
+func htmlToMarkdown(s string) string {
+	s = htmlDropRegex.ReplaceAllString(s, "")
+	var title string
+	if m := htmlTitleRegex.FindStringSubmatch(s); m != nil {
+		title = plainText(m[1])
+	}
+	s = htmlHeadRegex.ReplaceAllString(s, "")
+	hasH1 := false
+	s = htmlHeadingRegex.ReplaceAllStringFunc(s, func(h string) string {
+		m := htmlHeadingRegex.FindStringSubmatch(h)
+		text := strings.Join(strings.Fields(plainText(m[2])), " ")
+		if text == "" {
+			return "\n"
+		}
+		level := int(m[1][0] - '0')
+		if level == 1 {
+			hasH1 = true
+		} else if sec := htmlSection(text); sec != "" {
+			level, text = 2, sec
+		}
+		return "\n\n" + strings.Repeat("#", level) + " " + text + "\n\n"
+	})
+	s = htmlItemRegex.ReplaceAllString(s, "\n- ")
+	s = htmlBlockRegex.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(htmlAnyTagRegex.ReplaceAllString(s, ""))
+	if !hasH1 && title != "" {
+		s = "# " + title + "\n\n" + s
+	}
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	s = multiBlankRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// htmlSection：标题对应的 HowToCook 章节名；不认识时返回空串。
+func htmlSection(title string) string {
+	key := textnorm.Fold(title)
+	for _, a := range htmlSectionAliases {
+		for _, w := range a.words {
+			if strings.Contains(key, w) {
+				return a.section
+			}
+		}
+	}
+	return ""
+}
+
+// htmlRecipe：由普通 HTML 页面生成菜谱；没有「计算」章节时从原料行识别用量；页面没有标题、原料与步骤时返回 nil。
+func htmlRecipe(b []byte) *parser.Recipe {
+	md := htmlToMarkdown(string(b))
+	if md == "" {
+		return nil
+	}
+	r := recipeFromMarkdown(md)
+	if r.Title == "" && len(r.Ingredients) == 0 && len(r.Steps) == 0 {
+		return nil
+	}
+	if len(r.Quantities) == 0 {
+		// 网页通常把用量写在原料行中（没有「计算」章节）。
+		for _, it := range r.Ingredients {
+			r.Quantities = append(r.Quantities, units.ExtractItem(it)...)
+		}
+	}
+	return r
+}
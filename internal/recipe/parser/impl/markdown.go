@@ -4,65 +4,46 @@ package impl
 
 import (
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"cook/internal/recipe/parser"
 	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/textnorm"
 )
 
 // MarkdownParser：将 Markdown 文件解析为若干 Chunk；支持按标题（Header）与按长度（Size）两种分块策略。
 // 业务背景：用于 RAG 数据准备阶段，将菜谱 Markdown 规范化、清洗并切分，以便后续 Embedding 与向量索引构建。
-type MarkdownParser struct{}
+//   - Filter：Collect 使用的 include/exclude 过滤规则。
+type MarkdownParser struct {
+	Filter parser.Filter // 文件收集过滤规则
+}
 
 // NewMarkdownParser：构造 MarkdownParser 实例。
-// 功能说明：返回一个无状态的解析器；方法可并发使用，但调用方需自行控制并发与 I/O。
+// 功能说明：返回使用默认过滤规则（排除 template/、读取 .recipeignore）的解析器；方法可并发使用，但调用方需自行控制并发与 I/O。
 // 参数说明：无。
 // 返回值说明：返回实现 Parser 的具体 Markdown 解析器。
 // 示例代码：
 //
-
-func NewMarkdownParser() *MarkdownParser { return &MarkdownParser{} }
+//	p := impl.NewMarkdownParser()
+//	p.Filter.Exclude = append(p.Filter.Exclude, "drink/")
+func NewMarkdownParser() *MarkdownParser { return &MarkdownParser{Filter: parser.DefaultFilter()} }
 
 // Collect：在指定根目录下收集全部 Markdown 文件路径。
-// 功能说明：递归遍历 root 目录；仅收集扩展名为 .md 的文件；对不可达或非法目录进行错误上抛（error wrapping）。
+// 功能说明：递归遍历 root 目录；仅收集扩展名为 .md 的文件；按 Filter 过滤并按路径排序；对不可达或非法目录进行错误上抛（error wrapping）。
 // 参数说明：
 //   - root：扫描的根目录路径；必须为存在且可读的目录；空字符串将报错。
 //
 // 返回值说明：
-//   - []string：匹配到的 Markdown 文件的绝对或相对路径集合；不保证排序稳定。
+//   - []string：匹配到的 Markdown 文件路径集合；按字典序排序，多次运行结果一致。
 //   - error：当 root 为空、非目录或遍历期间发生 I/O 错误时返回具体错误。
 func (p *MarkdownParser) Collect(root string) ([]string, error) {
-	if root == "" {
-		return nil, fmt.Errorf("collect: root is empty")
-	}
-	st, err := os.Stat(root)
-	if err != nil {
-		return nil, fmt.Errorf("collect: stat root: %w", err)
-	}
-	if !st.IsDir() {
-		return nil, fmt.Errorf("collect: root not directory: %s", root)
-	}
-	out := make([]string, 0, 256)
-	// 关键逻辑：使用 WalkDir 高效遍历目录树；过滤目录项；仅匹配 .md 扩展名（大小写不敏感）。
-	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, e error) error {
-		if e != nil {
-			return e
-		}
-		if d.IsDir() {
-			return nil
-		}
-		if mdExtRegex.MatchString(strings.ToLower(filepath.Ext(p))) {
-			out = append(out, p)
-		}
-		return nil
+	// 关键逻辑：仅匹配 .md 扩展名（大小写不敏感）；目录过滤与排序由 parser.Walk 统一处理。
+	return parser.Walk(root, p.Filter, func(path string) bool {
+		return mdExtRegex.MatchString(strings.ToLower(filepath.Ext(path)))
 	})
-	if err != nil {
-		return nil, fmt.Errorf("collect: walk: %w", err)
-	}
-	return out, nil
 }
 
 // ParseFiles：解析文件集合并输出 Chunk 列表。
@@ -72,11 +53,12 @@ func (p *MarkdownParser) Collect(root string) ([]string, error) {
 //
 // 参数说明：
 //   - paths：待解析的 Markdown 文件列表；为空时返回错误。
-//   - opts：解析选项，包含 ByHeader／ChunkSize／Overlap／Timestamp；其中：
+//   - opts：解析选项，包含 ByHeader／ChunkSize／Overlap／Timestamp／Root；其中：
 //   - ByHeader：是否按标题分块；为 false 时使用按长度分块；
 //   - ChunkSize：长度分块的最大字符数；≤0 时使用默认 1200；
 //   - Overlap：长度分块的重叠字符数；<0 视为 0；
-//   - Timestamp：source 字段是否附加 UTC 时间戳。
+//   - Timestamp：source 字段是否附加 UTC 时间戳；
+//   - Root：相对路径与分类的基准目录；为空时取全部输入的公共父目录。
 //
 // 返回值说明：
 //   - []types.Chunk：解析得到的分块列表；每个分块具备唯一 ID、索引、正文与元数据。
@@ -92,6 +74,13 @@ func (p *MarkdownParser) Collect(root string) ([]string, error) {
 //	if err != nil { /* 处理错误 */ }
 //	```
 func (p *MarkdownParser) ParseFiles(paths []string, opts types.Options) ([]types.Chunk, error) {
+	// 关键逻辑：统一清洗 Markdown 文本；剔除图片标记与 HTML 标签，统一换行并压缩空行；
+	return parseTextFiles(paths, opts, func(b []byte) string { return cleanMarkdown(string(b)) })
+}
+
+// parseTextFiles：文本类文档的通用解析流程；convert 负责将原始字节转换为带 Markdown 标题的规范文本。
+// 功能说明：补齐 Options 默认值；逐文件读取、计算相对路径与分类；按 ByHeader 选择分块策略并编号。
+func parseTextFiles(paths []string, opts types.Options, convert func([]byte) string) ([]types.Chunk, error) {
 	if len(paths) == 0 {
 		return nil, fmt.Errorf("parse: no input files")
 	}
@@ -105,6 +94,10 @@ func (p *MarkdownParser) ParseFiles(paths []string, opts types.Options) ([]types
 	if !opts.ByHeader && opts.ChunkSize < 200 {
 		opts.ChunkSize = 200
 	}
+	root := opts.Root
+	if root == "" {
+		root = parser.CommonRoot(paths)
+	}
 	chunks := make([]types.Chunk, 0, len(paths)*4)
 	docCounter := 0
 	chunkCounter := 0
@@ -114,24 +107,12 @@ func (p *MarkdownParser) ParseFiles(paths []string, opts types.Options) ([]types
 		if err != nil {
 			return nil, fmt.Errorf("parse: read %s: %w", pth, err)
 		}
-		rel := pth
-		// attempt to make path relative to root of first input
-		// 关键逻辑：相对路径标准化；以首个输入的父目录作为“公共根”，便于生成稳定的 source/path 元数据；
-		if root := commonRoot(paths); root != "" {
-			if absRoot, e := filepath.Abs(root); e == nil {
-				if absFile, e2 := filepath.Abs(pth); e2 == nil {
-					if strings.HasPrefix(absFile, absRoot) {
-						rel = strings.TrimPrefix(absFile, absRoot)
-						rel = strings.TrimPrefix(rel, string(filepath.Separator))
-					}
-				}
-			}
-		}
+		// 关键逻辑：相对路径标准化；以 opts.Root（默认为全部输入的公共父目录）为基准，便于生成稳定的 source/path 元数据；
+		rel := relPath(root, pth)
 		cat, name := extractCategoryName(rel)
 		docID := fmt.Sprintf("doc-%d", docCounter)
 		docCounter++
-		// 关键逻辑：统一清洗 Markdown 文本；剔除图片标记与 HTML 标签，统一换行并压缩空行；
-		text := cleanMarkdown(string(b))
+		text := convert(b)
 		var docChunks []types.Chunk
 		if opts.ByHeader {
 			// 算法说明（标题分块）：
@@ -146,6 +127,7 @@ func (p *MarkdownParser) ParseFiles(paths []string, opts types.Options) ([]types
 			//  3）Header 使用占位标记以体现块序号；
 			docChunks = splitBySize(text, docID, rel, cat, name, opts)
 		}
+		annotateDoc(docChunks, recipeFromMarkdown(text))
 		for i := range docChunks {
 			chunkCounter++
 			docChunks[i].ID = fmt.Sprintf("chunk-%d", chunkCounter)
@@ -170,6 +152,7 @@ func splitByHeaders(text, docID, rel, cat, name string, opts types.Options) []ty
 		}}
 	}
 	out := make([]types.Chunk, 0, len(idxs)+1)
+	section := ""
 	for i := 0; i < len(idxs); i++ {
 		start := idxs[i][0]
 		end := len(text)
@@ -179,6 +162,14 @@ func splitByHeaders(text, docID, rel, cat, name string, opts types.Options) []ty
 		seg := strings.TrimSpace(text[start:end])
 		headerLine := firstLine(seg)
 		body := strings.TrimSpace(strings.TrimPrefix(seg, headerLine))
+		// 关键逻辑：二级标题开启新章节，三级及以下标题沿用所属二级章节，一级标题无章节；
+		// 章节名作为分类键使用检索键形式（繁体折叠为简体），展示用的 Header 保留原文；
+		switch level, title := headerLevel(headerLine); {
+		case level == 1:
+			section = ""
+		case level == 2:
+			section = textnorm.Fold(title)
+		}
 		out = append(out, types.Chunk{
 			DocID:    docID,
 			Header:   strings.TrimSpace(headerLine),
@@ -187,6 +178,7 @@ func splitByHeaders(text, docID, rel, cat, name string, opts types.Options) []ty
 			Category: cat,
 			Name:     name,
 			Path:     rel,
+			Section:  section,
 		})
 	}
 	return out
@@ -233,10 +225,21 @@ func source(rel string, ts bool) string {
 	return rel
 }
 
-func commonRoot(paths []string) string {
-	if len(paths) == 0 {
-		return ""
+// relPath：计算 pth 相对 root 的路径；不在 root 之下时原样返回。
+func relPath(root, pth string) string {
+	if root == "" {
+		return pth
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return pth
+	}
+	absFile, err := filepath.Abs(pth)
+	if err != nil {
+		return pth
+	}
+	if !strings.HasPrefix(absFile, absRoot+string(filepath.Separator)) {
+		return pth
 	}
-	dir := filepath.Dir(paths[0])
-	return dir
+	return strings.TrimPrefix(absFile, absRoot+string(filepath.Separator))
 }
@@ -2,11 +2,13 @@
 package impl
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"cook/internal/recipe/parser"
 	"cook/internal/recipe/parser/types"
 )
 
@@ -39,6 +41,52 @@ func TestCollectAndParseByHeader(t *testing.T) {
 	}
 }
 
+func TestCollectFilterAndOrder(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"staple/b.md",
+		"aquatic/a.md",
+		"aquatic/draft.md",
+		"template/示例菜/示例菜.md",
+		"vegetable_dish/c.md",
+	}
+	for _, f := range files {
+		full := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte("# x"), 0o644); err != nil {
+			t.Fatalf("write md: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".recipeignore"), []byte("# drafts\ndraft.md\n"), 0o644); err != nil {
+		t.Fatalf("write ignore: %v", err)
+	}
+	p := NewMarkdownParser()
+	got, err := p.Collect(dir)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "aquatic", "a.md"),
+		filepath.Join(dir, "staple", "b.md"),
+		filepath.Join(dir, "vegetable_dish", "c.md"),
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("collect = %v, want %v", got, want)
+	}
+
+	p.Filter.Include = []string{"aquatic/**", "**/c.md"}
+	p.Filter.Exclude = append(p.Filter.Exclude, "vegetable_dish/")
+	got, err = p.Collect(dir)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("collect with include/exclude = %v", got)
+	}
+}
+
 func TestParseBySize(t *testing.T) {
 	dir := t.TempDir()
 	var sb strings.Builder
@@ -65,6 +113,112 @@ func TestParseBySize(t *testing.T) {
 	}
 }
 
+func TestCleanMarkdownStrayAngle(t *testing.T) {
+	in := "* 面条（二细<见下方）\n\n## 计算\n\n<b>面条</b> 300g <br/>"
+	want := "* 面条（二细<见下方）\n\n## 计算\n\n面条 300g"
+	if got := cleanMarkdown(in); got != want {
+		t.Fatalf("cleanMarkdown = %q, want %q", got, want)
+	}
+}
+
+func TestCleanMarkdownMultilineTag(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"## 操作\n\n<img\n  src=\"a.png\"\n  alt=\"成品\"\n>\n- 煮面", "## 操作\n\n- 煮面"},
+		{"<div\n  class=\"tip\">多放葱</div>", "多放葱"},
+		// 孤立的 < 后即使有字母，也不会跨过空行吞掉后续章节
+		{"* 水 <a few cups\n\n## 计算\n\n面 300g >", "* 水 <a few cups\n\n## 计算\n\n面 300g >"},
+	}
+	for _, c := range cases {
+		if got := cleanMarkdown(c.in); got != c.want {
+			t.Errorf("cleanMarkdown(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestRecipeFromTraditional(t *testing.T) {
+	md := "# 豉汁蒸白鯧的做法\n\n預估烹飪難度：★★\n\n## 必備原料和工具\n\n- 白鯧魚\n- 豆豉\n- 醬油\n\n## 計算\n\n一份正好夠 2 個人吃。\n\n## 操作\n\n- 蒸 8 分鐘"
+	r := recipeFromMarkdown(md)
+	if r.Title != "豉汁蒸白鯧" || r.Difficulty != "2" || r.Servings != 2 {
+		t.Fatalf("recipe: %+v", r)
+	}
+	if len(r.Ingredients) != 3 || r.Ingredients[0] != "白鯧魚" || len(r.Steps) != 1 {
+		t.Fatalf("sections: %q %q", r.Ingredients, r.Steps)
+	}
+	chunks := splitByHeaders(cleanMarkdown(md), "d", "aquatic/a.md", "aquatic", "a", types.Options{})
+	annotateDoc(chunks, r)
+	if chunks[1].Section != "必备原料和工具" || chunks[1].Header != "## 必備原料和工具" {
+		t.Fatalf("section: %q header: %q", chunks[1].Section, chunks[1].Header)
+	}
+	if got := strings.Join(chunks[0].Allergens, ","); got != "大豆,鱼类" {
+		t.Fatalf("allergens: %s", got)
+	}
+	c := chunks[2]
+	if c.Difficulty != 2 || c.Minutes != 8 || strings.Join(c.Diets, ",") != "无麸质" || strings.Join(c.Ingredients, ",") != "白鯧魚,豆豉,醬油" {
+		t.Fatalf("facets: difficulty %d minutes %d diets %q ingredients %q", c.Difficulty, c.Minutes, c.Diets, c.Ingredients)
+	}
+}
+
+func TestRecipeFacets(t *testing.T) {
+	cases := []struct {
+		r       parser.Recipe
+		diets   string
+		minutes int
+	}{
+		{parser.Recipe{Ingredients: []string{"番茄", "鸡蛋", "鸡精"}, Steps: []string{"炒 2 分钟", "焖半小时"}}, "素食,无麸质", 32},
+		{parser.Recipe{Ingredients: []string{"豆腐、青菜", "香菇（又称：冬菇）"}, Steps: []string{"炖 1~2 小时"}}, "素食,纯素,无麸质", 90},
+		{parser.Recipe{Ingredients: []string{"鸡胸肉", "面粉"}, CookingTime: "25 分钟", Steps: []string{"烤 40 分钟"}}, "", 25},
+		{parser.Recipe{Nutrition: map[string]string{"calories": "320 kcal", "fatContent": "8 g", "proteinContent": "28000 mg"}}, "低卡,低脂,高蛋白", 0},
+	}
+	for _, c := range cases {
+		r := c.r
+		diets := parser.DetectDiets(&r, parser.DetectAllergens(strings.Join(r.Ingredients, "\n")))
+		if got := strings.Join(diets, ","); got != c.diets {
+			t.Errorf("diets %q = %q, want %q", r.Ingredients, got, c.diets)
+		}
+		if got := parser.TotalMinutes(&r); got != c.minutes {
+			t.Errorf("minutes %q = %d, want %d", r.Steps, got, c.minutes)
+		}
+	}
+	if got := strings.Join(parser.IngredientNames([]string{"豆腐、青菜", "香菇（又称：冬菇）", "葱/姜 或 蒜"}), ","); got != "豆腐,青菜,冬菇,香菇,葱,姜,蒜" {
+		t.Errorf("ingredient names = %s", got)
+	}
+	if got := fmt.Sprint(parser.IngredientAliases([]string{"青蟹（别称：肉蟹）", "葱、香菇（又称：冬菇）", "咖喱块（推介乐惠）"})); got != "[[青蟹 肉蟹] [香菇 冬菇]]" {
+		t.Errorf("ingredient aliases = %s", got)
+	}
+}
+
+func TestRecipeQuantities(t *testing.T) {
+	md := "# 番茄炒蛋的做法\n\n## 计算\n\n每份：\n\n- 番茄 两个半\n- 鸡蛋 3~4 个\n- 盐 约 2g（可选，减少到 1 g）\n- 白糖 半勺 #不喜可不加\n\n## 操作\n\n- 打入一个鸡蛋，加入十几克清水\n- 翻炒一两分钟"
+	r := recipeFromMarkdown(md)
+	var got []string
+	for _, q := range r.Quantities {
+		got = append(got, fmt.Sprintf("%s %v~%v %s %t", q.Ingredient, q.Amount.Min, q.Amount.Max, q.Unit, q.Amount.Approx))
+	}
+	want := "番茄 2.5~2.5 个 false|鸡蛋 3~4 个 true|盐 2~2 g true|白糖 0.5~0.5 勺 false"
+	if strings.Join(got, "|") != want {
+		t.Fatalf("quantities: %q", got)
+	}
+	if len(r.StepQuantities) != 2 || len(r.StepQuantities[0]) != 2 || len(r.StepQuantities[1]) != 0 {
+		t.Fatalf("step quantities: %+v", r.StepQuantities)
+	}
+	if q := r.StepQuantities[0][1]; q.Text != "十几克" || q.Amount.Min != 11 || q.Amount.Max != 19 {
+		t.Fatalf("step quantity: %+v", q)
+	}
+}
+
+func TestRecipeStepHeat(t *testing.T) {
+	md := "# 炸鸡翅的做法\n\n## 操作\n\n- 鸡翅腌制 20 分钟\n- 中火烧油至七成热，下鸡翅炸至金黄\n- 转大火复炸 30 秒"
+	r := recipeFromMarkdown(md)
+	if len(r.StepHeat) != 3 || len(r.StepHeat[0]) != 0 || len(r.StepHeat[1]) != 2 || r.StepHeat[1][0].Level != "中火" || r.StepHeat[2][0].Level != "大火" {
+		t.Fatalf("step heat: %+v", r.StepHeat)
+	}
+	chunks := splitByHeaders(cleanMarkdown(md), "d", "meat_dish/a.md", "meat_dish", "a", types.Options{})
+	annotateDoc(chunks, r)
+	if got := strings.Join(chunks[0].Heat, ","); got != "中火,旺油,大火" {
+		t.Fatalf("chunk heat: %s", got)
+	}
+}
+
 func BenchmarkCleanMarkdown(b *testing.B) {
 	text := strings.Repeat("![](img) <b>x</b>  内容\n\n\n", 500)
 	b.ResetTimer()
@@ -0,0 +1,145 @@
+// 文件功能：从 HowToCook 风格 Markdown 中抽取结构化 Recipe（标题、难度、份量、原料、步骤、附加内容）。
+package impl
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cook/internal/recipe/heat"
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/textnorm"
+	"cook/internal/recipe/units"
+)
+
+var (
+	titleSuffixRegex = regexp.MustCompile(`的做法$`)
+	difficultyRegex  = regexp.MustCompile(`预估烹饪难度[：:]\s*(★+)`)
+	servingsRegex    = regexp.MustCompile(`一份[^。\n\d]{0,8}(\d+)(?:\s*[-~～]\s*\d+)?\s*个?人|(\d+)\s*人份`)
+	listItemRegex    = regexp.MustCompile(`^(?:[-*+]|\d+[.)、])\s+(.*)$`)
+)
+
+// boilerplateNote：HowToCook 每篇菜谱末尾保留的固定文字；不计入 Notes。
+const boilerplateNote = "如果您遵循本指南的制作流程而发现有问题或可以改进的流程，请提出 Issue 或 Pull request 。"
+
+// ParseRecipes：将 Markdown 文件解析为结构化 Recipe；Category/Path 以 opts.Root 为基准计算。
+func (p *MarkdownParser) ParseRecipes(paths []string, opts types.Options) ([]*parser.Recipe, error) {
+	return parseTextRecipes(paths, opts, func(b []byte) string { return string(b) })
+}
+
+// ParseRecipes：将纯文本文件解析为结构化 Recipe；段落标题识别规则同 ParseFiles。
+func (p *TextParser) ParseRecipes(paths []string, opts types.Options) ([]*parser.Recipe, error) {
+	return parseTextRecipes(paths, opts, func(b []byte) string { return textToMarkdown(string(b)) })
+}
+
+// parseTextRecipes：文本类文档的 Recipe 抽取流程；convert 将原始字节转换为 Markdown。
+func parseTextRecipes(paths []string, opts types.Options, convert func([]byte) string) ([]*parser.Recipe, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("parse: no input files")
+	}
+	root := opts.Root
+	if root == "" {
+		root = parser.CommonRoot(paths)
+	}
+	out := make([]*parser.Recipe, 0, len(paths))
+	for _, pth := range paths {
+		b, err := os.ReadFile(pth)
+		if err != nil {
+			return nil, fmt.Errorf("parse: read %s: %w", pth, err)
+		}
+		rel := relPath(root, pth)
+		cat, name := extractCategoryName(rel)
+		r := recipeFromMarkdown(convert(b))
+		if r.Title == "" {
+			r.Title = name
+		}
+		r.Category = cat
+		r.Path = rel
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// recipeFromMarkdown：按 HowToCook 模板的章节抽取 Recipe 字段。
+// 算法说明：
+//  1. 一级标题去掉「的做法」后作为 Title；
+//  2. 「预估烹饪难度：★★★」中星号个数作为 Difficulty；
+//  3. 「必备原料和工具」「操作」章节（含其下级标题）的列表项分别作为 Ingredients 与 Steps；
+//  4. 「计算」章节中「一份…N 个人」或「N 人份」作为 Servings；
+//  5. 「附加内容」去掉固定结尾后作为 Notes；
+//  6. 「计算」章节列表项与各步骤中的用量（含中文数字、分数、范围与约数）分别写入 Quantities 与 StepQuantities；
+//     各步骤的火候写入 StepHeat；
+//  7. 章节名与模板文字经 textnorm.Fold 折叠后匹配，兼容繁体与全角书写。
+func recipeFromMarkdown(md string) *parser.Recipe {
+	r := &parser.Recipe{RawMarkdown: md}
+	text := cleanMarkdown(md)
+	var section string
+	var notes, calc []string
+	for _, line := range strings.Split(text, "\n") {
+		if headerRegex.MatchString(line) {
+			level, title := headerLevel(line)
+			switch {
+			case level == 1 && r.Title == "":
+				r.Title = titleSuffixRegex.ReplaceAllString(title, "")
+			case level <= 2:
+				section = textnorm.Fold(title)
+			}
+			continue
+		}
+		// 关键逻辑：模板文字按检索键匹配，繁体或全角书写的菜谱同样能识别章节、难度与份量；抽取的字段保留原文；
+		key := textnorm.Fold(line)
+		if m := difficultyRegex.FindStringSubmatch(key); m != nil && r.Difficulty == "" {
+			r.Difficulty = strconv.Itoa(len([]rune(m[1])))
+		}
+		item := ""
+		if m := listItemRegex.FindStringSubmatch(line); m != nil {
+			item = strings.TrimSpace(m[1])
+		}
+		switch section {
+		case "必备原料和工具":
+			if item != "" {
+				r.Ingredients = append(r.Ingredients, item)
+			}
+		case "操作":
+			if item != "" {
+				r.Steps = append(r.Steps, item)
+			}
+		case "计算":
+			if item != "" {
+				calc = append(calc, item)
+			}
+			if m := servingsRegex.FindStringSubmatch(key); m != nil && r.Servings == 0 {
+				r.Servings, _ = strconv.Atoi(m[1] + m[2])
+			}
+		case "附加内容":
+			if line != "" && key != boilerplateNote {
+				notes = append(notes, line)
+			}
+		}
+	}
+	r.Notes = strings.Join(notes, "\n")
+	extractQuantities(r, calc)
+	return r
+}
+
+// extractQuantities：从原料行与步骤中识别用量，并识别各步骤的火候。
+// 参数说明：
+//   - r：已填充 Steps 的菜谱；
+//   - items：原料行（HowToCook「计算」章节的列表项或 schema.org recipeIngredient）。
+func extractQuantities(r *parser.Recipe, items []string) {
+	for _, it := range items {
+		r.Quantities = append(r.Quantities, units.ExtractItem(it)...)
+	}
+	if len(r.Steps) == 0 {
+		return
+	}
+	r.StepQuantities = make([][]units.Quantity, len(r.Steps))
+	r.StepHeat = make([][]heat.Heat, len(r.Steps))
+	for i, s := range r.Steps {
+		r.StepQuantities[i] = units.Extract(s)
+		r.StepHeat[i] = heat.Extract(s)
+	}
+}
@@ -0,0 +1,48 @@
+// 文件功能：向默认解析器注册表登记本包提供的格式；导入本包即可启用 Markdown、纯文本与 schema.org Recipe 解析。
+package impl
+
+import (
+	"bytes"
+
+	"cook/internal/recipe/parser"
+)
+
+func init() {
+	parser.Register(parser.Format{
+		Name:       "markdown",
+		Extensions: []string{".md", ".markdown"},
+		Sniff:      sniffMarkdown,
+		New:        func() parser.Parser { return NewMarkdownParser() },
+	})
+	parser.Register(parser.Format{
+		Name:       "text",
+		Extensions: []string{".txt"},
+		New:        func() parser.Parser { return NewTextParser() },
+	})
+	parser.Register(parser.Format{
+		Name:       "schemaorg",
+		Extensions: []string{".html", ".htm", ".json"},
+		Sniff:      sniffSchemaOrg,
+		New:        func() parser.Parser { return NewSchemaOrgParser() },
+	})
+}
+
+// sniffSchemaOrg：头部包含 JSON-LD 脚本或 schema.org 上下文声明时按 schema.org Recipe 处理。
+func sniffSchemaOrg(_ string, head []byte) bool {
+	return bytes.Contains(head, []byte("application/ld+json")) ||
+		(bytes.Contains(head, []byte(`"@context"`)) && bytes.Contains(head, []byte("schema.org")))
+}
+
+// sniffMarkdown：无扩展名文件若以 Markdown 标题开头则按 Markdown 处理。
+func sniffMarkdown(_ string, head []byte) bool {
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	return headerRegex.Match(firstLineBytes(bytes.TrimSpace(head)))
+}
+
+// firstLineBytes：返回字节切片的首行。
+func firstLineBytes(b []byte) []byte {
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		return b[:i]
+	}
+	return b
+}
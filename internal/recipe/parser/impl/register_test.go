@@ -0,0 +1,101 @@
+// 文件功能：默认注册表的分派测试；验证混合格式目录被统一收集、解析与编号。
+package impl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/parser/types"
+)
+
+func TestRegistryMixedFormats(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"meat_dish/a.md":    "# 红烧肉的做法\n简介\n\n## 操作\n- 炖",
+		"meat_dish/b.txt":   "可乐鸡翅\n\n【材料】\n鸡翅 10 个\n\n做法：\n焯水后加可乐收汁",
+		"meat_dish/c":       "# 无扩展名\n内容",
+		"meat_dish/pic.jpg": "version https://git-lfs.github.com/spec/v1\n",
+		"meat_dish/x.yaml":  "# 配置\nname: 红烧肉",
+		"meat_dish/y.toml":  "# 配置\nname = \"红烧肉\"",
+		"meat_dish/LICENSE": "The Unlicense\n\nThis is free software.",
+	}
+	for name, body := range files {
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	reg := parser.Default()
+	paths, err := reg.Collect(dir)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if len(paths) != 3 { // 未注册扩展名与不像 Markdown 的无扩展名文件被跳过
+		t.Fatalf("expect 3 files, got %v", paths)
+	}
+	chunks, err := reg.ParseFiles(paths, types.Options{ByHeader: true, Root: dir})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	// a.md: 2 个分块；b.txt: 标题 + 材料 + 做法 3 个分块；c: 1 个分块
+	if len(chunks) != 6 {
+		t.Fatalf("expect 6 chunks, got %d: %+v", len(chunks), chunks)
+	}
+	seen := map[string]bool{}
+	for i, c := range chunks {
+		if seen[c.ID] {
+			t.Fatalf("duplicate chunk id %s", c.ID)
+		}
+		seen[c.ID] = true
+		if c.Category != "meat_dish" {
+			t.Fatalf("chunk %d category = %q", i, c.Category)
+		}
+	}
+	if chunks[2].DocID != "doc-1" || chunks[3].Header != "## 材料" {
+		t.Fatalf("unexpected text chunks: %+v", chunks[2:5])
+	}
+}
+
+func TestRegistrySkipsBadJSON(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"app/good.json":    `{"@context":"https://schema.org","@type":"Recipe","name":"蒸蛋","recipeInstructions":"打蛋加水蒸十分钟"}`,
+		"app/broken.json":  `{"@type":"Recipe","name":`,
+		"app/package.json": `{"name":"not-a-recipe","version":"1.0.0"}`,
+		"app/d.md":         "# 炒青菜的做法\n\n## 操作\n- 大火快炒",
+	}
+	for name, body := range files {
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	reg := parser.Default()
+	paths, err := reg.Collect(dir)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	chunks, err := reg.ParseFiles(paths, types.Options{ByHeader: true, Root: dir})
+	if err != nil {
+		t.Fatalf("parse aborted on a bad json file: %v", err)
+	}
+	names := map[string]bool{}
+	for _, c := range chunks {
+		names[c.Name] = true
+	}
+	if len(names) != 2 || !names["蒸蛋"] || !names["d"] {
+		t.Fatalf("expect chunks from good.json and d.md only, got %v", names)
+	}
+	recipes, err := reg.ParseRecipes(paths, types.Options{Root: dir})
+	if err != nil || len(recipes) != 2 {
+		t.Fatalf("recipes = %d, %v", len(recipes), err)
+	}
+}
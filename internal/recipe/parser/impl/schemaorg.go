@@ -0,0 +1,468 @@
+// 文件功能：schema.org Recipe（JSON-LD）解析器；从本地 .html/.json 文件提取 Recipe 结构并按 HowToCook 章节布局生成 Chunk；
+// 不含 JSON-LD 的网页按普通 HTML 转换。
+package impl
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/parser/types"
+)
+
+var (
+	schemaExtRegex = regexp.MustCompile(`(?i)\.(html?|json)$`)
+	ldScriptRegex  = regexp.MustCompile(`(?is)<script[^>]*type\s*=\s*["']?application/ld\+json["']?[^>]*>(.*?)</script>`)
+	isoDurRegex    = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+	firstIntRegex  = regexp.MustCompile(`\d+`)
+	blockTagRegex  = regexp.MustCompile(`(?i)<br\s*/?>|</(?:p|li|div)>`)
+)
+
+// nutritionLabels：schema.org NutritionInformation 字段与中文名称；顺序即输出顺序。
+var nutritionLabels = []struct{ Key, Label string }{
+	{"servingSize", "每份"},
+	{"calories", "热量"},
+	{"proteinContent", "蛋白质"},
+	{"fatContent", "脂肪"},
+	{"saturatedFatContent", "饱和脂肪"},
+	{"transFatContent", "反式脂肪"},
+	{"carbohydrateContent", "碳水化合物"},
+	{"sugarContent", "糖"},
+	{"fiberContent", "膳食纤维"},
+	{"cholesterolContent", "胆固醇"},
+	{"sodiumContent", "钠"},
+}
+
+// SchemaOrgParser：解析嵌入 schema.org Recipe JSON-LD 的网页（.html/.htm）与应用导出文件（.json）；
+// 不含 JSON-LD Recipe 的网页按普通 HTML 页面处理（标题与列表转换为章节）。
+// 业务背景：用于将非 HowToCook 来源的菜谱并入语料；输出的 Chunk 与 Markdown 解析器使用相同的章节标题，便于统一检索。
+//   - Filter：Collect 使用的 include/exclude 过滤规则。
+type SchemaOrgParser struct {
+	Filter parser.Filter // 文件收集过滤规则
+}
+
+// NewSchemaOrgParser：构造使用默认过滤规则的 SchemaOrgParser。
+func NewSchemaOrgParser() *SchemaOrgParser { return &SchemaOrgParser{Filter: parser.DefaultFilter()} }
+
+// Collect：在指定根目录下收集 .html/.htm/.json 文件路径；过滤与排序规则同 MarkdownParser.Collect。
+func (p *SchemaOrgParser) Collect(root string) ([]string, error) {
+	return parser.Walk(root, p.Filter, func(path string) bool {
+		return schemaExtRegex.MatchString(path)
+	})
+}
+
+// ParseFiles：解析文件集合中的全部 schema.org Recipe 并输出 Chunk 列表。
+// 功能说明：每个 Recipe 视为一篇文档（同一页面可包含多篇）；Recipe 先渲染为 HowToCook 风格的 Markdown，
+// 再按 Options 复用标题/长度分块；Name 取菜谱名称，缺失时回退为文件名；JSON 非法或不含 Recipe 的 .json 文件与
+// 转换后既无标题也无正文的网页被跳过并记录日志。
+// 返回值说明：
+//   - []types.Chunk：解析得到的分块列表；
+//   - error：参数非法或读取失败时返回错误；JSON 非法或不含 Recipe 的文件记录日志后跳过。
+func (p *SchemaOrgParser) ParseFiles(paths []string, opts types.Options) ([]types.Chunk, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("parse: no input files")
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = 1200
+	}
+	if opts.Overlap < 0 {
+		opts.Overlap = 0
+	}
+	if !opts.ByHeader && opts.ChunkSize < 200 {
+		opts.ChunkSize = 200
+	}
+	root := opts.Root
+	if root == "" {
+		root = parser.CommonRoot(paths)
+	}
+	chunks := make([]types.Chunk, 0, len(paths)*4)
+	docCounter := 0
+	for _, pth := range paths {
+		recipes, err := p.parseFile(pth)
+		if err != nil {
+			return nil, err
+		}
+		rel := relPath(root, pth)
+		cat, fileName := extractCategoryName(rel)
+		for _, r := range recipes {
+			name := r.Title
+			if name == "" {
+				name = fileName
+			}
+			docID := fmt.Sprintf("doc-%d", docCounter)
+			docCounter++
+			text := cleanMarkdown(r.RawMarkdown)
+			var docChunks []types.Chunk
+			if opts.ByHeader {
+				docChunks = splitByHeaders(text, docID, rel, cat, name, opts)
+			} else {
+				docChunks = splitBySize(text, docID, rel, cat, name, opts)
+			}
+			annotateDoc(docChunks, r)
+			for i := range docChunks {
+				docChunks[i].ID = fmt.Sprintf("chunk-%d", len(chunks)+1)
+				docChunks[i].Index = i
+				chunks = append(chunks, docChunks[i])
+			}
+		}
+	}
+	return chunks, nil
+}
+
+// ParseRecipes：将文件集合中的 schema.org Recipe 映射为 parser.Recipe；RawMarkdown 为渲染后的 HowToCook 风格文本。
+func (p *SchemaOrgParser) ParseRecipes(paths []string, opts types.Options) ([]*parser.Recipe, error) {
+	root := opts.Root
+	if root == "" {
+		root = parser.CommonRoot(paths)
+	}
+	var out []*parser.Recipe
+	for _, pth := range paths {
+		rs, err := p.parseFile(pth)
+		if err != nil {
+			return nil, err
+		}
+		rel := relPath(root, pth)
+		cat, name := extractCategoryName(rel)
+		for _, r := range rs {
+			if r.Title == "" {
+				r.Title = name
+			}
+			r.Category = cat
+			r.Path = rel
+		}
+		out = append(out, rs...)
+	}
+	return out, nil
+}
+
+// parseFile：读取单个文件并提取其中全部 Recipe；网页不含 JSON-LD Recipe 时回退为普通 HTML 转换，仍无结果时记录日志并返回空。
+// 非法的 JSON（.json 文件或网页中的脚本块）记录日志后跳过；只有读取失败返回错误。
+func (p *SchemaOrgParser) parseFile(pth string) ([]*parser.Recipe, error) {
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, fmt.Errorf("parse: read %s: %w", pth, err)
+	}
+	var blocks []string
+	isJSON := strings.EqualFold(filepath.Ext(pth), ".json")
+	if isJSON {
+		blocks = []string{string(b)}
+	} else {
+		for _, m := range ldScriptRegex.FindAllStringSubmatch(string(b), -1) {
+			blocks = append(blocks, m[1])
+		}
+	}
+	var out []*parser.Recipe
+	malformed := false
+	for _, blk := range blocks {
+		var v any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(blk)), &v); err != nil {
+			// 关键逻辑：JSON 非法只跳过该文件（网页跳过该脚本块），不中止整批解析；
+			log.Printf("parse: skip malformed json-ld in %s: %v", pth, err)
+			malformed = true
+			continue
+		}
+		for _, obj := range findRecipes(v) {
+			out = append(out, mapSchemaRecipe(obj))
+		}
+	}
+	if len(out) == 0 && !isJSON {
+		// 关键逻辑：没有 JSON-LD Recipe 的网页按普通 HTML 转换，标题与列表映射为 HowToCook 章节；
+		if r := htmlRecipe(b); r != nil {
+			out = append(out, r)
+		}
+	}
+	if len(out) == 0 && !malformed {
+		log.Printf("parse: skip %s: no recipe found", pth)
+	}
+	return out, nil
+}
+
+// findRecipes：递归查找 @type 为 Recipe 的对象；覆盖顶层数组、@graph 与 mainEntity 等嵌套写法。
+func findRecipes(v any) []map[string]any {
+	switch t := v.(type) {
+	case []any:
+		var out []map[string]any
+		for _, e := range t {
+			out = append(out, findRecipes(e)...)
+		}
+		return out
+	case map[string]any:
+		if hasType(t, "Recipe") {
+			return []map[string]any{t}
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var out []map[string]any
+		for _, k := range keys {
+			out = append(out, findRecipes(t[k])...)
+		}
+		return out
+	}
+	return nil
+}
+
+// hasType：判断 JSON-LD 对象的 @type（字符串或数组）是否包含 want。
+func hasType(obj map[string]any, want string) bool {
+	for _, t := range stringList(obj["@type"]) {
+		if t == want || strings.HasSuffix(t, "/"+want) {
+			return true
+		}
+	}
+	return false
+}
+
+// mapSchemaRecipe：将 schema.org Recipe 对象映射为 parser.Recipe，并渲染 RawMarkdown。
+func mapSchemaRecipe(obj map[string]any) *parser.Recipe {
+	r := &parser.Recipe{
+		Title:       plainText(firstString(obj["name"])),
+		Notes:       plainText(firstString(obj["description"])),
+		Ingredients: plainList(obj["recipeIngredient"]),
+		Servings:    parseYield(obj["recipeYield"]),
+		Nutrition:   parseNutrition(obj["nutrition"]),
+	}
+	if len(r.Ingredients) == 0 {
+		r.Ingredients = plainList(obj["ingredients"])
+	}
+	for _, key := range []string{"totalTime", "cookTime", "prepTime"} {
+		if d, ok := parseISODuration(firstString(obj[key])); ok {
+			r.CookingTime = formatMinutes(d)
+			break
+		}
+	}
+	seen := map[string]bool{}
+	for _, key := range []string{"recipeCategory", "recipeCuisine", "keywords"} {
+		for _, t := range stringList(obj[key]) {
+			for _, s := range strings.Split(t, ",") {
+				if s = strings.TrimSpace(plainText(s)); s != "" && !seen[s] {
+					seen[s] = true
+					r.Tags = append(r.Tags, s)
+				}
+			}
+		}
+	}
+	sections := parseInstructions(obj["recipeInstructions"])
+	for _, sec := range sections {
+		r.Steps = append(r.Steps, sec.steps...)
+	}
+	extractQuantities(r, r.Ingredients)
+	r.RawMarkdown = renderSchemaMarkdown(r, sections)
+	return r
+}
+
+// instructionSection：HowToSection 分组的步骤；name 为空表示未分组。
+type instructionSection struct {
+	name  string
+	steps []string
+}
+
+// parseInstructions：解析 recipeInstructions 的多种写法（纯文本、字符串数组、HowToStep、HowToSection）。
+func parseInstructions(v any) []instructionSection {
+	var out []instructionSection
+	cur := instructionSection{}
+	flush := func() {
+		if len(cur.steps) > 0 {
+			out = append(out, cur)
+		}
+		cur = instructionSection{}
+	}
+	var walk func(v any)
+	walk = func(v any) {
+		switch t := v.(type) {
+		case string:
+			for _, line := range strings.Split(plainText(t), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					cur.steps = append(cur.steps, line)
+				}
+			}
+		case []any:
+			for _, e := range t {
+				walk(e)
+			}
+		case map[string]any:
+			if hasType(t, "HowToSection") {
+				flush()
+				cur.name = plainText(firstString(t["name"]))
+				walk(t["itemListElement"])
+				flush()
+				return
+			}
+			if s := firstString(t["text"]); s != "" {
+				walk(s)
+			} else if s := firstString(t["name"]); s != "" {
+				walk(s)
+			} else {
+				walk(t["itemListElement"])
+			}
+		}
+	}
+	walk(v)
+	flush()
+	return out
+}
+
+// renderSchemaMarkdown：按 HowToCook 章节布局渲染 Markdown，使分块标题与 Markdown 语料一致。
+func renderSchemaMarkdown(r *parser.Recipe, sections []instructionSection) string {
+	var sb strings.Builder
+	title := r.Title
+	if title == "" {
+		title = "未命名菜谱"
+	}
+	fmt.Fprintf(&sb, "# %s的做法\n\n", title)
+	if r.Notes != "" {
+		sb.WriteString(r.Notes + "\n\n")
+	}
+	if r.CookingTime != "" {
+		fmt.Fprintf(&sb, "预计制作时长：%s\n\n", r.CookingTime)
+	}
+	if len(r.Tags) > 0 {
+		fmt.Fprintf(&sb, "标签：%s\n\n", strings.Join(r.Tags, "、"))
+	}
+	if len(r.Ingredients) > 0 {
+		sb.WriteString("## 必备原料和工具\n\n")
+		for _, ing := range r.Ingredients {
+			sb.WriteString("- " + ing + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	if r.Servings > 0 {
+		fmt.Fprintf(&sb, "## 计算\n\n总量可供 %d 人份食用。\n\n", r.Servings)
+	}
+	if len(sections) > 0 {
+		sb.WriteString("## 操作\n\n")
+		for _, sec := range sections {
+			if sec.name != "" {
+				fmt.Fprintf(&sb, "### %s\n\n", sec.name)
+			}
+			for _, st := range sec.steps {
+				sb.WriteString("- " + st + "\n")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if len(r.Nutrition) > 0 {
+		sb.WriteString("## 营养成分\n\n")
+		for _, nl := range nutritionLabels {
+			if v, ok := r.Nutrition[nl.Key]; ok {
+				fmt.Fprintf(&sb, "- %s：%s\n", nl.Label, v)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// parseNutrition：提取 NutritionInformation 中已知字段；数值统一转为字符串。
+func parseNutrition(v any) map[string]string {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	out := make(map[string]string)
+	for _, nl := range nutritionLabels {
+		if s := plainText(firstString(obj[nl.Key])); s != "" {
+			out[nl.Key] = s
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// parseYield：从 recipeYield（数字、"4 servings"、数组）中提取份数；无法识别返回 0。
+func parseYield(v any) int {
+	for _, s := range stringList(v) {
+		if m := firstIntRegex.FindString(s); m != "" {
+			if n, err := strconv.Atoi(m); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// parseISODuration：解析 ISO 8601 时长（如 PT1H30M、P0DT45M）。
+func parseISODuration(s string) (time.Duration, bool) {
+	m := isoDurRegex.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil || s == "" {
+		return 0, false
+	}
+	var d time.Duration
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	for i, u := range units {
+		if m[i+1] == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(m[i+1], 64)
+		if err != nil {
+			return 0, false
+		}
+		d += time.Duration(f * float64(u))
+	}
+	return d, d > 0
+}
+
+// formatMinutes：将时长格式化为「N 分钟」。
+func formatMinutes(d time.Duration) string {
+	return fmt.Sprintf("%d 分钟", int(d.Round(time.Minute)/time.Minute))
+}
+
+// firstString：取 JSON 值的首个字符串表示（字符串、数字或数组首元素）。
+func firstString(v any) string {
+	if l := stringList(v); len(l) > 0 {
+		return l[0]
+	}
+	return ""
+}
+
+// stringList：将字符串、数字、对象（取 name/@value）或其数组统一转换为字符串切片。
+func stringList(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case float64:
+		return []string{strconv.FormatFloat(t, 'f', -1, 64)}
+	case []any:
+		var out []string
+		for _, e := range t {
+			out = append(out, stringList(e)...)
+		}
+		return out
+	case map[string]any:
+		for _, k := range []string{"@value", "name", "text"} {
+			if s, ok := t[k].(string); ok {
+				return []string{s}
+			}
+		}
+	}
+	return nil
+}
+
+// plainList：stringList 后逐项清理 HTML 并去除空项。
+func plainList(v any) []string {
+	var out []string
+	for _, s := range stringList(v) {
+		if s = plainText(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// plainText：反转义 HTML 实体、块级标签转换为换行、去除其余标签与首尾空白。
+func plainText(s string) string {
+	s = blockTagRegex.ReplaceAllString(s, "\n")
+	s = htmlTagRegex.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
@@ -0,0 +1,132 @@
+// 文件功能：schema.org Recipe 解析器的单元测试；覆盖 @graph 嵌套、HowToSection 与营养成分映射。
+package impl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cook/internal/recipe/parser/types"
+)
+
+const schemaPage = `<html><head>
+<script type="application/ld+json">
+{"@context":"https://schema.org","@graph":[
+  {"@type":"WebPage","name":"页面"},
+  {"@type":"Recipe","name":"番茄炒蛋","description":"家常&amp;快手",
+   "recipeYield":["2","2 人份"],"totalTime":"PT15M","keywords":"快手, 下饭",
+   "recipeIngredient":["番茄 2 个","鸡蛋 3 个"],
+   "recipeInstructions":[
+     {"@type":"HowToSection","name":"准备","itemListElement":[{"@type":"HowToStep","text":"番茄切块"}]},
+     {"@type":"HowToStep","text":"炒蛋<br>加番茄"}],
+   "nutrition":{"@type":"NutritionInformation","calories":"210 kcal","proteinContent":"12 g"}}
+]}
+</script></head><body></body></html>`
+
+func TestSchemaOrgParse(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "web", "page.html")
+	if err := os.MkdirAll(filepath.Dir(pth), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(pth, []byte(schemaPage), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	p := NewSchemaOrgParser()
+	recipes, err := p.ParseRecipes([]string{pth}, types.Options{Root: dir})
+	if err != nil {
+		t.Fatalf("parse recipes: %v", err)
+	}
+	if len(recipes) != 1 {
+		t.Fatalf("expect 1 recipe, got %d", len(recipes))
+	}
+	r := recipes[0]
+	if r.Title != "番茄炒蛋" || r.Notes != "家常&快手" || r.Servings != 2 || r.CookingTime != "15 分钟" {
+		t.Fatalf("unexpected recipe: %+v", r)
+	}
+	if strings.Join(r.Steps, "|") != "番茄切块|炒蛋|加番茄" {
+		t.Fatalf("steps = %q", r.Steps)
+	}
+	if r.Category != "web" || r.Path != filepath.Join("web", "page.html") {
+		t.Fatalf("unexpected location: %q %q", r.Category, r.Path)
+	}
+	if r.Nutrition["calories"] != "210 kcal" || len(r.Tags) != 2 {
+		t.Fatalf("nutrition/tags = %v %v", r.Nutrition, r.Tags)
+	}
+
+	chunks, err := p.ParseFiles([]string{pth}, types.Options{ByHeader: true, Root: dir})
+	if err != nil {
+		t.Fatalf("parse files: %v", err)
+	}
+	var headers []string
+	for _, c := range chunks {
+		headers = append(headers, c.Header)
+		if c.Name != "番茄炒蛋" || c.Category != "web" {
+			t.Fatalf("unexpected metadata: %+v", c)
+		}
+	}
+	want := "# 番茄炒蛋的做法|## 必备原料和工具|## 计算|## 操作|### 准备|## 营养成分"
+	if strings.Join(headers, "|") != want {
+		t.Fatalf("headers = %q", headers)
+	}
+}
+
+const plainPage = `<!DOCTYPE html><html><head><title>站点 - 红烧肉</title><style>h1{color:red}</style></head>
+<body><nav><a href="/">首页</a></nav>
+<h1 class="title">红烧肉的做法</h1>
+<p>肥而不腻，入口即化。</p>
+<h2>用料 Ingredients</h2>
+<ul><li>五花肉 500g</li><li>冰糖 <b>30g</b></li></ul>
+<h3>做法步骤</h3>
+<ol><li>五花肉切块焯水</li><li>小火炒糖色 &amp; 下肉翻炒</li></ol>
+<script>var x = "<li>不是步骤</li>";</script>
+</body></html>`
+
+func TestSchemaOrgPlainHTML(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "web", "hongshao.html")
+	empty := filepath.Join(dir, "web", "empty.html")
+	if err := os.MkdirAll(filepath.Dir(pth), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(pth, []byte(plainPage), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(empty, []byte("<html><body><script>x()</script></body></html>"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	p := NewSchemaOrgParser()
+	recipes, err := p.ParseRecipes([]string{pth, empty}, types.Options{Root: dir})
+	if err != nil {
+		t.Fatalf("parse recipes: %v", err)
+	}
+	if len(recipes) != 1 {
+		t.Fatalf("expect 1 recipe (empty page skipped), got %d", len(recipes))
+	}
+	r := recipes[0]
+	if r.Title != "红烧肉" || strings.Join(r.Ingredients, "|") != "五花肉 500g|冰糖 30g" {
+		t.Fatalf("unexpected recipe: %q %q", r.Title, r.Ingredients)
+	}
+	if strings.Join(r.Steps, "|") != "五花肉切块焯水|小火炒糖色 & 下肉翻炒" {
+		t.Fatalf("steps = %q", r.Steps)
+	}
+	if len(r.Quantities) != 2 {
+		t.Fatalf("quantities = %+v", r.Quantities)
+	}
+
+	chunks, err := p.ParseFiles([]string{pth, empty}, types.Options{ByHeader: true, Root: dir})
+	if err != nil {
+		t.Fatalf("parse files: %v", err)
+	}
+	var headers []string
+	for _, c := range chunks {
+		headers = append(headers, c.Header)
+		if c.Name != "红烧肉" || c.Category != "web" {
+			t.Fatalf("unexpected metadata: %+v", c)
+		}
+	}
+	if want := "# 红烧肉的做法|## 必备原料和工具|## 操作"; strings.Join(headers, "|") != want {
+		t.Fatalf("headers = %q", headers)
+	}
+}
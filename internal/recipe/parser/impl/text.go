@@ -0,0 +1,67 @@
+// 文件功能：纯文本菜谱解析器；识别常见的段落标题写法并转换为 Markdown 标题，复用 Markdown 的分块流程。
+package impl
+
+import (
+	"regexp"
+	"strings"
+
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/parser/types"
+)
+
+var (
+	txtExtRegex = regexp.MustCompile(`(?i)\.txt$`)
+	// txtHeadingRegex：纯文本中的段落标题，如「【做法】」「材料：」「一、准备」。
+	txtHeadingRegex = regexp.MustCompile(`^(?:【([^】]{1,20})】|([一二三四五六七八九十]+[、.．][^，。,.]{1,20})|([^\s，。,.：:]{1,12})[：:])$`)
+)
+
+// TextParser：将纯文本（.txt）菜谱解析为 Chunk。
+// 业务背景：部分菜谱来源为聊天记录或笔记导出的纯文本；首个非空行视为菜名，段落标题转换为二级标题后按 Markdown 规则分块。
+//   - Filter：Collect 使用的 include/exclude 过滤规则。
+type TextParser struct {
+	Filter parser.Filter // 文件收集过滤规则
+}
+
+// NewTextParser：构造使用默认过滤规则的 TextParser。
+func NewTextParser() *TextParser { return &TextParser{Filter: parser.DefaultFilter()} }
+
+// Collect：在指定根目录下收集全部 .txt 文件路径；过滤与排序规则同 MarkdownParser.Collect。
+func (p *TextParser) Collect(root string) ([]string, error) {
+	return parser.Walk(root, p.Filter, func(path string) bool {
+		return txtExtRegex.MatchString(path)
+	})
+}
+
+// ParseFiles：解析纯文本文件集合并输出 Chunk 列表；Options 语义同 MarkdownParser.ParseFiles。
+func (p *TextParser) ParseFiles(paths []string, opts types.Options) ([]types.Chunk, error) {
+	return parseTextFiles(paths, opts, func(b []byte) string { return textToMarkdown(string(b)) })
+}
+
+// textToMarkdown：将纯文本转换为 Markdown 结构。
+// 算法说明：
+//  1. 统一换行并按行去除首尾空白；
+//  2. 首个非空行作为一级标题；
+//  3. 命中 txtHeadingRegex 的行转换为二级标题，其余行原样保留；
+//  4. 结果再经 cleanMarkdown 压缩空行。
+func textToMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	lines := strings.Split(s, "\n")
+	titled := false
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case !titled:
+			line = "# " + line
+			titled = true
+		case headerRegex.MatchString(line):
+		default:
+			if m := txtHeadingRegex.FindStringSubmatch(line); m != nil {
+				line = "## " + m[1] + m[2] + m[3]
+			}
+		}
+		lines[i] = line
+	}
+	return cleanMarkdown(strings.Join(lines, "\n"))
+}
@@ -5,14 +5,19 @@ package impl
 import (
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+
+	"cook/internal/recipe/heat"
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/parser/types"
 )
 
 var (
 	mdExtRegex      = regexp.MustCompile(`(?i)\.md$`)
 	headerRegex     = regexp.MustCompile(`(?m)^#{1,6}\s+.*$`)
 	imageMdRegex    = regexp.MustCompile(`!\[[^\]]*\]\([^\)]*\)`)
-	htmlTagRegex    = regexp.MustCompile(`<[^>]+>`)
+	htmlTagRegex    = regexp.MustCompile(`</?[A-Za-z!](?:[^<>\n]|\n[^<>\n])*\n?>`) // 标签可跨行但不跨空行，避免正文中孤立的 < 吞掉后续段落
 	multiBlankRegex = regexp.MustCompile(`\n{3,}`)
 )
 
@@ -66,3 +71,33 @@ func extractCategoryName(rel string) (string, string) {
 	}
 	return "", ""
 }
+
+// headerLevel：返回 Markdown 标题行的级别与去除 # 后的标题文本。
+func headerLevel(line string) (int, string) {
+	line = strings.TrimSpace(line)
+	title := strings.TrimLeft(line, "#")
+	return len(line) - len(title), strings.TrimSpace(title)
+}
+
+// annotateDoc：将文档级元数据（份量、营养成分、过敏原、难度、总时长、饮食标签、原料名、步骤火候）写入该文档的全部分块。
+// 参数：
+//   - chunks：同一文档的分块；
+//   - r：由该文档抽取的结构化菜谱；过敏原、饮食标签与原料名基于其原料清单识别。
+func annotateDoc(chunks []types.Chunk, r *parser.Recipe) {
+	allergens := parser.DetectAllergens(strings.Join(r.Ingredients, "\n"))
+	diets := parser.DetectDiets(r, allergens)
+	ingredients := parser.IngredientNames(r.Ingredients)
+	difficulty, _ := strconv.Atoi(r.Difficulty)
+	minutes := parser.TotalMinutes(r)
+	levels := heat.Levels(r.StepHeat)
+	for i := range chunks {
+		chunks[i].Servings = r.Servings
+		chunks[i].Nutrition = r.Nutrition
+		chunks[i].Allergens = allergens
+		chunks[i].Difficulty = difficulty
+		chunks[i].Minutes = minutes
+		chunks[i].Diets = diets
+		chunks[i].Ingredients = ingredients
+		chunks[i].Heat = levels
+	}
+}
@@ -11,3 +11,9 @@ type Parser interface {
 	Collect(root string) ([]string, error)
 	ParseFiles(paths []string, opts types.Options) ([]types.Chunk, error)
 }
+
+// RecipeParser：可选接口；支持将文档解析为结构化 Recipe 的实现。
+//   - ParseRecipes：按 Options（仅使用 Root）将文件解析为 Recipe 集合；单个文件可包含多篇菜谱。
+type RecipeParser interface {
+	ParseRecipes(paths []string, opts types.Options) ([]*Recipe, error)
+}
@@ -9,10 +9,15 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+
+	"cook/internal/recipe/heat"
+	"cook/internal/recipe/units"
 )
 
 // Recipe：菜谱文档的基础结构体（示例）。
 //   - Title：菜谱标题；
+//   - Category：一级目录分类；
+//   - Path：相对语料根目录的路径；
 //   - Servings：份量；
 //   - CookingTime：烹饪时长；
 //   - Difficulty：难度评估；
@@ -20,17 +25,27 @@ import (
 //   - Ingredients：原料清单；
 //   - Steps：操作步骤；
 //   - Notes：补充说明；
+//   - Nutrition：营养成分（键为 schema.org NutritionInformation 字段名，如 calories）；
+//   - Quantities：原料用量（HowToCook「计算」章节或 schema.org recipeIngredient 中识别）；
+//   - StepQuantities：各步骤正文中的用量，与 Steps 一一对应；
+//   - StepHeat：各步骤的火候（火力、油温与温度），与 Steps 一一对应；
 //   - RawMarkdown：原始 Markdown 文本。
 type Recipe struct {
-	Title       string   // 菜谱标题
-	Servings    int      // 份量
-	CookingTime string   // 烹饪时长
-	Difficulty  string   // 难度
-	Tags        []string // 标签
-	Ingredients []string // 原料
-	Steps       []string // 步骤
-	Notes       string   // 备注
-	RawMarkdown string   // 原始 Markdown
+	Title          string             // 菜谱标题
+	Category       string             // 分类
+	Path           string             // 相对路径
+	Servings       int                // 份量
+	CookingTime    string             // 烹饪时长
+	Difficulty     string             // 难度
+	Tags           []string           // 标签
+	Ingredients    []string           // 原料
+	Steps          []string           // 步骤
+	Notes          string             // 备注
+	Nutrition      map[string]string  // 营养成分
+	Quantities     []units.Quantity   // 原料用量
+	StepQuantities [][]units.Quantity // 步骤用量
+	StepHeat       [][]heat.Heat      // 步骤火候
+	RawMarkdown    string             // 原始 Markdown
 }
 
 // ParseDir：解析目录下的所有 Markdown 文件为 Recipe 结构。
@@ -0,0 +1,260 @@
+// 文件功能：解析器注册表；按扩展名或内容嗅探将文件分派到对应的 Parser 实现，支持混合格式目录的统一解析。
+// 包功能：parser 包，提供格式注册与分派能力，新格式可在 init 中自行注册。
+package parser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"cook/internal/recipe/parser/types"
+)
+
+// sniffLen：内容嗅探读取的文件头部字节数。
+const sniffLen = 512
+
+// Sniffer：内容嗅探函数；head 为文件开头至多 512 字节；返回 true 表示该格式可处理此文件。
+type Sniffer func(path string, head []byte) bool
+
+// Format：一种可注册的文档格式。
+//   - Name：格式名称，在注册表内唯一；
+//   - Extensions：处理的扩展名（含点，大小写不敏感）；
+//   - Sniff：可选的内容嗅探；仅对没有扩展名的文件生效，只有具备可识别特征（如 Markdown 标题、schema.org 声明）的格式才应提供；
+//   - New：构造该格式的 Parser 实例。
+type Format struct {
+	Name       string        // 格式名称
+	Extensions []string      // 扩展名
+	Sniff      Sniffer       // 内容嗅探
+	New        func() Parser // 解析器构造函数
+}
+
+// Registry：格式注册表；本身实现 Parser 接口，按文件类型分派到已注册的实现。
+//   - Filter：Collect 使用的过滤规则。
+type Registry struct {
+	Filter Filter // 文件收集过滤规则
+
+	mu      sync.RWMutex
+	formats []Format
+	byExt   map[string]int
+	parsers map[string]Parser
+}
+
+// NewRegistry：构造空注册表；过滤规则为 DefaultFilter。
+func NewRegistry() *Registry {
+	return &Registry{
+		Filter:  DefaultFilter(),
+		byExt:   make(map[string]int),
+		parsers: make(map[string]Parser),
+	}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default：返回进程级默认注册表；各实现包在 init 中向其注册。
+func Default() *Registry { return defaultRegistry }
+
+// Register：向默认注册表注册格式；名称或扩展名冲突时 panic（与 database/sql 驱动注册一致）。
+func Register(f Format) {
+	if err := defaultRegistry.Register(f); err != nil {
+		panic(err)
+	}
+}
+
+// Register：注册格式。
+// 参数说明：
+//   - f：格式描述；Name 与 New 必填，Extensions 与 Sniff 至少提供其一。
+//
+// 返回值说明：
+//   - error：参数不完整、名称重复或扩展名已被其他格式占用时返回错误。
+func (r *Registry) Register(f Format) error {
+	if f.Name == "" || f.New == nil {
+		return fmt.Errorf("register: format name and constructor are required")
+	}
+	if len(f.Extensions) == 0 && f.Sniff == nil {
+		return fmt.Errorf("register %s: extensions or sniffer required", f.Name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, g := range r.formats {
+		if g.Name == f.Name {
+			return fmt.Errorf("register %s: duplicate format", f.Name)
+		}
+	}
+	for _, ext := range f.Extensions {
+		if i, ok := r.byExt[strings.ToLower(ext)]; ok {
+			return fmt.Errorf("register %s: extension %s already handled by %s", f.Name, ext, r.formats[i].Name)
+		}
+	}
+	r.formats = append(r.formats, f)
+	for _, ext := range f.Extensions {
+		r.byExt[strings.ToLower(ext)] = len(r.formats) - 1
+	}
+	return nil
+}
+
+// Formats：返回已注册格式名称（按注册顺序）。
+func (r *Registry) Formats() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]string, 0, len(r.formats))
+	for _, f := range r.formats {
+		out = append(out, f.Name)
+	}
+	return out
+}
+
+// Lookup：为文件选择格式。
+// 功能说明：按扩展名匹配；扩展名未注册的文件（如 .yaml、.toml 配置或 git-lfs 指针形式的图片）直接跳过，
+// 没有扩展名的文件读取头部，依注册顺序调用各格式的 Sniff。
+// 返回值说明：
+//   - Format：命中的格式；
+//   - bool：是否命中。
+func (r *Registry) Lookup(path string) (Format, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ext := strings.ToLower(filepath.Ext(path))
+	if i, ok := r.byExt[ext]; ok {
+		return r.formats[i], true
+	}
+	if ext != "" {
+		return Format{}, false
+	}
+	var head []byte
+	for _, f := range r.formats {
+		if f.Sniff == nil {
+			continue
+		}
+		if head == nil {
+			head = readHead(path)
+			if len(head) == 0 {
+				return Format{}, false
+			}
+		}
+		if f.Sniff(path, head) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Collect：收集 root 下所有可被已注册格式处理的文件；过滤与排序规则同 Walk。
+func (r *Registry) Collect(root string) ([]string, error) {
+	return Walk(root, r.Filter, func(path string) bool {
+		_, ok := r.Lookup(path)
+		return ok
+	})
+}
+
+// ParseFiles：按文件类型分派解析并合并为单一 Chunk 流。
+// 功能说明：以全部输入的公共父目录作为 opts.Root，保证不同格式的 path/category 元数据一致；
+// 按输入顺序逐文件解析，并在全局范围内重新编号 DocID 与 ID，避免不同实现之间的编号冲突。
+// 返回值说明：
+//   - []types.Chunk：合并后的分块；
+//   - error：存在无法识别的文件或任一实现解析失败时返回错误。
+func (r *Registry) ParseFiles(paths []string, opts types.Options) ([]types.Chunk, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("parse: no input files")
+	}
+	if opts.Root == "" {
+		opts.Root = CommonRoot(paths)
+	}
+	out := make([]types.Chunk, 0, len(paths)*4)
+	docCounter := 0
+	for _, pth := range paths {
+		f, ok := r.Lookup(pth)
+		if !ok {
+			return nil, fmt.Errorf("parse: no parser registered for %s", pth)
+		}
+		chunks, err := r.parser(f).ParseFiles([]string{pth}, opts)
+		if err != nil {
+			return nil, err
+		}
+		docIDs := make(map[string]string)
+		for _, c := range chunks {
+			id, ok := docIDs[c.DocID]
+			if !ok {
+				id = fmt.Sprintf("doc-%d", docCounter)
+				docCounter++
+				docIDs[c.DocID] = id
+			}
+			c.DocID = id
+			c.ID = fmt.Sprintf("chunk-%d", len(out)+1)
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// ParseRecipes：按文件类型分派结构化解析；未实现 RecipeParser 的格式被跳过。
+// 功能说明：与 ParseFiles 相同，以全部输入的公共父目录作为默认 opts.Root，保证 Category/Path 一致。
+func (r *Registry) ParseRecipes(paths []string, opts types.Options) ([]*Recipe, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("parse: no input files")
+	}
+	if opts.Root == "" {
+		opts.Root = CommonRoot(paths)
+	}
+	out := make([]*Recipe, 0, len(paths))
+	for _, pth := range paths {
+		f, ok := r.Lookup(pth)
+		if !ok {
+			return nil, fmt.Errorf("parse: no parser registered for %s", pth)
+		}
+		rp, ok := r.parser(f).(RecipeParser)
+		if !ok {
+			continue
+		}
+		recipes, err := rp.ParseRecipes([]string{pth}, opts)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, recipes...)
+	}
+	return out, nil
+}
+
+// parser：返回格式对应的解析器实例；同一注册表内按格式缓存。
+func (r *Registry) parser(f Format) Parser {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.parsers[f.Name]
+	if !ok {
+		p = f.New()
+		r.parsers[f.Name] = p
+	}
+	return p
+}
+
+// CommonRoot：计算路径集合的公共父目录；用于生成稳定的相对路径与分类元数据。
+func CommonRoot(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	root := filepath.Dir(filepath.Clean(paths[0]))
+	for _, p := range paths[1:] {
+		dir := filepath.Dir(filepath.Clean(p))
+		for root != dir && !strings.HasPrefix(dir, root+string(filepath.Separator)) {
+			parent := filepath.Dir(root)
+			if parent == root {
+				break
+			}
+			root = parent
+		}
+	}
+	return root
+}
+
+// readHead：读取文件头部用于嗅探；读取失败返回空切片。
+func readHead(path string) []byte {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer fh.Close()
+	buf := make([]byte, sniffLen)
+	n, _ := io.ReadFull(fh, buf)
+	return buf[:n]
+}
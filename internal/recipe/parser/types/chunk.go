@@ -12,17 +12,36 @@ package types
 //   - Source：来源路径与可选时间戳；
 //   - Category：文档一级目录分类；
 //   - Name：文档基名（不含后缀）；
-//   - Path：文档相对路径。
+//   - Path：文档相对路径；
+//   - Section：所属二级章节名（如「操作」）；一级标题分块为空；
+//   - Servings：文档声明的每份可供人数；未知为 0；
+//   - Nutrition：文档级营养成分（键为 schema.org 字段名）；
+//   - Allergens：文档原料中识别出的过敏原类别；
+//   - Difficulty：文档声明的烹饪难度（1～5 星）；未知为 0；
+//   - Minutes：总时长（分钟），声明值或步骤时长之和；未知为 0；
+//   - Diets：饮食标签（如素食、无麸质、低脂）；
+//   - Ingredients：原料名（拆分并列项、去掉括注）；
+//   - Heat：步骤中出现的命名火候（如中火、大火），按首次出现的顺序去重。
 type Chunk struct {
-	ID       string `json:"id"`       // 分块唯一标识
-	DocID    string `json:"doc_id"`   // 文档标识
-	Index    int    `json:"index"`    // 分块序号
-	Header   string `json:"header"`   // 分块标题
-	Text     string `json:"text"`     // 分块正文
-	Source   string `json:"source"`   // 来源信息
-	Category string `json:"category"` // 分类
-	Name     string `json:"name"`     // 文档名
-	Path     string `json:"path"`     // 相对路径
+	ID        string            `json:"id"`                  // 分块唯一标识
+	DocID     string            `json:"doc_id"`              // 文档标识
+	Index     int               `json:"index"`               // 分块序号
+	Header    string            `json:"header"`              // 分块标题
+	Text      string            `json:"text"`                // 分块正文
+	Source    string            `json:"source"`              // 来源信息
+	Category  string            `json:"category"`            // 分类
+	Name      string            `json:"name"`                // 文档名
+	Path      string            `json:"path"`                // 相对路径
+	Section   string            `json:"section,omitempty"`   // 所属章节
+	Servings  int               `json:"servings,omitempty"`  // 份量（人数）
+	Nutrition map[string]string `json:"nutrition,omitempty"` // 营养成分
+	Allergens []string          `json:"allergens,omitempty"` // 过敏原
+
+	Difficulty  int      `json:"difficulty,omitempty"`  // 难度
+	Minutes     int      `json:"minutes,omitempty"`     // 总时长
+	Diets       []string `json:"diets,omitempty"`       // 饮食标签
+	Ingredients []string `json:"ingredients,omitempty"` // 原料名
+	Heat        []string `json:"heat,omitempty"`        // 步骤中的命名火候
 }
 
 // Options controls parsing behaviors.
@@ -30,10 +49,12 @@ type Chunk struct {
 //   - ByHeader：是否按标题分块；
 //   - ChunkSize：按长度分块的最大字符数；
 //   - Overlap：相邻分块之间的重叠字符数；
-//   - Timestamp：是否在 Source 附加 UTC 时间戳。
+//   - Timestamp：是否在 Source 附加 UTC 时间戳；
+//   - Root：相对路径基准目录；为空时取全部输入文件的公共父目录。
 type Options struct {
-	ByHeader  bool // 标题分块开关
-	ChunkSize int  // 分块最大长度
-	Overlap   int  // 分块重叠长度
-	Timestamp bool // Source 是否带时间戳
+	ByHeader  bool   // 标题分块开关
+	ChunkSize int    // 分块最大长度
+	Overlap   int    // 分块重叠长度
+	Timestamp bool   // Source 是否带时间戳
+	Root      string // 相对路径基准目录
 }
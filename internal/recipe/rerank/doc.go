@@ -0,0 +1,4 @@
+// 文件功能：重排包说明。
+// 包功能：rerank 包，定义检索结果重排接口，提供 OpenAI 兼容 rerank 接口、大模型打分（DeepSeek 等 Chat 接口）
+// 与离线启发式三种实现，以及限定重排深度与超时的重排阶段；重排只改变候选顺序，不依赖索引。
+package rerank
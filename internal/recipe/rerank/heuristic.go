@@ -0,0 +1,156 @@
+// 文件功能：离线启发式重排；按问题意图与章节的匹配、菜名与问题的重合度、分类等元数据与问题的契合度，
+// 结合一阶段名次重新打分，无需外部服务。
+package rerank
+
+import (
+	"context"
+	"strings"
+
+	"cook/internal/recipe/textnorm"
+	"cook/internal/recipe/vector"
+)
+
+// HeuristicOptions：各项得分的权重（各项得分均在 0～1）；≤0 时使用默认值。
+//   - Prior：一阶段名次先验（第 1 名为 1，线性递减），默认 1；
+//   - Section：问题意图与章节匹配，默认 1；
+//   - Title：菜名与问题重合度，默认 1.5；
+//   - Meta：分类与营养信息契合度，默认 0.5。
+type HeuristicOptions struct {
+	Prior   float64
+	Section float64
+	Title   float64
+	Meta    float64
+}
+
+// withDefaults：填充未设置的权重。
+func (o HeuristicOptions) withDefaults() HeuristicOptions {
+	if o.Prior <= 0 {
+		o.Prior = 1
+	}
+	if o.Section <= 0 {
+		o.Section = 1
+	}
+	if o.Title <= 0 {
+		o.Title = 1.5
+	}
+	if o.Meta <= 0 {
+		o.Meta = 0.5
+	}
+	return o
+}
+
+// Heuristic：离线启发式重排器。
+type Heuristic struct {
+	opts HeuristicOptions
+}
+
+var _ Reranker = (*Heuristic)(nil)
+
+// NewHeuristic：构造启发式重排器。
+func NewHeuristic(opts HeuristicOptions) *Heuristic {
+	return &Heuristic{opts: opts.withDefaults()}
+}
+
+// Name：后端标识。
+func (h *Heuristic) Name() string { return ProviderHeuristic }
+
+// cue：意图线索；问题包含任一 words 时命中 target。
+type cue struct {
+	target string
+	words  []string
+}
+
+// sectionCues：问题意图对应的菜谱章节（与 HowToCook 模板的二级标题一致）。
+var sectionCues = []cue{
+	{"操作", []string{"怎么做", "怎样做", "如何做", "做法", "步骤", "流程", "教程", "怎么烧", "怎么炒", "怎么煮", "怎么炖", "制作"}},
+	{"必备原料和工具", []string{"材料", "食材", "原料", "配料", "调料", "需要什么", "准备什么", "用什么", "工具"}},
+	{"计算", []string{"多少", "用量", "分量", "份量", "几人份", "几克", "比例"}},
+	{"附加内容", []string{"注意", "技巧", "窍门", "小贴士", "为什么", "失败", "保存", "替代"}},
+}
+
+// categoryCues：问题用词对应的菜谱分类（与语料目录名一致）。
+var categoryCues = []cue{
+	{"soup", []string{"汤", "羹"}},
+	{"dessert", []string{"甜品", "甜点", "蛋糕", "布丁"}},
+	{"drink", []string{"饮料", "饮品", "奶茶", "果汁"}},
+	{"breakfast", []string{"早餐", "早饭", "早点"}},
+	{"staple", []string{"主食", "米饭", "炒饭", "面条", "炒面", "粥", "饼"}},
+	{"vegetable_dish", []string{"素菜", "素食", "蔬菜", "凉菜"}},
+	{"meat_dish", []string{"荤菜", "肉"}},
+	{"aquatic", []string{"海鲜", "水产", "鱼", "虾", "蟹", "贝"}},
+	{"condiment", []string{"酱料", "蘸料", "调味汁"}},
+	{"semi-finished", []string{"半成品", "速冻", "预制"}},
+}
+
+// nutritionCues：关注营养与热量的问题用词；命中时带营养信息的候选加分。
+var nutritionCues = []string{"减脂", "减肥", "低卡", "热量", "卡路里", "蛋白质", "健身", "营养", "低脂"}
+
+// match：返回问题命中的全部 target。
+func match(q string, cues []cue) map[string]bool {
+	out := make(map[string]bool)
+	for _, c := range cues {
+		for _, w := range c.words {
+			if strings.Contains(q, w) {
+				out[c.target] = true
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Rerank：按加权得分重排。
+// 算法说明：score = Prior×(1 − i/n) + Section×s + Title×t + Meta×m，其中 i 为一阶段名次（从 0 开始）；
+// s 为候选章节命中问题意图时 1；t 为菜名出现在问题中时 1，否则为菜名二元组在问题中出现的比例；
+// m 为候选分类命中问题中的分类用词时 1，问题关注营养且候选带营养信息时再加 0.5（上限 1）。
+// 问题未体现某类意图时该项对所有候选为 0，不影响排序。
+func (h *Heuristic) Rerank(_ context.Context, query string, docs []*vector.Document) ([]*vector.Document, error) {
+	q := textnorm.Fold(query)
+	sections := match(q, sectionCues)
+	categories := match(q, categoryCues)
+	nutrition := false
+	for _, w := range nutritionCues {
+		if strings.Contains(q, w) {
+			nutrition = true
+			break
+		}
+	}
+	scores := make([]float64, len(docs))
+	for i, d := range docs {
+		var s, m float64
+		if sections[metaString(d, vector.MetaSection)] {
+			s = 1
+		}
+		if categories[metaString(d, vector.MetaCategory)] {
+			m = 1
+		}
+		if _, ok := d.MetaData[vector.MetaNutrition]; ok && nutrition {
+			m = min(m+0.5, 1)
+		}
+		prior := 1 - float64(i)/float64(len(docs))
+		t := titleOverlap(q, textnorm.Fold(metaString(d, vector.MetaName)))
+		scores[i] = h.opts.Prior*prior + h.opts.Section*s + h.opts.Title*t + h.opts.Meta*m
+	}
+	return order(docs, scores), nil
+}
+
+// titleOverlap：菜名与问题的重合度（0～1）；菜名完整出现时为 1，否则为菜名二元组（单字菜名为单字）在问题中出现的比例。
+func titleOverlap(q, name string) float64 {
+	if name == "" {
+		return 0
+	}
+	if strings.Contains(q, name) {
+		return 1
+	}
+	r := []rune(name)
+	if len(r) == 1 {
+		return 0
+	}
+	hit := 0
+	for i := 0; i+1 < len(r); i++ {
+		if strings.Contains(q, string(r[i:i+2])) {
+			hit++
+		}
+	}
+	return float64(hit) / float64(len(r)-1)
+}
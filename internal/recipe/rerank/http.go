@@ -0,0 +1,116 @@
+// 文件功能：远程重排后端共用的 JSON 请求；限流与服务端错误时退避重试。
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 远程后端默认值。
+const (
+	DefaultRequestTimeout = 30 * time.Second
+	DefaultMaxRetries     = 2
+)
+
+// endpoint：远程接口。
+//   - url：完整请求地址；
+//   - apiKey：访问密钥；为空时不携带 Authorization；
+//   - retries：429 与 5xx 的最大重试次数；
+//   - client：HTTP 客户端。
+type endpoint struct {
+	url     string
+	apiKey  string
+	retries int
+	client  *http.Client
+}
+
+// newClient：未注入客户端时按超时构造。
+func newClient(c *http.Client, timeout time.Duration) *http.Client {
+	if c != nil {
+		return c
+	}
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// retries：将配置的重试次数转换为实际值（<0 不重试，0 取默认值）。
+func retries(n int) int {
+	if n == 0 {
+		return DefaultMaxRetries
+	}
+	return max(n, 0)
+}
+
+// checkURL：校验基础地址并去掉末尾斜杠。
+func checkURL(base string) (string, error) {
+	base = strings.TrimRight(base, "/")
+	if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+		return "", fmt.Errorf("rerank: bad base url %q", base)
+	}
+	return base, nil
+}
+
+// call：POST JSON 请求并解码响应；429 与 5xx 按指数退避重试，上下文取消时立即返回。
+func (e endpoint) call(ctx context.Context, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("rerank: %w", err)
+	}
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		retry, err := e.post(ctx, body, out)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= e.retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post：发送一次请求；返回是否值得重试。
+func (e endpoint) post(ctx context.Context, body []byte, out any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("rerank: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("rerank: %w", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return true, fmt.Errorf("rerank: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(b))
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("rerank: %s: status %d: %s", e.url, resp.StatusCode, msg)
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return false, fmt.Errorf("rerank: decode response: %w", err)
+	}
+	return false, nil
+}
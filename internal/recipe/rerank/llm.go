@@ -0,0 +1,161 @@
+// 文件功能：大模型打分重排；调用 Chat Completions 接口（默认 DeepSeek），让模型为每个候选片段给出 0～10 的相关度分数。
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cook/internal/recipe/vector"
+)
+
+// 大模型打分默认值。
+const (
+	DefaultLLMBaseURL  = "https://api.deepseek.com"
+	DefaultLLMModel    = "deepseek-chat"
+	DefaultLLMMaxChars = 300
+)
+
+// LLMOptions：大模型打分参数。
+//   - BaseURL：Chat Completions 接口基础地址；为空时使用 DefaultLLMBaseURL；
+//   - Model：模型名称；为空时使用 DefaultLLMModel；
+//   - APIKey：访问密钥；必填；
+//   - MaxChars：单个候选写入提示词的最大字符数；≤0 时为 DefaultLLMMaxChars；
+//   - Timeout：单次请求超时；≤0 时使用 DefaultRequestTimeout；
+//   - MaxRetries：429 与 5xx 的最大重试次数；<0 表示不重试，0 时使用 DefaultMaxRetries；
+//   - Client：HTTP 客户端；为空时按 Timeout 构造（测试可注入）。
+type LLMOptions struct {
+	BaseURL    string
+	Model      string
+	APIKey     string
+	MaxChars   int
+	Timeout    time.Duration
+	MaxRetries int
+	Client     *http.Client
+}
+
+// LLM：大模型打分重排器。
+type LLM struct {
+	model    string
+	maxChars int
+	ep       endpoint
+}
+
+var _ Reranker = (*LLM)(nil)
+
+// NewLLM：构造大模型打分重排器并填充默认值。
+// 返回值说明：
+//   - error：地址无效或未配置访问密钥时返回错误。
+func NewLLM(opts LLMOptions) (*LLM, error) {
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultLLMBaseURL
+	}
+	base, err := checkURL(opts.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if opts.APIKey == "" {
+		return nil, fmt.Errorf("rerank: llm reranker needs an api key (deepseek.api_key)")
+	}
+	if opts.Model == "" {
+		opts.Model = DefaultLLMModel
+	}
+	if opts.MaxChars <= 0 {
+		opts.MaxChars = DefaultLLMMaxChars
+	}
+	return &LLM{
+		model:    opts.Model,
+		maxChars: opts.MaxChars,
+		ep: endpoint{
+			url:     base + "/chat/completions",
+			apiKey:  opts.APIKey,
+			retries: retries(opts.MaxRetries),
+			client:  newClient(opts.Client, opts.Timeout),
+		},
+	}, nil
+}
+
+// Name：后端标识。
+func (l *LLM) Name() string { return ProviderLLM + ":" + l.model }
+
+// chatMessage / chatRequest / chatResponse：Chat Completions 接口的请求与响应（仅用到的字段）。
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string            `json:"model"`
+	Messages       []chatMessage     `json:"messages"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]string `json:"response_format"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// judgePrompt：系统提示词；要求模型只输出与候选顺序一致的分数数组。
+const judgePrompt = `你是菜谱问答系统的检索评审。给定用户问题与若干编号的菜谱片段，逐个评估片段能否直接回答问题，给出 0～10 的整数分：
+10 表示片段正是所需内容（如问做法时的操作步骤、问用料时的原料清单），5 表示相关但不直接回答，0 表示无关。
+只输出 JSON：{"scores":[…]}，数组长度等于片段数，顺序与编号一致，不要输出其他内容。`
+
+// Rerank：一次请求为全部候选打分；分数归一化到 0～1。
+// 返回值说明：
+//   - error：请求失败、模型输出无法解析或分数个数与候选数不符时返回错误。
+func (l *LLM) Rerank(ctx context.Context, query string, docs []*vector.Document) ([]*vector.Document, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "问题：%s\n\n共 %d 个片段：\n", query, len(docs))
+	for i, d := range docs {
+		fmt.Fprintf(&b, "[%d] %s\n\n", i+1, passage(d, l.maxChars))
+	}
+	req := chatRequest{
+		Model: l.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: judgePrompt},
+			{Role: "user", Content: b.String()},
+		},
+		ResponseFormat: map[string]string{"type": "json_object"},
+	}
+	var resp chatResponse
+	if err := l.ep.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("rerank: %s returned no choices", l.model)
+	}
+	scores, err := parseScores(resp.Choices[0].Message.Content, len(docs))
+	if err != nil {
+		return nil, fmt.Errorf("rerank: %s: %w", l.model, err)
+	}
+	return order(docs, scores), nil
+}
+
+// parseScores：解析模型输出的 {"scores":[…]}；容忍代码块包裹，分数截断到 0～10 后除以 10。
+func parseScores(content string, n int) ([]float64, error) {
+	content = strings.TrimSpace(content)
+	if i, j := strings.Index(content, "{"), strings.LastIndex(content, "}"); i >= 0 && j > i {
+		content = content[i : j+1]
+	}
+	var out struct {
+		Scores []float64 `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(content), &out); err != nil {
+		return nil, fmt.Errorf("decode scores: %w", err)
+	}
+	if len(out.Scores) != n {
+		return nil, fmt.Errorf("got %d scores for %d passages", len(out.Scores), n)
+	}
+	for i, s := range out.Scores {
+		out.Scores[i] = min(max(s, 0), 10) / 10
+	}
+	return out.Scores, nil
+}
@@ -0,0 +1,112 @@
+// 文件功能：OpenAI 兼容 rerank 接口后端；调用 POST {base_url}/rerank（Cohere、Jina、vLLM、SiliconFlow 等通用格式）。
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cook/internal/recipe/vector"
+)
+
+// DefaultRerankModel：rerank 接口默认模型。
+const DefaultRerankModel = "BAAI/bge-reranker-v2-m3"
+
+// OpenAIOptions：rerank 接口参数。
+//   - BaseURL：接口基础地址（含 /v1）；必填；
+//   - Model：模型名称；为空时使用 DefaultRerankModel；
+//   - APIKey：访问密钥；本地无鉴权服务可为空；
+//   - MaxChars：单个候选送出的最大字符数；≤0 时不截断；
+//   - Timeout：单次请求超时；≤0 时使用 DefaultRequestTimeout；
+//   - MaxRetries：429 与 5xx 的最大重试次数；<0 表示不重试，0 时使用 DefaultMaxRetries；
+//   - Client：HTTP 客户端；为空时按 Timeout 构造（测试可注入）。
+type OpenAIOptions struct {
+	BaseURL    string
+	Model      string
+	APIKey     string
+	MaxChars   int
+	Timeout    time.Duration
+	MaxRetries int
+	Client     *http.Client
+}
+
+// OpenAI：rerank 接口重排器。
+type OpenAI struct {
+	model    string
+	maxChars int
+	ep       endpoint
+}
+
+var _ Reranker = (*OpenAI)(nil)
+
+// NewOpenAI：构造 rerank 接口重排器并填充默认值。
+func NewOpenAI(opts OpenAIOptions) (*OpenAI, error) {
+	base, err := checkURL(opts.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Model == "" {
+		opts.Model = DefaultRerankModel
+	}
+	return &OpenAI{
+		model:    opts.Model,
+		maxChars: opts.MaxChars,
+		ep: endpoint{
+			url:     base + "/rerank",
+			apiKey:  opts.APIKey,
+			retries: retries(opts.MaxRetries),
+			client:  newClient(opts.Client, opts.Timeout),
+		},
+	}, nil
+}
+
+// Name：后端标识。
+func (o *OpenAI) Name() string { return ProviderOpenAI + ":" + o.model }
+
+// rerankRequest / rerankResponse：/rerank 接口的请求与响应。
+type rerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// Rerank：一次请求为全部候选打分；未返回得分的候选排在最后。
+// 返回值说明：
+//   - error：请求失败、重试耗尽或返回下标越界时返回错误。
+func (o *OpenAI) Rerank(ctx context.Context, query string, docs []*vector.Document) ([]*vector.Document, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	req := rerankRequest{Model: o.model, Query: query, Documents: make([]string, len(docs)), TopN: len(docs)}
+	for i, d := range docs {
+		req.Documents[i] = passage(d, o.maxChars)
+	}
+	var resp rerankResponse
+	if err := o.ep.call(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	scores := make([]float64, len(docs))
+	lowest := 0.0
+	for _, r := range resp.Results {
+		lowest = min(lowest, r.RelevanceScore)
+	}
+	for i := range scores {
+		scores[i] = lowest - 1
+	}
+	for _, r := range resp.Results {
+		if r.Index < 0 || r.Index >= len(docs) {
+			return nil, fmt.Errorf("rerank: %s returned index %d for %d documents", o.model, r.Index, len(docs))
+		}
+		scores[r.Index] = r.RelevanceScore
+	}
+	return order(docs, scores), nil
+}
@@ -0,0 +1,180 @@
+// 文件功能：重排器的单元测试；验证启发式打分、rerank 接口与大模型打分的请求与解析（httptest 模拟服务），以及重排阶段的深度与超时降级。
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cook/internal/recipe/vector"
+)
+
+func doc(id, name, section, category string) *vector.Document {
+	return &vector.Document{ID: id, Content: name + " " + section, MetaData: map[string]any{
+		vector.MetaName:     name,
+		vector.MetaSection:  section,
+		vector.MetaCategory: category,
+	}}
+}
+
+func ids(docs []*vector.Document) string {
+	out := make([]string, len(docs))
+	for i, d := range docs {
+		out[i] = d.ID
+	}
+	return fmt.Sprint(out)
+}
+
+func candidates() []*vector.Document {
+	return []*vector.Document{
+		doc("other-steps", "广式萝卜牛腩", "操作", "meat_dish"),
+		doc("beef-extra", "柱候牛腩", "附加内容", "meat_dish"),
+		doc("beef-steps", "柱候牛腩", "操作", "meat_dish"),
+		doc("beef-items", "柱候牛腩", "必备原料和工具", "meat_dish"),
+	}
+}
+
+func TestHeuristic(t *testing.T) {
+	h := NewHeuristic(HeuristicOptions{})
+	in := candidates()
+	got, err := h.Rerank(context.Background(), "柱候牛腩怎么做", in)
+	if err != nil || ids(got) != "[beef-steps other-steps beef-extra beef-items]" {
+		t.Fatalf("how-to = %s, %v", ids(got), err)
+	}
+	if got[0].Score() <= got[1].Score() || in[0].Score() != 0 {
+		t.Fatal("scores not descending or input modified")
+	}
+	got, _ = h.Rerank(context.Background(), "柱候牛腩需要什么材料", in)
+	if got[0].ID != "beef-items" {
+		t.Fatalf("ingredients = %s", ids(got))
+	}
+	soup := append(candidates(), doc("soup", "番茄牛腩汤", "操作", "soup"))
+	got, _ = h.Rerank(context.Background(), "牛腩汤怎么做", soup)
+	if got[0].ID != "soup" {
+		t.Fatalf("category = %s", ids(got))
+	}
+	if titleOverlap("柱候牛腩怎么做", "柱候牛腩") != 1 || titleOverlap("萝卜炖牛腩", "广式萝卜牛腩") != 0.4 {
+		t.Fatal("title overlap")
+	}
+}
+
+func TestOpenAI(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		var req rerankRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/v1/rerank" || req.Model != "m" || req.TopN != 3 || req.Query != "q" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		// 只返回两条，第三条应排在最后
+		fmt.Fprint(w, `{"results":[{"index":2,"relevance_score":0.9},{"index":0,"relevance_score":0.2}]}`)
+	}))
+	defer srv.Close()
+	o, err := NewOpenAI(OpenAIOptions{BaseURL: srv.URL + "/v1/", Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := o.Rerank(context.Background(), "q", candidates()[:3])
+	if err != nil || ids(got) != "[beef-steps other-steps beef-extra]" || got[0].Score() != 0.9 {
+		t.Fatalf("rerank = %s, %v", ids(got), err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want a retry", calls.Load())
+	}
+	if _, err := NewOpenAI(OpenAIOptions{}); err == nil {
+		t.Fatal("missing base url accepted")
+	}
+}
+
+func TestLLM(t *testing.T) {
+	reply := `{"scores":[2, 9, 12]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/chat/completions" || r.Header.Get("Authorization") != "Bearer k" ||
+			len(req.Messages) != 2 || !strings.Contains(req.Messages[1].Content, "[3] 柱候牛腩 操作") {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"choices": []any{map[string]any{"message": chatMessage{Role: "assistant", Content: "```json\n" + reply + "\n```"}}}})
+	}))
+	defer srv.Close()
+	l, err := NewLLM(LLMOptions{BaseURL: srv.URL, APIKey: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := l.Rerank(context.Background(), "柱候牛腩怎么做", candidates()[:3])
+	if err != nil || ids(got) != "[beef-steps beef-extra other-steps]" || got[0].Score() != 1 {
+		t.Fatalf("rerank = %s, %v", ids(got), err)
+	}
+	reply = `{"scores":[1]}`
+	if _, err := l.Rerank(context.Background(), "q", candidates()[:3]); err == nil {
+		t.Fatal("short score list accepted")
+	}
+	if _, err := NewLLM(LLMOptions{}); err == nil {
+		t.Fatal("missing api key accepted")
+	}
+}
+
+// stub：按固定行为返回的测试重排器。
+type stub struct {
+	delay time.Duration
+	err   error
+}
+
+func (s stub) Name() string { return "stub" }
+
+func (s stub) Rerank(ctx context.Context, _ string, docs []*vector.Document) ([]*vector.Document, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(s.delay):
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	out := make([]*vector.Document, len(docs))
+	for i, d := range docs {
+		out[len(docs)-1-i] = d
+	}
+	return out, nil
+}
+
+func TestStage(t *testing.T) {
+	ctx := context.Background()
+	in := candidates()
+	s := &Stage{Reranker: stub{}, Depth: 2}
+	if s.Candidates(5) != 5 || s.Candidates(1) != 2 {
+		t.Fatal("candidates")
+	}
+	got, err := s.Rerank(ctx, "q", in)
+	if err != nil || ids(got) != "[beef-extra other-steps beef-steps beef-items]" {
+		t.Fatalf("depth = %s, %v", ids(got), err)
+	}
+	s = &Stage{Reranker: stub{delay: time.Second}, Timeout: 10 * time.Millisecond}
+	got, err = s.Rerank(ctx, "q", in)
+	if !errors.Is(err, context.DeadlineExceeded) || ids(got) != ids(in) {
+		t.Fatalf("timeout = %s, %v", ids(got), err)
+	}
+	if got, err := s.Rerank(ctx, " ", in); err != nil || ids(got) != ids(in) {
+		t.Fatalf("blank query = %s, %v", ids(got), err)
+	}
+	if r, err := New(Config{Provider: ProviderNone}); r != nil || err != nil {
+		t.Fatalf("none = %v, %v", r, err)
+	}
+	if _, err := New(Config{Provider: "bm25"}); err == nil {
+		t.Fatal("unknown provider accepted")
+	}
+}
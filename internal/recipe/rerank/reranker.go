@@ -0,0 +1,160 @@
+// 文件功能：重排接口、后端选择与重排阶段。
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cook/internal/recipe/vector"
+)
+
+// 后端名称；对应配置 rerank.provider。
+const (
+	ProviderNone      = "none"      // 不重排
+	ProviderOpenAI    = "openai"    // OpenAI 兼容 rerank 接口（Cohere/Jina/vLLM 等 POST /rerank）
+	ProviderLLM       = "llm"       // 大模型打分（DeepSeek 等 Chat Completions 接口）
+	ProviderHeuristic = "heuristic" // 离线启发式：章节、菜名与元数据匹配
+)
+
+// 重排阶段默认值。
+const (
+	DefaultDepth   = 20
+	DefaultTimeout = 10 * time.Second
+)
+
+// Reranker：重排器。
+//   - Rerank：按与查询的相关度重新排序候选；返回候选的副本（不修改入参），条数与入参相同，得分写入 Score；
+//     得分相同时保持原顺序；
+//   - Name：后端标识，用于调试输出。
+type Reranker interface {
+	Rerank(ctx context.Context, query string, docs []*vector.Document) ([]*vector.Document, error)
+	Name() string
+}
+
+// Config：后端选择与参数。
+//   - Provider：后端名称（none/openai/llm/heuristic）；为空时为 none；
+//   - OpenAI：rerank 接口参数；
+//   - LLM：大模型打分参数；
+//   - Heuristic：启发式权重。
+type Config struct {
+	Provider  string
+	OpenAI    OpenAIOptions
+	LLM       LLMOptions
+	Heuristic HeuristicOptions
+}
+
+// New：按配置构造重排器。
+// 返回值说明：
+//   - Reranker：重排器；Provider 为 none 或空时为 nil；
+//   - error：后端名称未知或参数无效时返回错误。
+func New(cfg Config) (Reranker, error) {
+	switch cfg.Provider {
+	case "", ProviderNone:
+		return nil, nil
+	case ProviderOpenAI:
+		return NewOpenAI(cfg.OpenAI)
+	case ProviderLLM:
+		return NewLLM(cfg.LLM)
+	case ProviderHeuristic:
+		return NewHeuristic(cfg.Heuristic), nil
+	}
+	return nil, fmt.Errorf("rerank: unknown provider %q", cfg.Provider)
+}
+
+// Stage：重排阶段。
+//   - Reranker：重排器；
+//   - Depth：参与重排的候选数（取一阶段结果的前 Depth 条）；≤0 时为 DefaultDepth；
+//   - Timeout：单次重排超时；≤0 时为 DefaultTimeout。
+type Stage struct {
+	Reranker Reranker
+	Depth    int
+	Timeout  time.Duration
+}
+
+// Limit：实际重排深度。
+func (s *Stage) Limit() int {
+	if s.Depth <= 0 {
+		return DefaultDepth
+	}
+	return s.Depth
+}
+
+// Candidates：一阶段应召回的候选数，即 topK 与重排深度的较大值。
+func (s *Stage) Candidates(topK int) int {
+	return max(topK, s.Limit())
+}
+
+// Rerank：重排前 Depth 条候选，其余候选按原顺序接在后面。
+// 功能说明：重排失败或超时时返回原顺序与错误，调用方可据此降级为一阶段结果。
+// 返回值说明：
+//   - []*vector.Document：重排后的候选；
+//   - error：重排失败、超时或返回条数不符时返回错误。
+func (s *Stage) Rerank(ctx context.Context, query string, docs []*vector.Document) ([]*vector.Document, error) {
+	n := min(len(docs), s.Limit())
+	if n == 0 || strings.TrimSpace(query) == "" {
+		return docs, nil
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	top, err := s.Reranker.Rerank(ctx, query, docs[:n])
+	if err != nil {
+		return docs, err
+	}
+	if len(top) != n {
+		return docs, fmt.Errorf("rerank: %s returned %d documents for %d candidates", s.Reranker.Name(), len(top), n)
+	}
+	out := make([]*vector.Document, 0, len(docs))
+	out = append(out, top...)
+	return append(out, docs[n:]...), nil
+}
+
+// order：按 scores 降序返回候选副本（得分写入 Score），得分相同时保持原顺序。
+func order(docs []*vector.Document, scores []float64) []*vector.Document {
+	idx := make([]int, len(docs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return scores[idx[i]] > scores[idx[j]] })
+	out := make([]*vector.Document, len(docs))
+	for i, j := range idx {
+		out[i] = withScore(docs[j], scores[j])
+	}
+	return out
+}
+
+// withScore：复制文档并写入得分，不修改一阶段返回的文档。
+func withScore(d *vector.Document, score float64) *vector.Document {
+	meta := make(map[string]any, len(d.MetaData))
+	for k, v := range d.MetaData {
+		meta[k] = v
+	}
+	c := &vector.Document{ID: d.ID, Content: d.Content, MetaData: meta}
+	return c.WithScore(score)
+}
+
+// metaString：读取字符串元数据；缺失或类型不符时为空。
+func metaString(d *vector.Document, key string) string {
+	s, _ := d.MetaData[key].(string)
+	return s
+}
+
+// passage：交给远程重排的候选文本：菜名与正文（正文已含标题）；maxRunes > 0 时按字符截断。
+func passage(d *vector.Document, maxRunes int) string {
+	text := d.Content
+	if name := metaString(d, vector.MetaName); name != "" && !strings.Contains(text, name) {
+		text = name + "\n" + text
+	}
+	if maxRunes > 0 {
+		if r := []rune(text); len(r) > maxRunes {
+			text = string(r[:maxRunes]) + "…"
+		}
+	}
+	return text
+}
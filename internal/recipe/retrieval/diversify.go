@@ -0,0 +1,309 @@
+// 文件功能：结果多样化；按最大边际相关（MMR）从候选中依次选取，限制每道菜谱的分块数，可按菜谱分组返回；
+// 推荐类问题（如「推荐几道快手早餐」）每道菜谱只保留一个分块，使结果覆盖多道菜。
+package retrieval
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cook/internal/recipe/textnorm"
+	"cook/internal/recipe/vector"
+)
+
+// 多样化默认值。
+const (
+	DefaultLambda    = 0.7 // MMR 相关度权重
+	DefaultGroupSize = 3   // 分组时每道菜谱的分块数
+)
+
+// Diversity：结果多样化参数。
+//   - Lambda：MMR 相关度权重（0～1）；越小越偏向与已选结果不同的候选；≤0 时不做 MMR，只按上限截取；
+//   - MaxPerRecipe：每道菜谱最多保留的分块数；≤0 时不限；分组时不使用（见 GroupSize）；
+//   - SuggestPerRecipe：推荐类问题每道菜谱的分块上限；≤0 时推荐类问题与其他问题相同；
+//   - Depth：参与多样化的候选数；≤0 时为 TopK 的 4 倍；
+//   - GroupByRecipe：按菜谱分组返回，TopK 为菜谱数；
+//   - GroupSize：分组时每道菜谱的分块数；≤0 时为 DefaultGroupSize；推荐类问题同时受 SuggestPerRecipe 约束。
+//
+// 问题中出现完整菜名时，该菜谱不受上限约束，同菜谱相似度也不计入，以便「宫保鸡丁怎么做」返回该菜的多个章节。
+type Diversity struct {
+	Lambda           float64
+	MaxPerRecipe     int
+	SuggestPerRecipe int
+	Depth            int
+	GroupByRecipe    bool
+	GroupSize        int
+}
+
+// candidates：多样化需要的候选数。
+func (d *Diversity) candidates(topK int) int {
+	if d.Depth > 0 {
+		return max(topK, d.Depth)
+	}
+	n := topK * 4
+	if d.GroupByRecipe {
+		n *= d.groupSize()
+	}
+	return n
+}
+
+// groupSize：分组时每道菜谱的分块数。
+func (d *Diversity) groupSize() int {
+	if d.GroupSize <= 0 {
+		return DefaultGroupSize
+	}
+	return d.GroupSize
+}
+
+// DiversityStat：多样化阶段的执行情况。
+type DiversityStat struct {
+	Lambda       float64  `json:"lambda"`          // MMR 相关度权重
+	MaxPerRecipe int      `json:"max_per_recipe"`  // 实际使用的每菜谱上限（0 为不限）
+	Suggestion   bool     `json:"suggestion"`      // 是否识别为推荐类问题
+	Candidates   int      `json:"candidates"`      // 参与多样化的候选数
+	Recipes      int      `json:"recipes"`         // 结果覆盖的菜谱数
+	Grouped      bool     `json:"grouped"`         // 是否按菜谱分组
+	Named        []string `json:"named,omitempty"` // 问题中出现的菜名（不受上限约束）
+}
+
+// Group：按菜谱分组的结果。
+type Group struct {
+	Recipe string  // 菜谱标识（相对路径）
+	Name   string  // 菜名
+	Score  float64 // 组内最高得分
+	Hits   []Hit   // 组内分块（按选取顺序）
+}
+
+// suggestCues：推荐类问题用词；问题希望得到多道菜而不是一道菜的细节。
+var suggestCues = []string{
+	"推荐", "几道", "几样", "几个菜", "哪些菜", "什么菜", "有什么好吃", "吃什么", "做什么菜", "做点什么", "来几道", "建议做", "菜单",
+}
+
+// IsSuggestion：问题是否为推荐类（希望得到多道不同的菜）。
+func IsSuggestion(query string) bool {
+	q := textnorm.Fold(query)
+	for _, w := range suggestCues {
+		if strings.Contains(q, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecipeKey：文档所属菜谱的标识；依次取相对路径、文档标识与分块标识。
+func RecipeKey(d *vector.Document) string {
+	for _, k := range []string{vector.MetaPath, vector.MetaDocID} {
+		if s, _ := d.MetaData[k].(string); s != "" {
+			return s
+		}
+	}
+	return d.ID
+}
+
+// diversify：对按相关度降序的候选做多样化选取。
+// 算法说明：相关度 rel 为得分（融合或重排得分）按最小-最大归一到 0～1 的值，得分差距大的候选相关度差距也大；
+// 重排深度之外的候选仍为融合得分、量纲不同，因此 rel 沿名次取前缀最小值，不会高于排在前面的候选；
+// 每轮选取 λ×rel − (1−λ)×maxSim 最大的候选，maxSim 为与已选结果的最大相似度：同一菜谱为 1，否则两者都带稠密向量时取余弦相似度，
+// 不带时取正文二元组的 Jaccard 系数。已达上限的菜谱的候选跳过；得分相同时取名次靠前者。
+// 分组时先按上述方式选出菜谱顺序，每道菜谱再按原顺序取前 GroupSize 个分块。
+// 返回值说明：
+//   - []Hit：选取结果，不分组时至多 topK 条；
+//   - []Group：分组结果（至多 topK 组）；不分组时为 nil；
+//   - *DiversityStat：执行情况。
+func (d *Diversity) diversify(query string, hits []Hit, topK int) ([]Hit, []Group, *DiversityStat) {
+	stat := &DiversityStat{Lambda: d.Lambda, MaxPerRecipe: max(d.MaxPerRecipe, 0), Candidates: len(hits), Grouped: d.GroupByRecipe}
+	if IsSuggestion(query) {
+		stat.Suggestion = true
+		if d.SuggestPerRecipe > 0 {
+			stat.MaxPerRecipe = d.SuggestPerRecipe
+		}
+	}
+	q := textnorm.Fold(query)
+	keys := make([]string, len(hits))
+	named := map[string]bool{}
+	for i, h := range hits {
+		keys[i] = RecipeKey(h.Doc)
+		name, _ := h.Doc.MetaData[vector.MetaName].(string)
+		if name = textnorm.Fold(name); utf8.RuneCountInString(name) >= 2 && strings.Contains(q, name) && !named[keys[i]] {
+			named[keys[i]] = true
+			stat.Named = append(stat.Named, name)
+		}
+	}
+
+	if !d.GroupByRecipe {
+		order := d.mmr(hits, keys, named, stat.MaxPerRecipe, topK)
+		out := make([]Hit, len(order))
+		for i, j := range order {
+			out[i] = hits[j]
+		}
+		stat.Recipes = countRecipes(keys, order)
+		return out, nil, stat
+	}
+	// 分组时每道菜谱只选一次，选出的是菜谱顺序。
+	order := d.mmr(hits, keys, nil, 1, len(hits))
+	size := d.groupSize()
+	if stat.Suggestion && d.SuggestPerRecipe > 0 {
+		size = min(size, d.SuggestPerRecipe)
+	}
+	stat.MaxPerRecipe = size
+	byKey := map[string][]int{}
+	for i, k := range keys {
+		byKey[k] = append(byKey[k], i)
+	}
+	var groups []Group
+	var out []Hit
+	for _, j := range order {
+		if len(groups) == topK {
+			break
+		}
+		k := keys[j]
+		n := size
+		if named[k] {
+			n = d.groupSize()
+		}
+		idx := byKey[k]
+		g := Group{Recipe: k, Score: hits[idx[0]].Doc.Score()}
+		g.Name, _ = hits[idx[0]].Doc.MetaData[vector.MetaName].(string)
+		for _, i := range idx[:min(n, len(idx))] {
+			g.Hits = append(g.Hits, hits[i])
+		}
+		groups = append(groups, g)
+		out = append(out, g.Hits...)
+	}
+	stat.Recipes = len(groups)
+	return out, groups, stat
+}
+
+// mmr：按 MMR 选取候选下标；perRecipe > 0 时每道菜谱（named 中的除外）至多选 perRecipe 个，至多选 limit 个。
+func (d *Diversity) mmr(hits []Hit, keys []string, named map[string]bool, perRecipe, limit int) []int {
+	rel := relevance(hits)
+	lambda := min(d.Lambda, 1)
+	var grams []map[string]bool
+	if lambda > 0 && lambda < 1 {
+		grams = make([]map[string]bool, len(hits))
+	}
+	maxSim := make([]float64, len(hits))
+	taken := make([]bool, len(hits))
+	count := map[string]int{}
+	var order []int
+	for len(order) < limit {
+		best, bestScore := -1, math.Inf(-1)
+		for i := range hits {
+			if taken[i] || (perRecipe > 0 && !named[keys[i]] && count[keys[i]] >= perRecipe) {
+				continue
+			}
+			s := rel[i]
+			if lambda > 0 && lambda < 1 {
+				s = lambda*rel[i] - (1-lambda)*maxSim[i]
+			}
+			if s > bestScore {
+				best, bestScore = i, s
+			}
+		}
+		if best < 0 {
+			break
+		}
+		taken[best] = true
+		count[keys[best]]++
+		order = append(order, best)
+		if grams == nil {
+			continue
+		}
+		for i := range hits {
+			if taken[i] {
+				continue
+			}
+			s := 1.0
+			if keys[i] != keys[best] || named[keys[i]] {
+				s = similarity(hits[i].Doc, hits[best].Doc, grams, i, best)
+			}
+			maxSim[i] = max(maxSim[i], s)
+		}
+	}
+	return order
+}
+
+// relevance：按名次排列的候选的归一化相关度（见 diversify 的算法说明）；得分全部相同时均为 1。
+func relevance(hits []Hit) []float64 {
+	rel := make([]float64, len(hits))
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i, h := range hits {
+		rel[i] = h.Doc.Score()
+		lo, hi = min(lo, rel[i]), max(hi, rel[i])
+	}
+	for i := range rel {
+		if hi > lo {
+			rel[i] = (rel[i] - lo) / (hi - lo)
+		} else {
+			rel[i] = 1
+		}
+		if i > 0 {
+			rel[i] = min(rel[i], rel[i-1])
+		}
+	}
+	return rel
+}
+
+// similarity：两个候选的相似度（0～1）；都带稠密向量时取余弦相似度（负值记 0），否则取正文二元组的 Jaccard 系数。
+// grams 缓存各候选的二元组集合。
+func similarity(a, b *vector.Document, grams []map[string]bool, i, j int) float64 {
+	if va, vb := a.DenseVector(), b.DenseVector(); len(va) > 0 && len(va) == len(vb) {
+		return max(cosine(va, vb), 0)
+	}
+	if grams[i] == nil {
+		grams[i] = bigrams(a.Content)
+	}
+	if grams[j] == nil {
+		grams[j] = bigrams(b.Content)
+	}
+	inter := 0
+	for g := range grams[i] {
+		if grams[j][g] {
+			inter++
+		}
+	}
+	union := len(grams[i]) + len(grams[j]) - inter
+	if union == 0 {
+		return 0
+	}
+	return float64(inter) / float64(union)
+}
+
+// cosine：余弦相似度；任一向量为零向量时为 0。
+func cosine(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// bigrams：规范化文本中字母、数字与汉字的二元组集合（跳过空白与标点）。
+func bigrams(s string) map[string]bool {
+	var rs []rune
+	for _, r := range textnorm.Fold(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			rs = append(rs, r)
+		}
+	}
+	out := make(map[string]bool, len(rs))
+	for i := 0; i+1 < len(rs); i++ {
+		out[string(rs[i:i+2])] = true
+	}
+	return out
+}
+
+// countRecipes：选取结果覆盖的菜谱数。
+func countRecipes(keys []string, order []int) int {
+	seen := map[string]bool{}
+	for _, j := range order {
+		seen[keys[j]] = true
+	}
+	return len(seen)
+}
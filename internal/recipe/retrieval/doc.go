@@ -0,0 +1,4 @@
+// 文件功能：检索包说明。
+// 包功能：retrieval 包，定义问答链路使用的检索接口，提供基于向量存储的稠密检索、菜名召回，
+// 以及多路并行召回、融合、可选重排与结果多样化（MMR、每菜谱上限与按菜谱分组）的混合检索。
+package retrieval
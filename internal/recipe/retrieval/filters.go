@@ -0,0 +1,148 @@
+// 文件功能：类型化检索过滤条件；问答接口的 filters 对象在此校验并编译为 vector.Filter，由各路召回（ES、本地存储、BM25、菜名）统一执行。
+package retrieval
+
+import (
+	"fmt"
+	"strings"
+
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/vector"
+)
+
+// Filters：类型化过滤条件；零值表示不过滤，各条件之间为“且”。
+//   - Category：分类（如 meat_dish、soup），任一命中即可；
+//   - Difficulty：难度范围（1～5 星）；
+//   - TotalMinutes：总时长范围（分钟）；
+//   - Kcal/Protein/Fat/Carbs：每份热量（kcal）与蛋白质、脂肪、碳水化合物（克）范围；
+//   - ExcludeAllergens：排除的过敏原类别（如花生、麸质）；
+//   - Diets：须同时满足的饮食标签（如素食、低脂）；
+//   - IncludeIngredients：须全部包含的原料（按原料名子串匹配，「鸡」可匹配「鸡胸肉」）；
+//   - ExcludeIngredients：须不含的原料（同样按子串匹配）。
+//
+// 范围条件要求文档带有对应字段：缺少难度、时长或营养数据的菜谱不满足范围条件。
+type Filters struct {
+	Category           []string     `json:"category,omitempty"`
+	Difficulty         vector.Range `json:"difficulty"`
+	TotalMinutes       vector.Range `json:"total_minutes"`
+	Kcal               vector.Range `json:"kcal"`
+	Protein            vector.Range `json:"protein"`
+	Fat                vector.Range `json:"fat"`
+	Carbs              vector.Range `json:"carbs"`
+	ExcludeAllergens   []string     `json:"exclude_allergens,omitempty"`
+	Diets              []string     `json:"diets,omitempty"`
+	IncludeIngredients []string     `json:"include_ingredients,omitempty"`
+	ExcludeIngredients []string     `json:"exclude_ingredients,omitempty"`
+}
+
+// Validate：校验范围（下限不大于上限、不为负）；列表中的空白项被忽略。
+func (f Filters) Validate() error {
+	for _, r := range []struct {
+		name string
+		r    vector.Range
+	}{
+		{"difficulty", f.Difficulty},
+		{"total_minutes", f.TotalMinutes},
+		{"kcal", f.Kcal},
+		{"protein", f.Protein},
+		{"fat", f.Fat},
+		{"carbs", f.Carbs},
+	} {
+		if (r.r.Min != nil && *r.r.Min < 0) || (r.r.Max != nil && *r.r.Max < 0) {
+			return fmt.Errorf("filters: %s must not be negative", r.name)
+		}
+		if r.r.Min != nil && r.r.Max != nil && *r.r.Min > *r.r.Max {
+			return fmt.Errorf("filters: %s min %g is greater than max %g", r.name, *r.r.Min, *r.r.Max)
+		}
+	}
+	return nil
+}
+
+// Filter：编译为元数据过滤。
+// 功能说明：分类为 Terms，饮食标签为 All，过敏原为 Not，数值范围为 Ranges，原料为 Contains/NotContains；
+// 列表项去掉首尾空白，空项忽略。
+func (f Filters) Filter() vector.Filter {
+	var out vector.Filter
+	set := func(m *map[string][]string, key string, vs []string) {
+		vs = clean(vs)
+		if len(vs) == 0 {
+			return
+		}
+		if *m == nil {
+			*m = make(map[string][]string)
+		}
+		(*m)[key] = vs
+	}
+	set(&out.Terms, vector.MetaCategory, f.Category)
+	set(&out.All, vector.MetaDiets, f.Diets)
+	set(&out.Not, vector.MetaAllergens, f.ExcludeAllergens)
+	set(&out.Contains, vector.MetaIngredients, f.IncludeIngredients)
+	set(&out.NotContains, vector.MetaIngredients, f.ExcludeIngredients)
+	for key, r := range map[string]vector.Range{
+		vector.MetaDifficulty: f.Difficulty,
+		vector.MetaMinutes:    f.TotalMinutes,
+		vector.MetaKcal:       f.Kcal,
+		vector.MetaProtein:    f.Protein,
+		vector.MetaFat:        f.Fat,
+		vector.MetaCarbs:      f.Carbs,
+	} {
+		if r.Empty() {
+			continue
+		}
+		if out.Ranges == nil {
+			out.Ranges = make(map[string]vector.Range)
+		}
+		out.Ranges[key] = r
+	}
+	return out
+}
+
+// NutrientNeed：一个依赖营养数据的条件。
+//   - Filter：条件名（kcal、protein、fat、carbs 或 diets:低卡 等）；
+//   - Nutrient：所需的 schema.org 营养字段名（calories、proteinContent 等）。
+type NutrientNeed struct {
+	Filter   string
+	Nutrient string
+}
+
+// dietNutrients：依赖营养数据的饮食标签。
+var dietNutrients = map[string]string{
+	parser.DietLowCalorie:  "calories",
+	parser.DietLowFat:      "fatContent",
+	parser.DietHighProtein: "proteinContent",
+}
+
+// Nutrients：条件中依赖营养数据的部分（营养范围与低卡、低脂、高蛋白饮食标签），按条件出现顺序返回。
+// 缺少营养数据的菜谱不满足这些条件，调用方可据此提示语料的覆盖情况。
+func (f Filters) Nutrients() []NutrientNeed {
+	var out []NutrientNeed
+	for _, r := range []struct {
+		name, nutrient string
+		r              vector.Range
+	}{
+		{"kcal", "calories", f.Kcal},
+		{"protein", "proteinContent", f.Protein},
+		{"fat", "fatContent", f.Fat},
+		{"carbs", "carbohydrateContent", f.Carbs},
+	} {
+		if !r.r.Empty() {
+			out = append(out, NutrientNeed{Filter: r.name, Nutrient: r.nutrient})
+		}
+	}
+	for _, d := range clean(f.Diets) {
+		if n, ok := dietNutrients[d]; ok {
+			out = append(out, NutrientNeed{Filter: "diets:" + d, Nutrient: n})
+		}
+	}
+	return out
+}
+
+// clean：去掉首尾空白与空项。
+func clean(vs []string) []string {
+	var out []string
+	for _, v := range vs {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,335 @@
+// 文件功能：混合检索；可选的查询扩展阶段先改写查询，多路检索器并行召回，按倒数排名融合（RRF）或归一化加权得分合并，
+// 并记录每条结果来自哪一路；可选的重排阶段对融合结果的前若干条重新排序，可选的多样化阶段再按菜谱去重与分组。
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cook/internal/recipe/expand"
+	"cook/internal/recipe/rerank"
+	"cook/internal/recipe/vector"
+)
+
+// 融合方式。
+const (
+	FusionRRF      = "rrf"      // 倒数排名融合：Σ weight / (k + rank)
+	FusionWeighted = "weighted" // 各路得分 min-max 归一化后加权求和
+)
+
+// DefaultRRFK：RRF 平滑常数；越大排名靠后的结果权重衰减越慢。
+const DefaultRRFK = 60
+
+// Arm：一路召回。
+//   - Name：名称（如 dense、lexical、name），用于调试输出；
+//   - Retriever：检索器；
+//   - TopK：该路召回条数；≤0 时取最终 TopK 的 4 倍；
+//   - Weight：融合权重；≤0 时该路不参与。
+type Arm struct {
+	Name      string
+	Retriever Retriever
+	TopK      int
+	Weight    float64
+}
+
+// ArmHit：结果在某一路中的位置。
+type ArmHit struct {
+	Arm   string  `json:"arm"`   // 召回路名称
+	Rank  int     `json:"rank"`  // 该路中的名次（从 1 开始）
+	Score float64 `json:"score"` // 该路原始得分
+}
+
+// Hit：融合后的结果。
+type Hit struct {
+	Doc       *vector.Document // 文档；Score 为融合得分，重排后为重排得分
+	Arms      []ArmHit         // 命中该文档的各路（按权重贡献降序）
+	FusedRank int              // 重排前的融合名次（从 1 开始）
+}
+
+// ArmStat：一路召回的执行情况。
+type ArmStat struct {
+	Name   string  `json:"name"`            // 召回路名称
+	Hits   int     `json:"hits"`            // 召回条数
+	TookMS float64 `json:"took_ms"`         // 耗时（毫秒）
+	Error  string  `json:"error,omitempty"` // 失败原因
+}
+
+// RerankStat：重排阶段的执行情况。
+type RerankStat struct {
+	Name   string  `json:"name"`            // 重排器标识
+	Depth  int     `json:"depth"`           // 参与重排的候选数
+	TookMS float64 `json:"took_ms"`         // 耗时（毫秒）
+	Error  string  `json:"error,omitempty"` // 失败原因；失败时保留融合顺序
+}
+
+// Result：混合检索结果。
+type Result struct {
+	Fusion string      // 融合方式
+	Hits   []Hit       // 按融合得分（重排后按重排得分）降序
+	Arms   []ArmStat   // 与 Hybrid.Arms 顺序一致
+	Rerank *RerankStat // 重排阶段；未启用或无候选时为 nil
+
+	Expansion *expand.Expansion // 查询扩展；未启用或没有扩展词时为 nil
+	Diversity *DiversityStat    // 多样化阶段；未启用或无候选时为 nil
+	Groups    []Group           // 按菜谱分组的结果；未分组时为 nil，分组时 Hits 为各组分块依次拼接
+}
+
+// Hybrid：混合检索器。
+//   - Arms：各路召回；
+//   - Fusion：融合方式（FusionRRF/FusionWeighted）；为空时为 FusionRRF；
+//   - RRFK：RRF 平滑常数；≤0 时为 DefaultRRFK；
+//   - Rerank：重排阶段；为 nil 时不重排。启用时融合结果取 TopK 与重排深度的较大值，重排后再截取 TopK；
+//   - Expand：查询扩展；为 nil 时不扩展。扩展词只传给实现 ExpandedRetriever 的召回路，其余路使用原查询；
+//   - Diversity：多样化；为 nil 时不做。启用时融合结果再放宽到多样化深度，重排后从中选取 TopK 条（分组时为 TopK 道菜谱）。
+type Hybrid struct {
+	Arms      []Arm
+	Fusion    string
+	RRFK      float64
+	Rerank    *rerank.Stage
+	Expand    func(query string) expand.Expansion
+	Diversity *Diversity
+}
+
+var _ Retriever = (*Hybrid)(nil)
+
+// WithDiversity：返回使用指定多样化参数的副本（d 为 nil 时不做多样化），供单次请求覆盖配置；各路召回与重排共用。
+func (h *Hybrid) WithDiversity(d *Diversity) *Hybrid {
+	c := *h
+	c.Diversity = d
+	return &c
+}
+
+// Retrieve：执行混合检索并返回融合后的文档。
+func (h *Hybrid) Retrieve(ctx context.Context, query string, opts vector.SearchOptions) ([]*vector.Document, error) {
+	res, err := h.Search(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*vector.Document, len(res.Hits))
+	for i, hit := range res.Hits {
+		out[i] = hit.Doc
+	}
+	return out, nil
+}
+
+// Search：并行执行各路召回并融合。
+// 功能说明：各路使用同一过滤条件、各自的 TopK；单路失败只记录在 Result.Arms 中，其余路照常融合；
+// 全部参与的路都失败时返回错误。同一文档在多路命中时得分累加，文档内容取先出现的一路。
+// 重排失败或超时只记录在 Result.Rerank 中，结果保持融合顺序。多样化在重排之后进行。
+// 返回值说明：
+//   - Result：融合结果与各路执行情况；
+//   - error：融合方式未知或全部召回失败时返回错误。
+func (h *Hybrid) Search(ctx context.Context, query string, opts vector.SearchOptions) (Result, error) {
+	fusion := h.Fusion
+	if fusion == "" {
+		fusion = FusionRRF
+	}
+	if fusion != FusionRRF && fusion != FusionWeighted {
+		return Result{}, fmt.Errorf("retrieve: unknown fusion %q", fusion)
+	}
+	res := Result{Fusion: fusion, Arms: make([]ArmStat, len(h.Arms))}
+	if strings.TrimSpace(query) == "" || opts.TopK <= 0 {
+		return res, nil
+	}
+
+	var terms []expand.Term
+	if h.Expand != nil {
+		if exp := h.Expand(query); len(exp.Terms) > 0 {
+			res.Expansion = &exp
+			terms = exp.Terms
+		}
+	}
+
+	lists := make([][]*vector.Document, len(h.Arms))
+	var wg sync.WaitGroup
+	for i, arm := range h.Arms {
+		res.Arms[i].Name = arm.Name
+		if arm.Weight <= 0 || arm.Retriever == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, arm Arm) {
+			defer wg.Done()
+			o := opts
+			if arm.TopK > 0 {
+				o.TopK = arm.TopK
+			} else {
+				o.TopK = opts.TopK * 4
+			}
+			start := time.Now()
+			var docs []*vector.Document
+			var err error
+			if er, ok := arm.Retriever.(ExpandedRetriever); ok && len(terms) > 0 {
+				docs, err = er.RetrieveExpanded(ctx, query, terms, o)
+			} else {
+				docs, err = arm.Retriever.Retrieve(ctx, query, o)
+			}
+			res.Arms[i].TookMS = float64(time.Since(start).Microseconds()) / 1000
+			if err != nil {
+				res.Arms[i].Error = err.Error()
+				return
+			}
+			lists[i] = docs
+			res.Arms[i].Hits = len(docs)
+		}(i, arm)
+	}
+	wg.Wait()
+
+	ran, failed := 0, 0
+	for i, arm := range h.Arms {
+		if arm.Weight <= 0 || arm.Retriever == nil {
+			continue
+		}
+		ran++
+		if res.Arms[i].Error != "" {
+			failed++
+		}
+	}
+	if ran > 0 && failed == ran {
+		var msgs []string
+		for _, a := range res.Arms {
+			if a.Error != "" {
+				msgs = append(msgs, a.Name+": "+a.Error)
+			}
+		}
+		return res, fmt.Errorf("retrieve: all arms failed: %s", strings.Join(msgs, "; "))
+	}
+	n := opts.TopK
+	if h.Rerank != nil {
+		n = h.Rerank.Candidates(n)
+	}
+	if h.Diversity != nil {
+		n = max(n, h.Diversity.candidates(opts.TopK))
+	}
+	res.Hits = h.fuse(fusion, lists, n)
+	if h.Rerank != nil {
+		res.Hits, res.Rerank = h.rerank(ctx, query, res.Hits)
+	}
+	if h.Diversity != nil && len(res.Hits) > 0 {
+		res.Hits, res.Groups, res.Diversity = h.Diversity.diversify(query, res.Hits, opts.TopK)
+	} else if len(res.Hits) > opts.TopK {
+		res.Hits = res.Hits[:opts.TopK]
+	}
+	return res, nil
+}
+
+// rerank：重排融合结果；失败时返回原顺序并记录原因。
+func (h *Hybrid) rerank(ctx context.Context, query string, hits []Hit) ([]Hit, *RerankStat) {
+	if len(hits) == 0 {
+		return hits, nil
+	}
+	docs := make([]*vector.Document, len(hits))
+	byID := make(map[string]Hit, len(hits))
+	for i, hit := range hits {
+		docs[i] = hit.Doc
+		byID[hit.Doc.ID] = hit
+	}
+	stat := &RerankStat{Name: h.Rerank.Reranker.Name(), Depth: min(len(hits), h.Rerank.Limit())}
+	start := time.Now()
+	docs, err := h.Rerank.Rerank(ctx, query, docs)
+	stat.TookMS = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		stat.Error = err.Error()
+		return hits, stat
+	}
+	out := make([]Hit, len(docs))
+	for i, d := range docs {
+		hit := byID[d.ID]
+		hit.Doc = d
+		out[i] = hit
+	}
+	return out, stat
+}
+
+// fuse：融合各路结果并截取前 topK 条。
+func (h *Hybrid) fuse(fusion string, lists [][]*vector.Document, topK int) []Hit {
+	k := h.RRFK
+	if k <= 0 {
+		k = DefaultRRFK
+	}
+	type acc struct {
+		doc     *vector.Document
+		score   float64
+		arms    []ArmHit
+		contrib []float64
+		order   int
+	}
+	byID := make(map[string]*acc)
+	var order []*acc
+	for i, docs := range lists {
+		arm := h.Arms[i]
+		lo, hi := scoreRange(docs)
+		for r, d := range docs {
+			var c float64
+			switch fusion {
+			case FusionRRF:
+				c = arm.Weight / (k + float64(r+1))
+			case FusionWeighted:
+				c = arm.Weight
+				if hi > lo {
+					c *= (d.Score() - lo) / (hi - lo)
+				}
+			}
+			a := byID[d.ID]
+			if a == nil {
+				a = &acc{doc: d, order: len(order)}
+				byID[d.ID] = a
+				order = append(order, a)
+			}
+			a.score += c
+			a.arms = append(a.arms, ArmHit{Arm: arm.Name, Rank: r + 1, Score: d.Score()})
+			a.contrib = append(a.contrib, c)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].score != order[j].score {
+			return order[i].score > order[j].score
+		}
+		return order[i].order < order[j].order
+	})
+	if len(order) > topK {
+		order = order[:topK]
+	}
+	out := make([]Hit, 0, len(order))
+	for rank, a := range order {
+		idx := make([]int, len(a.arms))
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.SliceStable(idx, func(i, j int) bool { return a.contrib[idx[i]] > a.contrib[idx[j]] })
+		arms := make([]ArmHit, len(idx))
+		for i, j := range idx {
+			arms[i] = a.arms[j]
+		}
+		out = append(out, Hit{Doc: withScore(a.doc, a.score), Arms: arms, FusedRank: rank + 1})
+	}
+	return out
+}
+
+// scoreRange：一路结果的最低与最高得分。
+func scoreRange(docs []*vector.Document) (lo, hi float64) {
+	for i, d := range docs {
+		s := d.Score()
+		if i == 0 || s < lo {
+			lo = s
+		}
+		if i == 0 || s > hi {
+			hi = s
+		}
+	}
+	return lo, hi
+}
+
+// withScore：复制文档并写入融合得分，不修改各路返回的原文档。
+func withScore(d *vector.Document, score float64) *vector.Document {
+	meta := make(map[string]any, len(d.MetaData))
+	for k, v := range d.MetaData {
+		meta[k] = v
+	}
+	c := &vector.Document{ID: d.ID, Content: d.Content, MetaData: meta}
+	return c.WithScore(score)
+}
@@ -0,0 +1,69 @@
+// 文件功能：菜名召回；用菜名索引（全拼、首字母、同音与错字容忍）匹配查询中的菜名，返回命中菜谱的分块。
+package retrieval
+
+import (
+	"context"
+
+	"cook/internal/recipe/lookup"
+	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/vector"
+)
+
+// DefaultNameMinScore：菜名匹配的最低得分；低于该值的模糊匹配不参与召回，避免描述性问题误召回菜谱。
+const DefaultNameMinScore = 0.6
+
+// Names：菜名召回。
+//   - Index：返回当前菜名索引（语料刷新后可返回新索引）；
+//   - Chunks：返回当前全部分块；按 Path 与菜名索引的菜谱标识对应；
+//   - MinScore：最低匹配得分；≤0 时为 DefaultNameMinScore。
+type Names struct {
+	Index    func() *lookup.Index
+	Chunks   func() []types.Chunk
+	MinScore float64
+}
+
+var _ Retriever = (*Names)(nil)
+
+// Retrieve：匹配菜名并返回命中菜谱的分块。
+// 功能说明：菜谱按匹配得分降序，同一菜谱的分块按原文顺序；分块得分取菜谱的匹配得分；满足过滤条件的分块才返回，
+// 总数不超过 TopK。
+func (n *Names) Retrieve(_ context.Context, query string, opts vector.SearchOptions) ([]*vector.Document, error) {
+	if opts.TopK <= 0 {
+		return nil, nil
+	}
+	minScore := n.MinScore
+	if minScore <= 0 {
+		minScore = DefaultNameMinScore
+	}
+	matches := n.Index().Search(query, opts.TopK)
+	score := make(map[string]float64, len(matches))
+	for _, m := range matches {
+		if m.Score >= minScore {
+			score[m.ID] = m.Score
+		}
+	}
+	if len(score) == 0 {
+		return nil, nil
+	}
+	byPath := make(map[string][]*vector.Document, len(score))
+	for _, c := range n.Chunks() {
+		if _, ok := score[c.Path]; !ok {
+			continue
+		}
+		d := vector.FromChunk(c)
+		if opts.Filter.Match(d.MetaData) {
+			byPath[c.Path] = append(byPath[c.Path], d.WithScore(score[c.Path]))
+		}
+	}
+	var out []*vector.Document
+	for _, m := range matches {
+		for _, d := range byPath[m.ID] {
+			if len(out) == opts.TopK {
+				return out, nil
+			}
+			out = append(out, d)
+		}
+		delete(byPath, m.ID)
+	}
+	return out, nil
+}
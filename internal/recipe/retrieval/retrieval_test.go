@@ -0,0 +1,386 @@
+package retrieval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"cook/internal/recipe/embedding"
+	"cook/internal/recipe/expand"
+	"cook/internal/recipe/localstore"
+	"cook/internal/recipe/lookup"
+	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/rerank"
+	"cook/internal/recipe/vector"
+)
+
+func TestDense(t *testing.T) {
+	ctx := context.Background()
+	emb := embedding.NewHash(256)
+	st, err := localstore.Open(filepath.Join(t.TempDir(), "v.snap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	texts := map[string]string{
+		"tomato": "西红柿炒鸡蛋：西红柿切块，鸡蛋打散炒熟",
+		"beef":   "柱候牛腩：牛腩焯水后加柱候酱焖煮",
+		"soup":   "紫菜蛋花汤：紫菜泡发，淋入蛋液",
+	}
+	var docs []*vector.Document
+	for id, text := range texts {
+		vecs, err := emb.EmbedStrings(ctx, []string{text})
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := &vector.Document{ID: id, Content: text, MetaData: map[string]any{vector.MetaName: id}}
+		docs = append(docs, d.WithDenseVector(vecs[0]))
+	}
+	if err := st.Upsert(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	r := &Dense{Embedder: emb, Searcher: st}
+	got, err := r.Retrieve(ctx, "柱候牛腩怎么做", vector.SearchOptions{TopK: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "beef" || got[0].Score() <= got[1].Score() {
+		t.Fatalf("retrieve = %+v", got)
+	}
+	if got, _ := r.Retrieve(ctx, "  ", vector.SearchOptions{TopK: 2}); got != nil {
+		t.Fatalf("blank query = %+v", got)
+	}
+}
+
+// fixed：按固定顺序返回文档的测试检索器；err 非 nil 时返回错误。
+type fixed struct {
+	ids []string
+	err error
+}
+
+func (f fixed) Retrieve(_ context.Context, _ string, opts vector.SearchOptions) ([]*vector.Document, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var out []*vector.Document
+	for i, id := range f.ids {
+		if i == opts.TopK {
+			break
+		}
+		d := &vector.Document{ID: id, MetaData: map[string]any{}}
+		out = append(out, d.WithScore(float64(len(f.ids)-i)))
+	}
+	return out, nil
+}
+
+func hitIDs(hits []Hit) string {
+	out := make([]string, len(hits))
+	for i, h := range hits {
+		out[i] = h.Doc.ID
+	}
+	return fmt.Sprint(out)
+}
+
+func TestHybrid(t *testing.T) {
+	ctx := context.Background()
+	h := &Hybrid{Arms: []Arm{
+		{Name: "dense", Retriever: fixed{ids: []string{"a", "b", "c"}}, Weight: 1},
+		{Name: "lexical", Retriever: fixed{ids: []string{"c", "b", "d"}}, Weight: 1},
+	}}
+	res, err := h.Search(ctx, "q", vector.SearchOptions{TopK: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Fusion != FusionRRF || hitIDs(res.Hits) != "[c b a]" {
+		t.Fatalf("rrf = %s %s", res.Fusion, hitIDs(res.Hits))
+	}
+	if got := fmt.Sprint(res.Hits[0].Arms); got != "[{lexical 1 3} {dense 3 1}]" {
+		t.Fatalf("arms of c = %s", got)
+	}
+	if res.Arms[0].Hits != 3 || res.Arms[1].Name != "lexical" {
+		t.Fatalf("arm stats = %+v", res.Arms)
+	}
+
+	h.Arms[1].Weight = 3
+	res, _ = h.Search(ctx, "q", vector.SearchOptions{TopK: 4})
+	if hitIDs(res.Hits) != "[c b d a]" {
+		t.Fatalf("weighted rrf = %s", hitIDs(res.Hits))
+	}
+	h.Fusion = FusionWeighted
+	h.Arms[1].Weight = 1.5
+	res, _ = h.Search(ctx, "q", vector.SearchOptions{TopK: 4})
+	if hitIDs(res.Hits) != "[c b a d]" || res.Hits[0].Doc.Score() != 1.5 {
+		t.Fatalf("weighted = %s %v", hitIDs(res.Hits), res.Hits[0].Doc.Score())
+	}
+
+	h.Arms[1].Weight = 0
+	res, _ = h.Search(ctx, "q", vector.SearchOptions{TopK: 4})
+	if hitIDs(res.Hits) != "[a b c]" || res.Arms[1].Hits != 0 {
+		t.Fatalf("disabled arm = %s %+v", hitIDs(res.Hits), res.Arms)
+	}
+	h.Fusion = "max"
+	if _, err := h.Search(ctx, "q", vector.SearchOptions{TopK: 4}); err == nil {
+		t.Fatal("unknown fusion accepted")
+	}
+}
+
+func TestHybridFailures(t *testing.T) {
+	ctx := context.Background()
+	boom := errors.New("boom")
+	h := &Hybrid{Arms: []Arm{
+		{Name: "dense", Retriever: fixed{err: boom}, Weight: 1},
+		{Name: "lexical", Retriever: fixed{ids: []string{"x"}}, Weight: 1},
+	}}
+	res, err := h.Search(ctx, "q", vector.SearchOptions{TopK: 2})
+	if err != nil || hitIDs(res.Hits) != "[x]" || res.Arms[0].Error != "boom" {
+		t.Fatalf("partial failure = %+v, %v", res, err)
+	}
+	h.Arms[1].Retriever = fixed{err: boom}
+	if _, err := h.Search(ctx, "q", vector.SearchOptions{TopK: 2}); err == nil {
+		t.Fatal("all arms failed without error")
+	}
+	docs, err := h.Retrieve(ctx, " ", vector.SearchOptions{TopK: 2})
+	if err != nil || len(docs) != 0 {
+		t.Fatalf("blank query = %v, %v", docs, err)
+	}
+}
+
+// reverse：倒序候选的测试重排器；err 非 nil 时返回错误。
+type reverse struct{ err error }
+
+func (r reverse) Name() string { return "reverse" }
+
+func (r reverse) Rerank(_ context.Context, _ string, docs []*vector.Document) ([]*vector.Document, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	out := make([]*vector.Document, len(docs))
+	for i, d := range docs {
+		out[len(docs)-1-i] = d
+	}
+	return out, nil
+}
+
+func TestHybridRerank(t *testing.T) {
+	ctx := context.Background()
+	h := &Hybrid{
+		Arms:   []Arm{{Name: "lexical", Retriever: fixed{ids: []string{"a", "b", "c", "d"}}, Weight: 1}},
+		Rerank: &rerank.Stage{Reranker: reverse{}, Depth: 3},
+	}
+	res, err := h.Search(ctx, "q", vector.SearchOptions{TopK: 2})
+	if err != nil || hitIDs(res.Hits) != "[c b]" {
+		t.Fatalf("rerank = %s, %v", hitIDs(res.Hits), err)
+	}
+	if res.Hits[0].FusedRank != 3 || res.Hits[0].Arms[0].Rank != 3 || res.Rerank.Name != "reverse" || res.Rerank.Depth != 3 {
+		t.Fatalf("rerank debug = %+v %+v", res.Hits[0], res.Rerank)
+	}
+	h.Rerank.Reranker = reverse{err: errors.New("down")}
+	res, err = h.Search(ctx, "q", vector.SearchOptions{TopK: 2})
+	if err != nil || hitIDs(res.Hits) != "[a b]" || res.Rerank.Error != "down" {
+		t.Fatalf("rerank failure = %s %+v, %v", hitIDs(res.Hits), res.Rerank, err)
+	}
+}
+
+func TestNames(t *testing.T) {
+	ix := lookup.NewIndex()
+	ix.Add("meat/beef.md", "柱候牛腩")
+	ix.Add("veg/tomato.md", "西红柿炒鸡蛋", "番茄炒蛋")
+	chunks := []types.Chunk{
+		{ID: "beef-1", Path: "meat/beef.md", Name: "柱候牛腩", Category: "meat_dish"},
+		{ID: "beef-2", Path: "meat/beef.md", Name: "柱候牛腩", Category: "meat_dish"},
+		{ID: "tomato", Path: "veg/tomato.md", Name: "西红柿炒鸡蛋", Category: "vegetable_dish"},
+	}
+	n := &Names{Index: func() *lookup.Index { return ix }, Chunks: func() []types.Chunk { return chunks }}
+	ctx := context.Background()
+	got, err := n.Retrieve(ctx, "番茄炒蛋", vector.SearchOptions{TopK: 5})
+	if err != nil || len(got) != 1 || got[0].ID != "tomato" || got[0].Score() < DefaultNameMinScore {
+		t.Fatalf("alias = %+v, %v", got, err)
+	}
+	if got, _ := n.Retrieve(ctx, "柱候牛腩", vector.SearchOptions{TopK: 1}); len(got) != 1 || got[0].ID != "beef-1" {
+		t.Fatalf("top-k = %+v", got)
+	}
+	filter := vector.Filter{Terms: map[string][]string{vector.MetaCategory: {"vegetable_dish"}}}
+	if got, _ := n.Retrieve(ctx, "柱候牛腩", vector.SearchOptions{TopK: 5, Filter: filter}); len(got) != 0 {
+		t.Fatalf("filtered = %+v", got)
+	}
+	if got, _ := n.Retrieve(ctx, "适合减脂的晚餐", vector.SearchOptions{TopK: 5}); len(got) != 0 {
+		t.Fatalf("descriptive query = %+v", got)
+	}
+}
+
+func TestFilters(t *testing.T) {
+	var f Filters
+	if err := json.Unmarshal([]byte(`{"category":["meat_dish"],"total_minutes":{"max":30},"fat":{"max":10},
+		"exclude_allergens":["花生"],"diets":["低脂"],"include_ingredients":["鸡"," "],"exclude_ingredients":["香菜"]}`), &f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	vf := f.Filter()
+	if len(vf.Ranges) != 2 || *vf.Ranges[vector.MetaMinutes].Max != 30 || vf.Ranges[vector.MetaMinutes].Min != nil {
+		t.Fatalf("ranges = %+v", vf.Ranges)
+	}
+	if fmt.Sprint(vf.Contains[vector.MetaIngredients]) != "[鸡]" || fmt.Sprint(vf.Not[vector.MetaAllergens]) != "[花生]" ||
+		fmt.Sprint(vf.All[vector.MetaDiets]) != "[低脂]" || fmt.Sprint(vf.Terms[vector.MetaCategory]) != "[meat_dish]" {
+		t.Fatalf("filter = %+v", vf)
+	}
+	meta := vector.FromChunk(types.Chunk{
+		Category: "meat_dish", Minutes: 20, Diets: []string{"低脂"}, Ingredients: []string{"鸡胸肉"},
+		Nutrition: map[string]string{"fatContent": "6 g"},
+	}).MetaData
+	if !vf.Match(meta) {
+		t.Fatal("matching document rejected")
+	}
+	if !(Filters{}).Filter().Empty() {
+		t.Fatal("zero filters not empty")
+	}
+	lo, hi := 3.0, 1.0
+	if err := (Filters{Difficulty: vector.Range{Min: &lo, Max: &hi}}).Validate(); err == nil {
+		t.Fatal("inverted range accepted")
+	}
+}
+
+// expanding：记录收到的扩展词的检索器。
+type expanding struct {
+	fixed
+	terms *[]expand.Term
+}
+
+func (e expanding) RetrieveExpanded(ctx context.Context, q string, terms []expand.Term, opts vector.SearchOptions) ([]*vector.Document, error) {
+	*e.terms = terms
+	return e.Retrieve(ctx, q, opts)
+}
+
+func TestHybridExpand(t *testing.T) {
+	var got []expand.Term
+	calls := 0
+	h := &Hybrid{
+		Arms: []Arm{
+			{Name: "dense", Retriever: fixed{ids: []string{"a"}}, Weight: 1},
+			{Name: "lexical", Retriever: expanding{fixed{ids: []string{"b"}}, &got}, Weight: 1},
+		},
+		Expand: func(q string) expand.Expansion {
+			calls++
+			if q != "番茄炒蛋" {
+				return expand.Expansion{}
+			}
+			return expand.Expansion{Matched: []string{"番茄"}, Terms: []expand.Term{{Term: "西红柿", From: "番茄", Relation: expand.RelSynonym, Weight: 0.8}}}
+		},
+	}
+	ctx := context.Background()
+	res, err := h.Search(ctx, "番茄炒蛋", vector.SearchOptions{TopK: 2})
+	if err != nil || res.Expansion == nil || len(got) != 1 || got[0].Term != "西红柿" || calls != 1 {
+		t.Fatalf("expansion = %+v, terms %v, calls %d, %v", res.Expansion, got, calls, err)
+	}
+	got = nil
+	if res, _ := h.Search(ctx, "红烧肉", vector.SearchOptions{TopK: 2}); res.Expansion != nil || got != nil || hitIDs(res.Hits) != "[a b]" {
+		t.Fatalf("no expansion = %+v, terms %v", res.Expansion, got)
+	}
+}
+
+// dishes：返回多道菜谱分块的测试检索器；分块按 ids 顺序，标识形如「a1」，首字母为菜谱。
+type dishes struct {
+	ids []string
+}
+
+func (r dishes) Retrieve(_ context.Context, _ string, opts vector.SearchOptions) ([]*vector.Document, error) {
+	names := map[byte]string{'a': "红烧肉", 'b': "糖醋排骨", 'c': "清蒸鲈鱼"}
+	var out []*vector.Document
+	for i, id := range r.ids[:min(len(r.ids), opts.TopK)] {
+		d := &vector.Document{ID: id, Content: names[id[0]], MetaData: map[string]any{
+			vector.MetaName: names[id[0]],
+			vector.MetaPath: id[:1] + ".md",
+		}}
+		out = append(out, d.WithScore(float64(len(r.ids)-i)))
+	}
+	return out, nil
+}
+
+func TestHybridDiversity(t *testing.T) {
+	ctx := context.Background()
+	h := &Hybrid{Arms: []Arm{{Name: "dense", Retriever: dishes{ids: []string{"a1", "a2", "a3", "b1", "c1"}}, Weight: 1}}}
+	search := func(q string, d *Diversity, topK int) Result {
+		t.Helper()
+		res, err := h.WithDiversity(d).Search(ctx, q, vector.SearchOptions{TopK: topK})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	if res := search("红烧", nil, 3); hitIDs(res.Hits) != "[a1 a2 a3]" || res.Diversity != nil {
+		t.Fatalf("no diversity = %s", hitIDs(res.Hits))
+	}
+	// 融合得分归一后 a2 的相关度（0.75）远高于 b1（0.25），λ=0.7 时同菜谱惩罚不足以让 b1 领先；λ=0.5 时 b1 领先。
+	res := search("红烧", &Diversity{Lambda: 0.7, MaxPerRecipe: 2}, 3)
+	if hitIDs(res.Hits) != "[a1 a2 b1]" || res.Diversity.Recipes != 2 || res.Diversity.Candidates != 5 {
+		t.Fatalf("mmr = %s %+v", hitIDs(res.Hits), res.Diversity)
+	}
+	if res := search("红烧", &Diversity{Lambda: 0.5, MaxPerRecipe: 2}, 2); hitIDs(res.Hits) != "[a1 b1]" {
+		t.Fatalf("mmr λ=0.5 = %s", hitIDs(res.Hits))
+	}
+	if res := search("红烧", &Diversity{MaxPerRecipe: 1}, 3); hitIDs(res.Hits) != "[a1 b1 c1]" {
+		t.Fatalf("cap = %s", hitIDs(res.Hits))
+	}
+	res = search("推荐几道家常菜", &Diversity{MaxPerRecipe: 2, SuggestPerRecipe: 1}, 3)
+	if hitIDs(res.Hits) != "[a1 b1 c1]" || !res.Diversity.Suggestion || res.Diversity.MaxPerRecipe != 1 {
+		t.Fatalf("suggestion = %s %+v", hitIDs(res.Hits), res.Diversity)
+	}
+	res = search("红烧肉怎么做", &Diversity{MaxPerRecipe: 1}, 3)
+	if hitIDs(res.Hits) != "[a1 a2 a3]" || fmt.Sprint(res.Diversity.Named) != "[红烧肉]" {
+		t.Fatalf("named dish = %s %+v", hitIDs(res.Hits), res.Diversity)
+	}
+
+	res = search("红烧", &Diversity{GroupByRecipe: true, GroupSize: 2}, 2)
+	if len(res.Groups) != 2 || hitIDs(res.Hits) != "[a1 a2 b1]" {
+		t.Fatalf("groups = %+v hits %s", res.Groups, hitIDs(res.Hits))
+	}
+	if g := res.Groups[0]; g.Recipe != "a.md" || g.Name != "红烧肉" || g.Score != g.Hits[0].Doc.Score() || len(g.Hits) != 2 {
+		t.Fatalf("group 0 = %+v", g)
+	}
+	if h.Diversity != nil {
+		t.Fatal("WithDiversity modified the original")
+	}
+}
+
+// 相关度取归一化得分而非名次：得分高得多的近似重复项排在得分很低的不相关项之前。
+func TestDiversifyScoreRelevance(t *testing.T) {
+	hit := func(id, name, content string, score float64) Hit {
+		d := &vector.Document{ID: id, Content: content, MetaData: map[string]any{vector.MetaName: name, vector.MetaPath: id + ".md"}}
+		return Hit{Doc: d.WithScore(score)}
+	}
+	hits := []Hit{
+		hit("a", "宫保鸡丁", "鸡腿肉切丁，花生米炸香，干辣椒花椒爆香后大火翻炒鸡丁", 2.4),
+		hit("b", "宫保鸡丁（家常版）", "鸡腿肉切丁，花生米炸香，干辣椒花椒爆香后大火翻炒鸡丁至熟", 2.3),
+		hit("c", "全麦面包", "面粉加酵母揉成面团发酵后放入烤箱", 1.2),
+		hit("d", "蛋炒饭", "米饭打散与鸡蛋同炒", 1.1),
+	}
+	d := &Diversity{Lambda: 0.7}
+	out, _, _ := d.diversify("宫爆鸡丁怎么做", hits, 3)
+	if hitIDs(out) != "[a b c]" {
+		t.Fatalf("order = %s", hitIDs(out))
+	}
+	if rel := relevance(hits); rel[0] != 1 || rel[3] != 0 || rel[1] < 0.9 || rel[2] > 0.1 {
+		t.Fatalf("relevance = %v", rel)
+	}
+	// 重排深度之外的融合得分（量纲更小或更大）不会高于排在前面的候选。
+	if rel := relevance([]Hit{hit("a", "", "", 0.2), hit("b", "", "", -1), hit("c", "", "", 0.016)}); rel[2] > rel[1] {
+		t.Fatalf("tail relevance = %v", rel)
+	}
+}
+
+func TestIsSuggestion(t *testing.T) {
+	for q, want := range map[string]bool{
+		"推荐几道快手早餐": true,
+		"今晚吃什么":    true,
+		"有哪些菜适合减脂": true,
+		"宫保鸡丁怎么做":  false,
+		"红烧肉要炖多久":  false,
+	} {
+		if got := IsSuggestion(q); got != want {
+			t.Errorf("IsSuggestion(%q) = %v", q, got)
+		}
+	}
+}
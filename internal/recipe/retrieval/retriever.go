@@ -0,0 +1,55 @@
+// 文件功能：检索接口与稠密检索；稠密检索向量化查询文本后交给向量存储（Elasticsearch 或本地存储）做近邻搜索。
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cook/internal/recipe/embedding"
+	"cook/internal/recipe/expand"
+	"cook/internal/recipe/vector"
+)
+
+// Retriever：检索器；按相关度降序返回文档，得分写入 Score。
+type Retriever interface {
+	Retrieve(ctx context.Context, query string, opts vector.SearchOptions) ([]*vector.Document, error)
+}
+
+// ExpandedRetriever：可使用查询扩展词的检索器（如 BM25）；扩展词按权重参与计分，权重低于原查询词。
+// 混合检索对未实现该接口的检索器只传原查询。
+type ExpandedRetriever interface {
+	Retriever
+	RetrieveExpanded(ctx context.Context, query string, terms []expand.Term, opts vector.SearchOptions) ([]*vector.Document, error)
+}
+
+// Dense：稠密检索。
+type Dense struct {
+	Embedder embedding.Embedder
+	Searcher vector.Searcher
+}
+
+var _ Retriever = (*Dense)(nil)
+
+// Retrieve：向量化查询后检索；空查询返回空结果。
+// 返回值说明：
+//   - []*vector.Document：按相似度降序的文档；
+//   - error：向量化或检索失败时返回错误。
+func (d *Dense) Retrieve(ctx context.Context, query string, opts vector.SearchOptions) ([]*vector.Document, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || opts.TopK <= 0 {
+		return nil, nil
+	}
+	vecs, err := d.Embedder.EmbedStrings(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("retrieve: embed query: %w", err)
+	}
+	if len(vecs) != 1 {
+		return nil, fmt.Errorf("retrieve: embedder returned %d vectors for 1 query", len(vecs))
+	}
+	docs, err := d.Searcher.Search(ctx, vecs[0], opts)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve: %w", err)
+	}
+	return docs, nil
+}
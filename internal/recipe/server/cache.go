@@ -0,0 +1,157 @@
+// 文件功能：cache 子命令；查看持久化向量缓存的大小与命中情况，按模型、使用时间或条数清理缓存。
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cook/internal/recipe/config"
+	"cook/internal/recipe/corpus"
+	"cook/internal/recipe/embedding"
+)
+
+// cache 子命令选项。
+var (
+	cacheFormat     string        // stats：输出格式：table|json
+	cacheOlderThan  time.Duration // prune：删除超过该时长未使用的条目
+	cacheMaxEntries int           // prune：至多保留的条目数
+	cacheKeepModels bool          // prune：保留其他模型的条目
+	cacheModel      string        // prune：视为当前模型的标识；为空时取当前 embedding 配置
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or prune the on-disk embedding cache (embedding.cache_path)",
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show embedding cache size, per-model entries and usage",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCacheStats()
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached vectors of other models, unused entries or the least recently used overflow",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCachePrune()
+	},
+}
+
+func init() {
+	cacheStatsCmd.Flags().StringVar(&cacheFormat, "format", "table", "output format: table|json")
+	f := cachePruneCmd.Flags()
+	f.DurationVar(&cacheOlderThan, "older-than", 0, "also remove entries not used for this long (e.g. 720h)")
+	f.IntVar(&cacheMaxEntries, "max-entries", 0, "keep at most this many most recently used entries (0 for no limit)")
+	f.BoolVar(&cacheKeepModels, "keep-other-models", false, "keep entries of models other than the configured one")
+	f.StringVar(&cacheModel, "model", "", "model id to keep, as shown by cache stats (default: the configured embedding model)")
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+}
+
+// loadEmbedCache：按配置打开向量缓存；未配置路径时返回错误。
+func loadEmbedCache(cfg *config.AppConfig) (*embedding.Cache, error) {
+	cache, err := openEmbedCache(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	if cache == nil {
+		return nil, fmt.Errorf("cache: embedding.cache_path is empty; caching is disabled")
+	}
+	return cache, nil
+}
+
+// runCacheStats：输出缓存统计；当前配置的模型在表格中以 * 标记。
+func runCacheStats() error {
+	if cacheFormat != "table" && cacheFormat != "json" {
+		return fmt.Errorf("cache: unknown format %q", cacheFormat)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cache, err := loadEmbedCache(cfg)
+	if err != nil {
+		return err
+	}
+	st := cache.Stats()
+	if cacheFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(st)
+	}
+	current := ""
+	if emb, err := newEmbedder(cfg, nil); err == nil {
+		current = emb.Model()
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "path\t%s\n", st.Path)
+	fmt.Fprintf(w, "size\t%.1f MiB on disk, ~%.1f MiB of vectors\n", float64(st.Bytes)/(1<<20), float64(st.Vectors*4)/(1<<20))
+	fmt.Fprintf(w, "entries\t%d\n", st.Entries)
+	fmt.Fprintf(w, "hits\t%d\n", st.TotalHits)
+	if !st.Oldest.IsZero() {
+		fmt.Fprintf(w, "oldest\t%s\n", st.Oldest.Format(time.DateTime))
+		fmt.Fprintf(w, "last used\t%s\n", st.LastUsed.Format(time.DateTime))
+	}
+	models := make([]string, 0, len(st.Models))
+	for m := range st.Models {
+		models = append(models, m)
+	}
+	sort.Strings(models)
+	for _, m := range models {
+		mark := ""
+		if m == current {
+			mark = " *"
+		}
+		fmt.Fprintf(w, "model\t%s%s: %d\n", m, mark, st.Models[m])
+	}
+	return w.Flush()
+}
+
+// runCachePrune：按选项清理缓存并保存。
+// 功能说明：默认删除当前模型（--model 或 embedding 配置）以外的条目；--older-than 与 --max-entries 追加按使用时间的清理。
+func runCachePrune() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cache, err := loadEmbedCache(cfg)
+	if err != nil {
+		return err
+	}
+	opts := embedding.PruneOptions{UnusedFor: cacheOlderThan, MaxEntries: cacheMaxEntries}
+	if !cacheKeepModels {
+		model := cacheModel
+		if model == "" {
+			emb, err := newEmbedder(cfg, nil)
+			if err != nil {
+				return fmt.Errorf("cache: resolve current model (pass --model or --keep-other-models): %w", err)
+			}
+			model = emb.Model()
+		}
+		opts.KeepModels = []string{model}
+	}
+	n := cache.Prune(opts)
+	if err := cache.Save(); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "removed %d entries, %d left in %s\n", n, cache.Stats().Entries, cache.Path())
+	return nil
+}
+
+// cacheSink：每批变更写入后保存向量缓存；放在索引写入之后，使监听模式下的缓存随索引持久化。
+type cacheSink struct{ c *embedding.Cache }
+
+var _ corpus.Sink = cacheSink{}
+
+// Apply：保存缓存。
+func (s cacheSink) Apply(_ context.Context, _ []corpus.Delta) error { return s.c.Save() }
@@ -3,9 +3,21 @@
 package server
 
 import (
+	"context"
 	"fmt"
-	"github.com/spf13/cobra"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cook/internal/recipe/config"
+	"cook/internal/recipe/corpus"
+	"cook/internal/recipe/embedding"
+	"cook/internal/recipe/indexer"
+	"cook/internal/recipe/vector"
 )
 
 var rootCmd = &cobra.Command{
@@ -22,16 +34,42 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+// 命令行选项。
+var (
+	serveWatchCorpus bool          // serve：监听语料目录并实时刷新内存目录
+	indexWatch       bool          // index：监听语料目录并持续增量索引
+	indexDeltaLog    string        // index：变更日志输出路径；- 表示标准输出，空表示不输出
+	indexStore       string        // index：向量存储后端（local/es/none）；为空时取 vector.backend
+	watchDebounce    time.Duration // 监听去抖间隔
+	indexCollapse    bool          // index：折叠样板行（覆盖 parser.collapse_boilerplate）
+	indexReplace     bool          // index：切换别名时删除与别名同名的具体索引
+)
+
 func init() {
+	serveCmd.Flags().BoolVar(&serveWatchCorpus, "watch-corpus", false, "watch the recipes tree and refresh the in-memory catalog on changes")
+	serveCmd.Flags().DurationVar(&watchDebounce, "debounce", corpus.DefaultDebounce, "quiet period before a burst of file events is applied")
+	indexCmd.Flags().BoolVar(&indexWatch, "watch", false, "keep running and re-index touched recipe bundles on file changes")
+	indexCmd.Flags().StringVar(&indexDeltaLog, "delta-log", "", "also write upsert/delete records as JSONL to this path (- for stdout)")
+	indexCmd.Flags().StringVar(&indexStore, "store", "", "vector store to index into: local (snapshot at vector.path), es (Elasticsearch at es8.address) or none; defaults to vector.backend")
+	indexCmd.Flags().BoolVar(&indexCollapse, "collapse-boilerplate", false, "drop lines shared by most recipes (e.g. the Issue/PR footer) before indexing; overrides parser.collapse_boilerplate")
+	indexCmd.Flags().DurationVar(&watchDebounce, "debounce", corpus.DefaultDebounce, "quiet period before a burst of file events is applied")
+	indexCmd.Flags().BoolVar(&indexReplace, "replace-index", false, "es: delete a concrete index named es8.index in the same request that creates the alias (one-time migration)")
 	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(indexCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(dedupCmd)
+	rootCmd.AddCommand(embedCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(cacheCmd)
 }
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start REST server",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return startHTTP()
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return startHTTP(ctx, serveWatchCorpus)
 	},
 }
 
@@ -39,7 +77,137 @@ var indexCmd = &cobra.Command{
 	Use:   "index",
 	Short: "Index recipes from recipes/",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Fprintln(os.Stdout, "indexing recipes: TODO")
-		return nil
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return runIndex(ctx, cmd)
 	},
 }
+
+// runIndex：执行索引构建。
+// 功能说明：全量解析语料，按 embedding 配置分批向量化后写入向量存储（--store），写入完成后清理存储中已不在语料中的文档；
+// --delta-log 额外写出变更日志。--watch 时继续监听目录，仅对受影响的菜谱包增量索引，直到中断。
+// 参数说明：
+//   - ctx：生命周期控制；
+//   - cmd：index 命令，用于判断哪些选项被显式设置。
+//
+// 返回值说明：
+//   - error：配置、解析、向量化、写入或监听失败时返回错误；全量索引存在写入失败的文档时返回错误。
+//
+// es 后端每次写入新的版本索引（<es8.index>-<时间戳>），校验文档数与冒烟查询后原子切换别名 es8.index，
+// 保留 es8.keep_versions 个旧版本；存在写入失败的文档时不切换。
+// 向量化经 embedding.cache_path 的缓存，内容未变的分块不再请求后端；每批变更写入后保存缓存。
+func runIndex(ctx context.Context, cmd *cobra.Command) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	var sinks []corpus.Sink
+	var store vector.Store
+	var ix *indexer.Sink
+	var cache *embedding.Cache
+	var emb embedding.Embedder
+	var rel *release
+	if indexStore != "none" {
+		var st vectorStore
+		var where string
+		if indexStore == "es" || indexStore == "" && cfg.Vector.Backend == "es" {
+			if st, rel, err = newRelease(cfg); err != nil {
+				return fmt.Errorf("index: %w", err)
+			}
+			where = cfg.ES8.Address + "/" + rel.index
+		} else if st, where, err = newStore(cfg, indexStore); err != nil {
+			return fmt.Errorf("index: %w", err)
+		}
+		if cache, err = openEmbedCache(cfg); err != nil {
+			return fmt.Errorf("index: %w", err)
+		}
+		if emb, err = newEmbedder(cfg, cache); err != nil {
+			return fmt.Errorf("index: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "indexing into %s with %s\n", where, emb.Model())
+		store = st
+		ix = indexer.New(emb, st, indexer.Options{BatchSize: cfg.Embedding.BatchSize, OnProgress: reportProgress})
+		sinks = append(sinks, ix)
+		if cache != nil {
+			sinks = append(sinks, cacheSink{cache})
+		}
+	}
+	if indexDeltaLog != "" {
+		var w io.Writer = os.Stdout
+		if indexDeltaLog != "-" {
+			f, err := os.Create(indexDeltaLog)
+			if err != nil {
+				return fmt.Errorf("index: %w", err)
+			}
+			defer f.Close()
+			w = f
+		}
+		sinks = append(sinks, corpus.NewJSONLSink(w))
+	}
+	sink := corpus.Tee(sinks...)
+
+	if cmd.Flags().Changed("collapse-boilerplate") {
+		cfg.Parser.CollapseBoilerplate = indexCollapse
+	}
+	cat := newCatalog(cfg)
+	deltas, err := cat.Load()
+	if err != nil {
+		return fmt.Errorf("index: %w", err)
+	}
+	if err := sink.Apply(ctx, deltas); err != nil {
+		return fmt.Errorf("index: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "indexed %d chunks from %s\n", len(deltas), cat.Root())
+	if cache != nil {
+		st := cache.Stats()
+		fmt.Fprintf(os.Stderr, "embedding cache: %d hits, %d misses, %d entries in %s\n", st.Hits, st.Misses, st.Entries, st.Path)
+	}
+	if store != nil {
+		chunks := cat.Chunks()
+		keep := make([]string, 0, len(chunks))
+		for _, c := range chunks {
+			keep = append(keep, c.ID)
+		}
+		n, err := store.Prune(ctx, keep)
+		if err != nil {
+			return fmt.Errorf("index: prune: %w", err)
+		}
+		if n > 0 {
+			fmt.Fprintf(os.Stderr, "removed %d stale documents\n", n)
+		}
+		if err := reportFailures(ix); err != nil && (!indexWatch || rel != nil) {
+			return err
+		}
+	}
+	if rel != nil {
+		if err := rel.promote(ctx, emb, cat.Chunks()); err != nil {
+			return fmt.Errorf("index: %w", err)
+		}
+	}
+	if !indexWatch {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "watching %s for changes\n", cat.Root())
+	return watchCatalog(ctx, cat, watchDebounce, sink)
+}
+
+// reportProgress：向标准错误输出索引进度。
+func reportProgress(p indexer.Progress) {
+	fmt.Fprintf(os.Stderr, "index: %d/%d deltas, %d upserted, %d deleted, %d failed\n", p.Done, p.Total, p.Upserted, p.Deleted, p.Failed)
+}
+
+// reportFailures：列出写入失败的文档（最多 20 条）；存在失败时返回汇总错误。
+func reportFailures(ix *indexer.Sink) error {
+	failures := ix.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	for i, f := range failures {
+		if i == 20 {
+			fmt.Fprintf(os.Stderr, "  ... and %d more\n", len(failures)-i)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "  %s: %d %s\n", f.ID, f.Status, f.Reason)
+	}
+	return fmt.Errorf("index: %d documents failed", len(failures))
+}
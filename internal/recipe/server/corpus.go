@@ -0,0 +1,409 @@
+// 文件功能：语料目录装配；按配置构造解析器注册表与内存 Catalog，并在监听模式下串联文件变更与刷新。
+// 包功能：server 包，封装 CLI 与 HTTP 服务相关逻辑。
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"cook/internal/recipe/config"
+	"cook/internal/recipe/corpus"
+	"cook/internal/recipe/dedup"
+	"cook/internal/recipe/embedding"
+	"cook/internal/recipe/es"
+	"cook/internal/recipe/expand"
+	"cook/internal/recipe/indexer"
+	"cook/internal/recipe/lexical"
+	"cook/internal/recipe/localstore"
+	"cook/internal/recipe/lookup"
+	"cook/internal/recipe/parser"
+	_ "cook/internal/recipe/parser/impl"
+	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/rerank"
+	"cook/internal/recipe/retrieval"
+	"cook/internal/recipe/vector"
+)
+
+// newRegistry：按配置构造解析器注册表。
+// 功能说明：使用默认注册表（Markdown、纯文本、schema.org 等格式由 impl 包注册），过滤规则取自 parser 配置。
+func newRegistry(cfg *config.AppConfig) *parser.Registry {
+	reg := parser.Default()
+	reg.Filter = parser.Filter{
+		Include:    cfg.Parser.Include,
+		Exclude:    cfg.Parser.Exclude,
+		IgnoreFile: cfg.Parser.IgnoreFile,
+	}
+	return reg
+}
+
+// newCatalog：按配置构造语料目录（未加载）；分块按标题切分，与 parse 命令默认值一致；
+// source 不带时间戳，使重复索引时未变化的文档与存储中的完全相同、不被重写；
+// parser.collapse_boilerplate 开启时入库前折叠样板行。
+func newCatalog(cfg *config.AppConfig) *corpus.Catalog {
+	opts := types.Options{ByHeader: true, ChunkSize: 1200, Overlap: 100, Root: cfg.Parser.Dir}
+	cat := corpus.NewCatalog(newRegistry(cfg), opts)
+	if cfg.Parser.CollapseBoilerplate {
+		cat.CollapseBoilerplate(dedup.DefaultBoilerplateOptions())
+	}
+	return cat
+}
+
+// watchCatalog：监听语料目录并局部刷新 Catalog，直到 ctx 取消。
+// 功能说明：每批去抖后的变更仅重新解析受影响的菜谱包；产生的变更交给 sink（为 nil 时仅更新内存目录）。
+// 刷新或写出失败只记录日志，不中断监听。
+func watchCatalog(ctx context.Context, cat *corpus.Catalog, debounce time.Duration, sink corpus.Sink) error {
+	return corpus.Watch(ctx, cat.Root(), debounce, func(paths []string) {
+		deltas, err := cat.Refresh(paths)
+		if err != nil {
+			log.Printf("refresh corpus: %v", err)
+			return
+		}
+		if len(deltas) == 0 {
+			return
+		}
+		log.Printf("refresh corpus: %d paths changed, %d deltas", len(paths), len(deltas))
+		if sink == nil {
+			return
+		}
+		if err := sink.Apply(ctx, deltas); err != nil {
+			log.Printf("apply deltas: %v", err)
+		}
+	})
+}
+
+// denseSink：serve 监听语料时的变更 Sink；与 index --watch 相同，将变更向量化后增量写入稠密召回路使用的向量存储，
+// cache 非 nil 时每批变更后保存向量缓存。未启用稠密召回时返回 nil（仅更新内存目录，BM25 与菜名召回随目录版本重建）。
+func denseSink(h *retrieval.Hybrid, cache *embedding.Cache, batchSize int) corpus.Sink {
+	for _, a := range h.Arms {
+		d, ok := a.Retriever.(*retrieval.Dense)
+		if !ok {
+			continue
+		}
+		st, ok := d.Searcher.(vector.Store)
+		if !ok {
+			return nil
+		}
+		sinks := []corpus.Sink{indexer.New(d.Embedder, st, indexer.Options{BatchSize: batchSize})}
+		if cache != nil {
+			sinks = append(sinks, cacheSink{cache})
+		}
+		return corpus.Tee(sinks...)
+	}
+	return nil
+}
+
+// nameIndex：菜名索引缓存；语料目录版本变化后在下次查询时重建。
+type nameIndex struct {
+	cat *corpus.Catalog
+
+	mu      sync.Mutex
+	version uint64
+	idx     *lookup.Index
+}
+
+// get：返回与当前语料目录一致的菜名索引。
+func (n *nameIndex) get() *lookup.Index {
+	v := n.cat.Version()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.idx == nil || n.version != v {
+		n.idx = lookup.FromRecipes(n.cat.Recipes())
+		n.version = v
+	}
+	return n.idx
+}
+
+// lexicalIndex：BM25 索引缓存；语料目录版本变化后在下次查询时重建（词典随菜谱一并更新）。
+type lexicalIndex struct {
+	cat *corpus.Catalog
+
+	mu      sync.Mutex
+	version uint64
+	idx     *lexical.Index
+}
+
+var _ retrieval.ExpandedRetriever = (*lexicalIndex)(nil)
+
+// get：返回与当前语料目录一致的 BM25 索引。
+func (l *lexicalIndex) get() *lexical.Index {
+	v := l.cat.Version()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.idx == nil || l.version != v {
+		a := lexical.NewAnalyzer(lexical.Dictionary(l.cat.Recipes()))
+		l.idx = lexical.Build(l.cat.Chunks(), a, lexical.Options{})
+		l.version = v
+	}
+	return l.idx
+}
+
+// Retrieve：在当前 BM25 索引上检索。
+func (l *lexicalIndex) Retrieve(ctx context.Context, query string, opts vector.SearchOptions) ([]*vector.Document, error) {
+	return l.get().Retrieve(ctx, query, opts)
+}
+
+// RetrieveExpanded：在当前 BM25 索引上带扩展词检索。
+func (l *lexicalIndex) RetrieveExpanded(ctx context.Context, query string, terms []expand.Term, opts vector.SearchOptions) ([]*vector.Document, error) {
+	return l.get().RetrieveExpanded(ctx, query, terms, opts)
+}
+
+// expanderIndex：查询扩展器缓存；语料目录版本变化后在下次查询时重建（别称与水产品种随菜谱补充，菜名作为受保护词）。
+type expanderIndex struct {
+	cat   *corpus.Catalog
+	extra string // 追加词表内容
+	opts  expand.Options
+
+	mu      sync.Mutex
+	version uint64
+	ex      *expand.Expander
+}
+
+// newExpander：按 retrieval.expansion 配置构造扩展器缓存；追加词表在此读取并校验。
+func newExpander(cfg *config.AppConfig, cat *corpus.Catalog) (*expanderIndex, error) {
+	ec := cfg.Retrieval.Expansion
+	e := &expanderIndex{cat: cat, opts: expand.Options{
+		SynonymWeight:  ec.SynonymWeight,
+		NarrowerWeight: ec.NarrowerWeight,
+		Decay:          ec.Decay,
+		MaxDepth:       ec.MaxDepth,
+		MaxTerms:       ec.MaxTerms,
+	}}
+	if ec.File != "" {
+		b, err := os.ReadFile(ec.File)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: expansion file: %w", err)
+		}
+		if err := expand.NewOntology().Parse(strings.NewReader(string(b))); err != nil {
+			return nil, fmt.Errorf("retrieval: %s: %w", ec.File, err)
+		}
+		e.extra = string(b)
+	}
+	return e, nil
+}
+
+// aquaticRoot：aquatic 分类补充品种时挂靠的上位词。
+const aquaticRoot = "水产"
+
+// get：返回与当前语料目录一致的扩展器。
+func (e *expanderIndex) get() *expand.Expander {
+	v := e.cat.Version()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.ex == nil || e.version != v {
+		o := expand.Default()
+		_ = o.Parse(strings.NewReader(e.extra)) // 已在 newExpander 中校验
+		recipes := e.cat.Recipes()
+		o.Seed(recipes, "aquatic", aquaticRoot)
+		titles := make([]string, len(recipes))
+		for i, r := range recipes {
+			titles[i] = r.Title
+		}
+		e.ex = expand.New(o, titles, e.opts)
+		e.version = v
+	}
+	return e.ex
+}
+
+// expand：扩展查询。
+func (e *expanderIndex) expand(query string) expand.Expansion { return e.get().Expand(query) }
+
+// openEmbedCache：打开 embedding.cache_path 指向的向量缓存；路径为空时返回 nil（不缓存）。
+func openEmbedCache(cfg *config.AppConfig) (*embedding.Cache, error) {
+	if cfg.Embedding.CachePath == "" {
+		return nil, nil
+	}
+	return embedding.OpenCache(cfg.Embedding.CachePath)
+}
+
+// newEmbedder：按 embedding 配置构造向量化后端；provider 为 hash 时无需凭据即可运行完整流程。
+// cache 非 nil 时先查缓存，仅未命中的文本请求后端；调用方负责 Save。
+func newEmbedder(cfg *config.AppConfig, cache *embedding.Cache) (embedding.Embedder, error) {
+	e := cfg.Embedding
+	emb, err := embedding.New(embedding.Config{
+		Provider:   e.Provider,
+		Dimensions: e.Dimensions,
+		OpenAI: embedding.OpenAIOptions{
+			BaseURL:    e.BaseURL,
+			Model:      e.Model,
+			APIKey:     e.APIKey,
+			Dimensions: e.Dimensions,
+			BatchSize:  e.BatchSize,
+			Timeout:    e.Timeout,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cache.Wrap(emb), nil
+}
+
+// vectorStore：可写入也可检索的向量存储。
+type vectorStore interface {
+	vector.Store
+	vector.Searcher
+}
+
+// newStore：按后端名称构造向量存储；backend 为空时取 vector.backend 配置。
+// 返回值说明：
+//   - vectorStore：存储实例；local 后端已加载快照；
+//   - string：用于日志的存储位置描述；
+//   - error：后端未知、连接参数无效或快照读取失败时返回错误。
+func newStore(cfg *config.AppConfig, backend string) (vectorStore, string, error) {
+	if backend == "" {
+		backend = cfg.Vector.Backend
+	}
+	switch backend {
+	case "local":
+		st, err := localstore.Open(cfg.Vector.Path)
+		if err != nil {
+			return nil, "", err
+		}
+		st.SetExactLimit(cfg.Vector.ExactLimit)
+		return st, st.Path(), nil
+	case "es":
+		c, err := newESClient(cfg)
+		if err != nil {
+			return nil, "", err
+		}
+		st := es.NewStore(c, cfg.ES8.Index, cfg.ES8.BulkSize)
+		return st, cfg.ES8.Address + "/" + st.Index(), nil
+	}
+	return nil, "", fmt.Errorf("unknown vector backend %q (want local or es)", backend)
+}
+
+// newESClient：按 es8 配置构造 Elasticsearch 客户端。
+func newESClient(cfg *config.AppConfig) (*es.Client, error) {
+	return es.NewClient(es.Options{
+		Address:    cfg.ES8.Address,
+		Username:   cfg.ES8.Username,
+		Password:   cfg.ES8.Password,
+		Timeout:    cfg.ES8.Timeout,
+		MaxRetries: cfg.ES8.MaxRetries,
+	})
+}
+
+// newDense：按配置构造稠密检索；local 后端在启动时加载快照，快照为空时提示先执行 index。查询向量经 cache 缓存。
+func newDense(cfg *config.AppConfig, cache *embedding.Cache) (*retrieval.Dense, error) {
+	st, where, err := newStore(cfg, "")
+	if err != nil {
+		return nil, err
+	}
+	emb, err := newEmbedder(cfg, cache)
+	if err != nil {
+		return nil, err
+	}
+	if ls, ok := st.(*localstore.Store); ok {
+		if ls.Len() == 0 {
+			log.Printf("vector store %s is empty; run `recipe-agent index` first", where)
+		} else {
+			log.Printf("loaded %d documents from %s", ls.Len(), where)
+		}
+	}
+	return &retrieval.Dense{Embedder: emb, Searcher: st}, nil
+}
+
+// 召回路名称。
+const (
+	armDense   = "dense"
+	armLexical = "lexical"
+	armName    = "name"
+)
+
+// newRerank：按 rerank 配置构造重排阶段；provider 为 none 时返回 nil。llm 重排器复用 deepseek 连接参数。
+func newRerank(cfg *config.AppConfig) (*rerank.Stage, error) {
+	rc := cfg.Rerank
+	r, err := rerank.New(rerank.Config{
+		Provider: rc.Provider,
+		OpenAI: rerank.OpenAIOptions{
+			BaseURL:  rc.BaseURL,
+			Model:    rc.Model,
+			APIKey:   rc.APIKey,
+			MaxChars: rc.MaxChars,
+			Timeout:  rc.Timeout,
+		},
+		LLM: rerank.LLMOptions{
+			BaseURL: cfg.DeepSeek.BaseURL,
+			Model:   cfg.DeepSeek.Model,
+			APIKey:  cfg.DeepSeek.APIKey,
+			Timeout: rc.Timeout,
+		},
+	})
+	if err != nil || r == nil {
+		return nil, err
+	}
+	return &rerank.Stage{Reranker: r, Depth: rc.Depth, Timeout: rc.Timeout}, nil
+}
+
+// newDiversity：由 retrieval.diversity 配置构造多样化参数。
+// 返回值说明：
+//   - error：lambda 不在 0～1 或上限为负时返回错误。
+func newDiversity(dc config.DiversityConfig) (*retrieval.Diversity, error) {
+	if dc.Lambda < 0 || dc.Lambda > 1 {
+		return nil, fmt.Errorf("retrieval: diversity lambda %v out of range [0, 1]", dc.Lambda)
+	}
+	if dc.MaxPerRecipe < 0 || dc.SuggestPerRecipe < 0 || dc.Depth < 0 || dc.GroupSize < 0 {
+		return nil, fmt.Errorf("retrieval: diversity limits must not be negative")
+	}
+	return &retrieval.Diversity{
+		Lambda:           dc.Lambda,
+		MaxPerRecipe:     dc.MaxPerRecipe,
+		SuggestPerRecipe: dc.SuggestPerRecipe,
+		Depth:            dc.Depth,
+		GroupSize:        dc.GroupSize,
+	}, nil
+}
+
+// newHybrid：按 retrieval 配置构造混合检索器：稠密向量、BM25 与菜名三路召回；权重为 0 的路不构造。
+// BM25、菜名索引与查询扩展器随语料目录版本自动重建；names 为 nil 时新建菜名索引缓存；重排阶段按 rerank 配置构造，
+// 多样化阶段按 retrieval.diversity 配置构造；
+// 稠密检索的查询向量经 cache 缓存。
+func newHybrid(cfg *config.AppConfig, cat *corpus.Catalog, names *nameIndex, cache *embedding.Cache) (*retrieval.Hybrid, error) {
+	rc := cfg.Retrieval
+	stage, err := newRerank(cfg)
+	if err != nil {
+		return nil, err
+	}
+	h := &retrieval.Hybrid{Fusion: rc.Fusion, RRFK: rc.RRFK, Rerank: stage}
+	if rc.Expansion.Enabled {
+		ex, err := newExpander(cfg, cat)
+		if err != nil {
+			return nil, err
+		}
+		h.Expand = ex.expand
+	}
+	if rc.Diversity.Enabled {
+		if h.Diversity, err = newDiversity(rc.Diversity); err != nil {
+			return nil, err
+		}
+	}
+	if rc.Dense.Weight > 0 {
+		dense, err := newDense(cfg, cache)
+		if err != nil {
+			return nil, err
+		}
+		h.Arms = append(h.Arms, retrieval.Arm{Name: armDense, Retriever: dense, TopK: rc.Dense.TopK, Weight: rc.Dense.Weight})
+	}
+	if rc.Lexical.Weight > 0 {
+		h.Arms = append(h.Arms, retrieval.Arm{Name: armLexical, Retriever: &lexicalIndex{cat: cat}, TopK: rc.Lexical.TopK, Weight: rc.Lexical.Weight})
+	}
+	if rc.Name.Weight > 0 {
+		if names == nil {
+			names = &nameIndex{cat: cat}
+		}
+		h.Arms = append(h.Arms, retrieval.Arm{
+			Name:      armName,
+			Retriever: &retrieval.Names{Index: names.get, Chunks: cat.Chunks},
+			TopK:      rc.Name.TopK,
+			Weight:    rc.Name.Weight,
+		})
+	}
+	if len(h.Arms) == 0 {
+		return nil, fmt.Errorf("retrieval: every arm has weight 0")
+	}
+	return h, nil
+}
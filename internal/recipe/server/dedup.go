@@ -0,0 +1,120 @@
+// 文件功能：dedup 子命令；报告近似相同的菜谱与章节簇，以及索引时可折叠的样板行。
+// 包功能：server 包，封装 CLI 与 HTTP 服务相关逻辑。
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"cook/internal/recipe/config"
+	"cook/internal/recipe/corpus"
+	"cook/internal/recipe/dedup"
+	"cook/internal/recipe/parser/types"
+)
+
+// dedup 子命令选项。
+var (
+	dedupFormat    string  // 输出格式：table|json
+	dedupLevel     string  // 聚类粒度：recipe|section
+	dedupThreshold float64 // 相似度阈值
+	dedupShingle   int     // shingle 长度
+	dedupMinChars  int     // 最小长度
+	dedupKeepBP    bool    // 计算相似度时保留样板行
+)
+
+var dedupCmd = &cobra.Command{
+	Use:   "dedup",
+	Short: "Report clusters of near-duplicate recipes or sections and corpus boilerplate",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDedup()
+	},
+}
+
+func init() {
+	d := dedup.DefaultOptions()
+	f := dedupCmd.Flags()
+	f.StringVar(&dedupFormat, "format", "table", "output format: table|json")
+	f.StringVar(&dedupLevel, "level", d.Level, "compare whole recipes or individual sections: recipe|section")
+	f.Float64Var(&dedupThreshold, "threshold", d.Threshold, "minimum estimated Jaccard similarity of character shingles")
+	f.IntVar(&dedupShingle, "shingle", d.Shingle, "shingle size in characters")
+	f.IntVar(&dedupMinChars, "min-chars", d.MinChars, "skip items shorter than this after normalization")
+	f.BoolVar(&dedupKeepBP, "keep-boilerplate", false, "compare text including boilerplate lines shared by most recipes")
+}
+
+// dedupReport：dedup 子命令的 JSON 输出。
+type dedupReport struct {
+	Level       string          `json:"level"`       // 聚类粒度
+	Threshold   float64         `json:"threshold"`   // 相似度阈值
+	Boilerplate []string        `json:"boilerplate"` // 样板行
+	Clusters    []dedup.Cluster `json:"clusters"`    // 近重复簇
+}
+
+// runDedup：解析语料并输出近重复报告。
+// 返回值说明：
+//   - error：配置、收集、解析或写出失败，以及参数非法时返回错误。
+func runDedup() error {
+	if dedupFormat != "table" && dedupFormat != "json" {
+		return fmt.Errorf("dedup: unknown format %q", dedupFormat)
+	}
+	if dedupLevel != dedup.LevelRecipe && dedupLevel != dedup.LevelSection {
+		return fmt.Errorf("dedup: unknown level %q", dedupLevel)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	reg := newRegistry(cfg)
+	files, err := reg.Collect(cfg.Parser.Dir)
+	if err != nil {
+		return fmt.Errorf("dedup: %w", err)
+	}
+	chunks, err := reg.ParseFiles(files, types.Options{ByHeader: true, Root: cfg.Parser.Dir})
+	if err != nil {
+		return fmt.Errorf("dedup: %w", err)
+	}
+	corpus.AssignStableIDs(chunks) // 报告中的分块 ID 与索引一致
+	bp := dedup.LearnBoilerplate(chunks, dedup.DefaultBoilerplateOptions())
+	opts := dedup.DefaultOptions()
+	opts.Level, opts.Threshold, opts.Shingle, opts.MinChars = dedupLevel, dedupThreshold, dedupShingle, dedupMinChars
+	if !dedupKeepBP {
+		opts.Boilerplate = bp
+	}
+	r := dedupReport{Level: opts.Level, Threshold: opts.Threshold, Boilerplate: bp.Lines(), Clusters: dedup.Find(chunks, opts)}
+	if r.Clusters == nil {
+		r.Clusters = []dedup.Cluster{}
+	}
+	if dedupFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	return writeDedupTable(os.Stdout, r)
+}
+
+// writeDedupTable：以文本表格写出近重复报告。
+func writeDedupTable(w io.Writer, r dedupReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "boilerplate lines: %d\n", len(r.Boilerplate))
+	for _, l := range r.Boilerplate {
+		fmt.Fprintf(tw, "  %s\n", l)
+	}
+	fmt.Fprintf(tw, "\n%s clusters (similarity >= %.2f): %d\n", r.Level, r.Threshold, len(r.Clusters))
+	for i, c := range r.Clusters {
+		fmt.Fprintf(tw, "\n#%d\t%d members\tmax %.2f\n", i+1, len(c.Members), c.Max)
+		for _, m := range c.Members {
+			fmt.Fprintf(tw, "  %s\t%s\n", m.Path, m.Header)
+		}
+		for _, p := range c.Pairs {
+			a, b := c.Members[p.A], c.Members[p.B]
+			fmt.Fprintf(tw, "  %.2f\t%s\n", p.Similarity, strings.TrimSpace(a.Path+" "+a.Header)+" ~ "+strings.TrimSpace(b.Path+" "+b.Header))
+		}
+	}
+	return tw.Flush()
+}
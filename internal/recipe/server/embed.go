@@ -0,0 +1,73 @@
+// 文件功能：embed 子命令；按当前 embedding 配置向量化输入文本，用于核对后端、模型与维度配置。
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cook/internal/recipe/config"
+)
+
+var embedCmd = &cobra.Command{
+	Use:   "embed [text...]",
+	Short: "Embed texts with the configured backend (reads lines from stdin when no args)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runEmbed(cmd, args)
+	},
+}
+
+// embedRecord：embed 命令的单行输出。
+type embedRecord struct {
+	Text       string    `json:"text"`
+	Model      string    `json:"model"`
+	Dimensions int       `json:"dimensions"`
+	Vector     []float64 `json:"vector"`
+}
+
+// runEmbed：向量化参数或标准输入中的文本，逐行输出 JSON；结果写入向量缓存。
+func runEmbed(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cache, err := openEmbedCache(cfg)
+	if err != nil {
+		return err
+	}
+	emb, err := newEmbedder(cfg, cache)
+	if err != nil {
+		return err
+	}
+	texts := args
+	if len(texts) == 0 {
+		sc := bufio.NewScanner(os.Stdin)
+		sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for sc.Scan() {
+			if line := sc.Text(); line != "" {
+				texts = append(texts, line)
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return fmt.Errorf("embed: read stdin: %w", err)
+		}
+	}
+	vecs, err := emb.EmbedStrings(cmd.Context(), texts)
+	if err != nil {
+		return err
+	}
+	if err := cache.Save(); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	for i, v := range vecs {
+		if err := enc.Encode(embedRecord{Text: texts[i], Model: emb.Model(), Dimensions: len(v), Vector: v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
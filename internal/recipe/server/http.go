@@ -3,23 +3,69 @@
 package server
 
 import (
+	"context"
 	"cook/internal/recipe/config"
+	"cook/internal/recipe/corpus"
+	"cook/internal/recipe/lookup"
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/textnorm"
+	"encoding/json"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
+// recipeSummary：菜谱列表项。
+type recipeSummary struct {
+	ID          string   `json:"id"`           // 菜谱标识（相对路径）
+	Title       string   `json:"title"`        // 菜名
+	Category    string   `json:"category"`     // 分类
+	Difficulty  string   `json:"difficulty"`   // 难度
+	CookingTime string   `json:"cooking_time"` // 烹饪时长
+	Servings    int      `json:"servings"`     // 份量
+	Tags        []string `json:"tags"`         // 标签
+}
+
 // startHTTP：启动 HTTP 服务。
-// 功能说明：加载应用配置，初始化 chi 路由与基础中间件，注册健康检查与占位 API。
-// 参数说明：无。
+// 功能说明：加载应用配置、语料目录与向量存储，初始化 chi 路由与基础中间件，注册健康检查、菜谱列表、菜名查找与问答检索 API；
+// watchCorpus 为 true 时后台监听语料目录，菜谱列表与 BM25、菜名索引随文件变更实时更新，变更同时增量写入稠密召回的向量存储
+// （见 denseSink）。查询向量经向量缓存，关闭服务时保存。
+// 参数说明：
+//   - ctx：生命周期控制；取消时停止监听并关闭服务；
+//   - watchCorpus：是否监听语料目录。
+//
 // 返回值说明：
-//   - error：监听失败时返回错误。
-func startHTTP() error {
+//   - error：配置、语料加载、向量存储打开或监听失败时返回错误。
+func startHTTP(ctx context.Context, watchCorpus bool) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
+	cat := newCatalog(cfg)
+	if _, err := cat.Load(); err != nil {
+		return err
+	}
+	cache, err := openEmbedCache(cfg)
+	if err != nil {
+		return err
+	}
+	names := &nameIndex{cat: cat}
+	hybrid, err := newHybrid(cfg, cat, names, cache)
+	if err != nil {
+		return err
+	}
+	if watchCorpus {
+		go func() {
+			if err := watchCatalog(ctx, cat, watchDebounce, denseSink(hybrid, cache, cfg.Embedding.BatchSize)); err != nil {
+				log.Printf("watch corpus: %v", err)
+			}
+		}()
+	}
+
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
@@ -31,16 +77,81 @@ func startHTTP() error {
 		_, _ = w.Write([]byte("ok"))
 	})
 
-	r.Get("/api/v1/recipes", func(w http.ResponseWriter, _ *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte("[]"))
+	r.Get("/api/v1/recipes", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, http.StatusOK, recipeList(cat, req.URL.Query().Get("q")))
 	})
 
-	r.Post("/api/v1/query", func(w http.ResponseWriter, _ *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"answer":"TODO","sources":[]}`))
+	r.Get("/api/v1/recipes/lookup", func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query().Get("q")
+		limit, err := strconv.Atoi(req.URL.Query().Get("limit"))
+		if err != nil || limit <= 0 {
+			limit = 10
+		}
+		matches := names.get().Search(q, limit)
+		if matches == nil {
+			matches = []lookup.Match{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"query": q, "matches": matches})
 	})
 
-	addr := fmt.Sprintf(":%d", cfg.Server.Port)
-	return http.ListenAndServe(addr, r)
+	r.Post("/api/v1/query", queryHandler(hybrid, cfg.Retrieval.TopK, catalogCoverage(cat)))
+
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", cfg.Server.Port), Handler: r}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return cache.Save()
+}
+
+// recipeList：由语料目录生成菜谱列表。
+// 功能说明：q 非空时仅返回菜名、路径、标签或原料包含 q 的菜谱；匹配基于 textnorm 检索键，
+// 因此「白鯧」可以找到「白鲳」，返回的字段保持原文。
+func recipeList(cat *corpus.Catalog, q string) []recipeSummary {
+	recipes := cat.Recipes()
+	out := make([]recipeSummary, 0, len(recipes))
+	key := textnorm.Fold(strings.TrimSpace(q))
+	for _, rc := range recipes {
+		if key != "" && !matchRecipe(rc, key) {
+			continue
+		}
+		tags := rc.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		out = append(out, recipeSummary{
+			ID:          rc.Path,
+			Title:       rc.Title,
+			Category:    rc.Category,
+			Difficulty:  rc.Difficulty,
+			CookingTime: rc.CookingTime,
+			Servings:    rc.Servings,
+			Tags:        tags,
+		})
+	}
+	return out
+}
+
+// matchRecipe：菜谱的菜名、路径、标签或原料是否包含检索键 key（已折叠）。
+func matchRecipe(rc *parser.Recipe, key string) bool {
+	fields := append([]string{rc.Title, rc.Path}, rc.Tags...)
+	fields = append(fields, rc.Ingredients...)
+	for _, f := range fields {
+		if strings.Contains(textnorm.Fold(f), key) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSON：写出 JSON 响应。
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
 }
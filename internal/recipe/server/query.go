@@ -0,0 +1,234 @@
+// 文件功能：问答检索接口；POST /api/v1/query 以混合检索召回相关分块并返回来源列表（可按菜谱分组），debug 时附带各路召回详情。
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cook/internal/recipe/corpus"
+	"cook/internal/recipe/expand"
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/retrieval"
+	"cook/internal/recipe/vector"
+)
+
+// 检索条数默认值与上限。
+const (
+	defaultTopK = 5
+	maxTopK     = 50
+)
+
+// queryRequest：问答请求。
+type queryRequest struct {
+	Query string `json:"query"` // 查询文本
+	TopK  int    `json:"top_k"` // 返回条数；0 时取 retrieval.top_k
+	Debug bool   `json:"debug"` // 是否返回各路召回详情
+
+	Filters *retrieval.Filters `json:"filters"` // 元数据过滤条件（可选）
+
+	GroupByRecipe bool  `json:"group_by_recipe"` // 按菜谱分组返回；top_k 为菜谱数
+	MaxPerRecipe  int   `json:"max_per_recipe"`  // 每道菜谱的分块上限（分组时为组内分块数）；0 时取配置
+	Diversify     *bool `json:"diversify"`       // 为 false 时关闭多样化；省略时取配置
+}
+
+// querySource：检索到的来源分块。
+type querySource struct {
+	ID       string             `json:"id"`             // 分块标识
+	Score    float64            `json:"score"`          // 相关度得分
+	Name     string             `json:"name"`           // 菜名
+	Category string             `json:"category"`       // 分类
+	Path     string             `json:"path"`           // 相对路径
+	Header   string             `json:"header"`         // 分块标题
+	Content  string             `json:"content"`        // 分块正文
+	Arms     []retrieval.ArmHit `json:"arms,omitempty"` // 命中该分块的召回路（debug）
+
+	FusedRank int `json:"fused_rank,omitempty"` // 重排前的融合名次（debug）
+}
+
+// queryRecipe：按菜谱分组的来源。
+type queryRecipe struct {
+	Path     string        `json:"path"`     // 相对路径（菜谱标识）
+	Name     string        `json:"name"`     // 菜名
+	Category string        `json:"category"` // 分类
+	Score    float64       `json:"score"`    // 组内最高得分
+	Sources  []querySource `json:"sources"`  // 组内分块
+}
+
+// queryDebug：各路召回详情。
+type queryDebug struct {
+	Fusion    string                   `json:"fusion"`              // 融合方式
+	Arms      []retrieval.ArmStat      `json:"arms"`                // 各路执行情况
+	Rerank    *retrieval.RerankStat    `json:"rerank,omitempty"`    // 重排执行情况
+	Expansion *expand.Expansion        `json:"expansion,omitempty"` // 查询扩展：匹配的原料词与扩展词、关系及权重
+	Diversity *retrieval.DiversityStat `json:"diversity,omitempty"` // 多样化：λ、每菜谱上限、是否推荐类问题与覆盖菜谱数
+}
+
+// queryResponse：问答响应；answer 预留给生成阶段，当前为空；recipes 仅在按菜谱分组时返回，sources 为各组分块依次拼接；
+// warnings 提示结果可能不完整的原因（如过滤条件依赖的营养数据在语料中缺失）。
+type queryResponse struct {
+	Query    string        `json:"query"`
+	Answer   string        `json:"answer"`
+	Sources  []querySource `json:"sources"`
+	Recipes  []queryRecipe `json:"recipes,omitempty"`
+	Warnings []string      `json:"warnings,omitempty"`
+	Debug    *queryDebug   `json:"debug,omitempty"`
+}
+
+// coverageFunc：营养字段（schema.org 字段名）在语料中有数据的菜谱数与菜谱总数。
+type coverageFunc func(nutrient string) (have, total int)
+
+// catalogCoverage：按语料目录当前的菜谱统计营养字段覆盖情况。
+func catalogCoverage(cat *corpus.Catalog) coverageFunc {
+	return func(nutrient string) (int, int) {
+		recipes := cat.Recipes()
+		have := 0
+		for _, r := range recipes {
+			if _, ok := parser.NutritionValue(r.Nutrition, nutrient); ok {
+				have++
+			}
+		}
+		return have, len(recipes)
+	}
+}
+
+// nutritionWarnings：过滤条件依赖、但语料中部分或全部菜谱缺少的营养数据；cov 为 nil 时不检查。
+func nutritionWarnings(f *retrieval.Filters, cov coverageFunc) []string {
+	if f == nil || cov == nil {
+		return nil
+	}
+	var out []string
+	for _, n := range f.Nutrients() {
+		have, total := cov(n.Nutrient)
+		switch {
+		case have == 0:
+			out = append(out, fmt.Sprintf("filters: %s needs nutrition data (%s) that no recipe in the corpus has; it matches nothing", n.Filter, n.Nutrient))
+		case have < total:
+			out = append(out, fmt.Sprintf("filters: %s needs nutrition data (%s) that only %d of %d recipes have; the others are excluded", n.Filter, n.Nutrient, have, total))
+		}
+	}
+	return out
+}
+
+// queryHandler：问答检索处理函数。
+// 功能说明：请求体为 JSON（query 必填，top_k 可选，上限 maxTopK；filters 可选，如
+// {"category":["meat_dish"],"total_minutes":{"max":30},"fat":{"max":10},"exclude_allergens":["花生"],"include_ingredients":["鸡"]}，
+// 范围非法或请求含未知字段（如拼错的 "dificulty"）时返回 400；营养范围与低卡、低脂、高蛋白标签依赖营养数据，
+// 语料中缺少时响应附带 warnings；group_by_recipe 为 true 时按菜谱分组，max_per_recipe 覆盖每菜谱上限，diversify 为 false 时关闭多样化；
+// debug 为 true 时每个来源附带命中的召回路、名次与原始得分，
+// 响应附带各路召回条数、耗时与错误，重排器、重排深度、耗时与重排前名次，查询扩展词与权重，以及多样化情况）；全部召回失败返回 502。
+// cov 统计营养数据覆盖情况，为 nil 时不提示。
+func queryHandler(h *retrieval.Hybrid, topK int, cov coverageFunc) http.HandlerFunc {
+	if topK <= 0 {
+		topK = defaultTopK
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		var in queryRequest
+		dec := json.NewDecoder(req.Body)
+		dec.DisallowUnknownFields() // 拼错的条件名不能被静默忽略
+		if err := dec.Decode(&in); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json: " + err.Error()})
+			return
+		}
+		in.Query = strings.TrimSpace(in.Query)
+		if in.Query == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "query is required"})
+			return
+		}
+		if in.TopK <= 0 {
+			in.TopK = topK
+		}
+		in.TopK = min(in.TopK, maxTopK)
+		opts := vector.SearchOptions{TopK: in.TopK}
+		if in.Filters != nil {
+			if err := in.Filters.Validate(); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			opts.Filter = in.Filters.Filter()
+		}
+		if in.MaxPerRecipe < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "max_per_recipe must not be negative"})
+			return
+		}
+		off := in.Diversify != nil && !*in.Diversify
+		res, err := withDiversity(h, off, in.GroupByRecipe, in.MaxPerRecipe).Search(req.Context(), in.Query, opts)
+		if err != nil {
+			writeJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+			return
+		}
+		out := newQueryResponse(in.Query, res, in.Debug)
+		out.Warnings = nutritionWarnings(in.Filters, cov)
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+// newQueryResponse：由混合检索结果生成响应；debug 为 false 时省略召回详情。
+func newQueryResponse(query string, res retrieval.Result, debug bool) queryResponse {
+	out := queryResponse{Query: query, Sources: make([]querySource, 0, len(res.Hits))}
+	for _, hit := range res.Hits {
+		s := toSource(hit.Doc)
+		if debug {
+			s.Arms = hit.Arms
+			if res.Rerank != nil {
+				s.FusedRank = hit.FusedRank
+			}
+		}
+		out.Sources = append(out.Sources, s)
+	}
+	for _, g := range res.Groups {
+		r := queryRecipe{Path: g.Recipe, Name: g.Name, Score: g.Score, Sources: make([]querySource, 0, len(g.Hits))}
+		for _, hit := range g.Hits {
+			s := toSource(hit.Doc)
+			r.Category = s.Category
+			r.Sources = append(r.Sources, s)
+		}
+		out.Recipes = append(out.Recipes, r)
+	}
+	if debug {
+		out.Debug = &queryDebug{Fusion: res.Fusion, Arms: res.Arms, Rerank: res.Rerank, Expansion: res.Expansion, Diversity: res.Diversity}
+	}
+	return out
+}
+
+// withDiversity：按单次请求覆盖多样化参数；均未指定时返回 h 本身。
+// 参数说明：
+//   - off：关闭多样化（优先于其余参数）；
+//   - group：按菜谱分组；配置未启用多样化时只分组，不做 MMR 与上限截取；
+//   - perRecipe：每菜谱上限（分组时为组内分块数）；≤0 时取配置。
+func withDiversity(h *retrieval.Hybrid, off, group bool, perRecipe int) *retrieval.Hybrid {
+	switch {
+	case off:
+		return h.WithDiversity(nil)
+	case !group && perRecipe <= 0:
+		return h
+	}
+	var d retrieval.Diversity
+	if h.Diversity != nil {
+		d = *h.Diversity
+	}
+	d.GroupByRecipe = d.GroupByRecipe || group
+	if perRecipe > 0 {
+		if d.GroupByRecipe {
+			d.GroupSize = perRecipe
+		} else {
+			d.MaxPerRecipe = perRecipe
+		}
+	}
+	return h.WithDiversity(&d)
+}
+
+// toSource：将检索文档转换为响应中的来源。
+func toSource(d *vector.Document) querySource {
+	str := func(k string) string { s, _ := d.MetaData[k].(string); return s }
+	return querySource{
+		ID:       d.ID,
+		Score:    d.Score(),
+		Name:     str(vector.MetaName),
+		Category: str(vector.MetaCategory),
+		Path:     str(vector.MetaPath),
+		Header:   str(vector.MetaHeader),
+		Content:  d.Content,
+	}
+}
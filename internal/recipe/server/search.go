@@ -0,0 +1,204 @@
+// 文件功能：search 子命令；在命令行中对语料执行检索（混合检索或单路：稠密向量、词法 BM25、菜名），用于核对召回效果；
+// 混合检索可按菜谱分组输出。
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"cook/internal/recipe/config"
+	"cook/internal/recipe/expand"
+	"cook/internal/recipe/retrieval"
+	"cook/internal/recipe/vector"
+)
+
+// search 子命令选项。
+var (
+	searchMode    string // 检索方式：hybrid|dense|lexical|name
+	searchTopK    int    // 返回条数
+	searchFormat  string // 输出格式：table|json
+	searchRerank  string // 重排器；为空时取 rerank.provider
+	searchFilters string // 过滤条件（JSON，与问答接口的 filters 相同）
+	searchNoExp   bool   // 关闭查询扩展
+	searchNoDiv   bool   // 关闭多样化
+	searchGroup   bool   // 按菜谱分组
+	searchPerDish int    // 每菜谱分块上限
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search the corpus with hybrid retrieval or a single arm (dense, lexical, name)",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSearch(cmd, strings.Join(args, " "))
+	},
+}
+
+func init() {
+	f := searchCmd.Flags()
+	f.StringVar(&searchMode, "mode", "hybrid", "retrieval mode: hybrid (fused arms per retrieval config), dense (vector store, run index first), lexical (BM25) or name (dish-name matching)")
+	f.IntVar(&searchTopK, "top-k", defaultTopK, "number of results")
+	f.StringVar(&searchFormat, "format", "table", "output format: table|json")
+	f.StringVar(&searchFilters, "filters", "", `metadata filters as JSON, same as the query API, e.g. '{"diets":["素食"],"total_minutes":{"max":30}}'`)
+	f.BoolVar(&searchNoExp, "no-expand", false, "disable query expansion (synonyms and narrower ingredients) for hybrid and lexical modes")
+	f.BoolVar(&searchNoDiv, "no-diversify", false, "disable result diversification (MMR and per-recipe cap) for hybrid mode")
+	f.BoolVar(&searchGroup, "group-by-recipe", false, "hybrid mode: group results by recipe; --top-k counts recipes")
+	f.IntVar(&searchPerDish, "max-per-recipe", 0, "hybrid mode: chunks kept per recipe, or per group with --group-by-recipe (default: retrieval.diversity)")
+	f.StringVar(&searchRerank, "rerank", "", "reranker for hybrid mode: none|heuristic|openai|llm (default: rerank.provider)")
+}
+
+// runSearch：按 --mode 构造检索器并输出结果。
+// 返回值说明：
+//   - error：配置、语料加载、检索失败或参数非法时返回错误。
+func runSearch(cmd *cobra.Command, query string) error {
+	if searchFormat != "table" && searchFormat != "json" {
+		return fmt.Errorf("search: unknown format %q", searchFormat)
+	}
+	if searchPerDish < 0 {
+		return fmt.Errorf("search: --max-per-recipe must not be negative")
+	}
+	opts := vector.SearchOptions{TopK: searchTopK}
+	if searchFilters != "" {
+		var f retrieval.Filters
+		dec := json.NewDecoder(strings.NewReader(searchFilters))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&f); err != nil {
+			return fmt.Errorf("search: bad --filters: %w", err)
+		}
+		if err := f.Validate(); err != nil {
+			return fmt.Errorf("search: %w", err)
+		}
+		opts.Filter = f.Filter()
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if searchRerank != "" {
+		cfg.Rerank.Provider = searchRerank
+	}
+	if searchNoExp {
+		cfg.Retrieval.Expansion.Enabled = false
+	}
+	cat := newCatalog(cfg)
+	if searchMode != armDense {
+		if _, err := cat.Load(); err != nil {
+			return fmt.Errorf("search: %w", err)
+		}
+	}
+	cache, err := openEmbedCache(cfg)
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+	defer cache.Save()
+	var r retrieval.Retriever
+	var exp expand.Expansion // lexical 模式的查询扩展
+	switch searchMode {
+	case "hybrid":
+		h, err := newHybrid(cfg, cat, nil, cache)
+		if err != nil {
+			return fmt.Errorf("search: %w", err)
+		}
+		res, err := withDiversity(h, searchNoDiv, searchGroup, searchPerDish).Search(cmd.Context(), query, opts)
+		if err != nil {
+			return fmt.Errorf("search: %w", err)
+		}
+		return writeSearch(newQueryResponse(query, res, true))
+	case armDense:
+		if r, err = newDense(cfg, cache); err != nil {
+			return fmt.Errorf("search: %w", err)
+		}
+	case armLexical:
+		r = &lexicalIndex{cat: cat}
+		if cfg.Retrieval.Expansion.Enabled {
+			ex, err := newExpander(cfg, cat)
+			if err != nil {
+				return fmt.Errorf("search: %w", err)
+			}
+			exp = ex.expand(query)
+		}
+	case armName:
+		names := &nameIndex{cat: cat}
+		r = &retrieval.Names{Index: names.get, Chunks: cat.Chunks}
+	default:
+		return fmt.Errorf("search: unknown mode %q (want hybrid, dense, lexical or name)", searchMode)
+	}
+	var docs []*vector.Document
+	if er, ok := r.(retrieval.ExpandedRetriever); ok && len(exp.Terms) > 0 {
+		docs, err = er.RetrieveExpanded(cmd.Context(), query, exp.Terms, opts)
+	} else {
+		docs, err = r.Retrieve(cmd.Context(), query, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+	resp := queryResponse{Query: query, Sources: make([]querySource, 0, len(docs))}
+	for _, d := range docs {
+		resp.Sources = append(resp.Sources, toSource(d))
+	}
+	if len(exp.Terms) > 0 {
+		resp.Debug = &queryDebug{Expansion: &exp}
+	}
+	return writeSearch(resp)
+}
+
+// writeSearch：按 --format 写出检索结果。
+func writeSearch(resp queryResponse) error {
+	if searchFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(resp)
+	}
+	if resp.Debug != nil && resp.Debug.Expansion != nil {
+		writeExpansion(os.Stdout, resp.Debug.Expansion)
+	}
+	if len(resp.Recipes) > 0 {
+		return writeRecipeTable(os.Stdout, resp.Recipes)
+	}
+	return writeSearchTable(os.Stdout, resp.Sources)
+}
+
+// writeRecipeTable：以文本表格写出按菜谱分组的结果；每道菜谱一行，其下缩进列出组内分块的得分与标题。
+func writeRecipeTable(w io.Writer, recipes []queryRecipe) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, r := range recipes {
+		fmt.Fprintf(tw, "%d\t%.4f\t%s\t%s\t%s\n", i+1, r.Score, r.Name, r.Category, r.Path)
+		for _, s := range r.Sources {
+			fmt.Fprintf(tw, "\t%.4f\t  %s\t\t\n", s.Score, s.Header)
+		}
+	}
+	return tw.Flush()
+}
+
+// writeExpansion：写出一行查询扩展摘要，如「expanded: 西红柿 0.80 (番茄) 鸡胸脯肉 0.80 (鸡胸肉)」。
+func writeExpansion(w io.Writer, exp *expand.Expansion) {
+	parts := make([]string, len(exp.Terms))
+	for i, t := range exp.Terms {
+		parts[i] = fmt.Sprintf("%s %.2f (%s)", t.Term, t.Weight, t.From)
+	}
+	fmt.Fprintf(w, "expanded: %s\n", strings.Join(parts, " "))
+}
+
+// writeSearchTable：以文本表格写出检索结果；混合检索时末列为命中各路及名次（如 lexical#1 dense#3），
+// 重排后附带重排前的融合名次（如 fused#4）。
+func writeSearchTable(w io.Writer, sources []querySource) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, s := range sources {
+		arms := make([]string, len(s.Arms))
+		for j, a := range s.Arms {
+			arms[j] = fmt.Sprintf("%s#%d", a.Arm, a.Rank)
+		}
+		if s.FusedRank > 0 {
+			arms = append(arms, fmt.Sprintf("fused#%d", s.FusedRank))
+		}
+		fmt.Fprintf(tw, "%d\t%.4f\t%s\t%s\t%s\t%s\n", i+1, s.Score, s.Name, s.Header, s.Path, strings.Join(arms, " "))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,176 @@
+// 文件功能：server 包的单元测试；验证 serve 监听语料时变更增量写入稠密召回的向量存储、重复索引不重写存储，
+// 问答接口拒绝未知字段并提示缺失的营养数据，以及 es8.address 非法时索引命令返回错误。
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cook/internal/recipe/config"
+	"cook/internal/recipe/localstore"
+	"cook/internal/recipe/retrieval"
+	"cook/internal/recipe/vector"
+)
+
+func TestWatchDenseSink(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) {
+		full := filepath.Join(dir, "staple", name)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	write("甲.md", "# 甲的做法\n简介\n\n## 操作\n- 煮")
+
+	cfg := &config.AppConfig{
+		Parser:    config.ParserConfig{Dir: dir},
+		Embedding: config.EmbeddingConfig{Provider: "hash", Dimensions: 64},
+		Vector:    config.VectorConfig{Backend: "local", Path: filepath.Join(t.TempDir(), "vectors.bin")},
+		Retrieval: config.RetrievalConfig{Dense: config.ArmConfig{Weight: 1, TopK: 5}},
+	}
+	cat := newCatalog(cfg)
+	deltas, err := cat.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	h, err := newHybrid(cfg, cat, nil, nil)
+	if err != nil {
+		t.Fatalf("hybrid: %v", err)
+	}
+	sink := denseSink(h, nil, 0)
+	if sink == nil {
+		t.Fatal("no sink for dense arm")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := sink.Apply(ctx, deltas); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	st := h.Arms[0].Retriever.(*retrieval.Dense).Searcher.(*localstore.Store)
+	base := st.Len()
+	if base != len(deltas) {
+		t.Fatalf("store has %d docs, want %d", base, len(deltas))
+	}
+
+	go func() { _ = watchCatalog(ctx, cat, 20*time.Millisecond, sink) }()
+	time.Sleep(100 * time.Millisecond) // 等待监听建立
+	write("乙.md", "# 乙的做法\n简介\n\n## 操作\n- 蒸")
+	deadline := time.Now().Add(5 * time.Second)
+	for st.Len() <= base {
+		if time.Now().After(deadline) {
+			t.Fatalf("watch did not reach the vector store: %d docs", st.Len())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	docs, err := h.Arms[0].Retriever.Retrieve(ctx, "乙的做法", vector.SearchOptions{TopK: 5})
+	if err != nil || len(docs) == 0 {
+		t.Fatalf("retrieve after watch: %v %v", docs, err)
+	}
+
+	if denseSink(&retrieval.Hybrid{Arms: []retrieval.Arm{{Name: armLexical, Retriever: &lexicalIndex{cat: cat}}}}, nil, 0) != nil {
+		t.Fatal("sink without dense arm")
+	}
+}
+
+func TestRepeatIndexUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "soup"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "soup", "甲.md"), []byte("# 甲的做法\n简介\n\n## 操作\n- 煮"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.AppConfig{
+		Parser:    config.ParserConfig{Dir: dir},
+		Embedding: config.EmbeddingConfig{Provider: "hash", Dimensions: 64},
+		Vector:    config.VectorConfig{Backend: "local", Path: filepath.Join(t.TempDir(), "vectors.bin")},
+		Retrieval: config.RetrievalConfig{Dense: config.ArmConfig{Weight: 1, TopK: 5}},
+	}
+	ctx := context.Background()
+	index := func() {
+		cat := newCatalog(cfg)
+		deltas, err := cat.Load()
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		for _, c := range cat.Chunks() {
+			if c.Source != c.Path {
+				t.Fatalf("source %q carries more than the path", c.Source)
+			}
+		}
+		h, err := newHybrid(cfg, cat, nil, nil)
+		if err != nil {
+			t.Fatalf("hybrid: %v", err)
+		}
+		if err := denseSink(h, nil, 0).Apply(ctx, deltas); err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+	}
+	index()
+	before, err := os.Stat(cfg.Vector.Path)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	index()
+	if after, _ := os.Stat(cfg.Vector.Path); !after.ModTime().Equal(before.ModTime()) {
+		t.Fatal("repeat index rewrote the snapshot")
+	}
+}
+
+func TestQueryHandler(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "soup"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "soup", "番茄蛋汤.md"), []byte("# 番茄蛋汤的做法\n简介\n\n## 操作\n- 番茄切块煮汤"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cat := newCatalog(&config.AppConfig{Parser: config.ParserConfig{Dir: dir}})
+	if _, err := cat.Load(); err != nil {
+		t.Fatal(err)
+	}
+	h := &retrieval.Hybrid{Arms: []retrieval.Arm{{Name: armLexical, Retriever: &lexicalIndex{cat: cat}, TopK: 5, Weight: 1}}}
+	handler := queryHandler(h, 5, catalogCoverage(cat))
+	post := func(body string) (int, queryResponse) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/query", strings.NewReader(body)))
+		var out queryResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec.Code, out
+	}
+
+	if code, out := post(`{"query":"番茄"}`); code != http.StatusOK || len(out.Sources) == 0 || out.Warnings != nil {
+		t.Fatalf("plain query: %d %+v", code, out)
+	}
+	for _, body := range []string{`{"query":"番茄","dificulty":{"max":2}}`, `{"query":"番茄","filters":{"dificulty":{"max":2}}}`} {
+		if code, _ := post(body); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", body, code)
+		}
+	}
+	code, out := post(`{"query":"番茄","filters":{"kcal":{"max":400},"diets":["高蛋白","素食"]}}`)
+	if code != http.StatusOK || len(out.Warnings) != 2 || !strings.Contains(out.Warnings[0], "kcal") || !strings.Contains(out.Warnings[1], "diets:高蛋白") {
+		t.Fatalf("nutrition filter: %d %q", code, out.Warnings)
+	}
+}
+
+func TestIndexBadESAddress(t *testing.T) {
+	t.Setenv("RECIPE_AGENT_ES8_ADDRESS", "localhost:9200")
+	prev := indexStore
+	indexStore = "es"
+	defer func() { indexStore = prev }()
+	err := runIndex(context.Background(), indexCmd)
+	if err == nil || !strings.Contains(err.Error(), "index: es: bad address") {
+		t.Fatalf("runIndex with a bad es8.address: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// 文件功能：stats 子命令；解析语料并输出质量报告（表格或 JSON），用于调优分块选项与发现解析回归。
+// 包功能：server 包，封装 CLI 与 HTTP 服务相关逻辑。
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cook/internal/recipe/config"
+	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/stats"
+)
+
+// stats 子命令选项。
+var (
+	statsFormat    string // 输出格式：table|json
+	statsByHeader  bool   // 按标题分块
+	statsChunkSize int    // 长度分块的最大字符数
+	statsOverlap   int    // 长度分块的重叠字符数
+	statsMinChars  int    // 空块阈值
+	statsMaxChars  int    // 超长块阈值
+	statsTop       int    // 高频标题条数
+	statsLimit     int    // 表格中异常清单的最大条数
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report corpus quality statistics for the current chunking options",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStats()
+	},
+}
+
+func init() {
+	th := stats.DefaultThresholds()
+	f := statsCmd.Flags()
+	f.StringVar(&statsFormat, "format", "table", "output format: table|json")
+	f.BoolVar(&statsByHeader, "byHeader", true, "split chunks using Markdown headers when possible")
+	f.IntVar(&statsChunkSize, "chunk", 1200, "max characters per chunk (when not splitting by header)")
+	f.IntVar(&statsOverlap, "overlap", 100, "overlap characters between chunks")
+	f.IntVar(&statsMinChars, "min-chars", th.MinChars, "chunks with fewer body characters are reported as empty")
+	f.IntVar(&statsMaxChars, "max-chars", th.MaxChars, "chunks with more body characters are reported as oversized")
+	f.IntVar(&statsTop, "top", th.TopHeaders, "number of most common headers to report")
+	f.IntVar(&statsLimit, "limit", 20, "max entries per problem list in table output (0 for all)")
+}
+
+// runStats：解析语料并输出质量报告。
+// 返回值说明：
+//   - error：配置、收集、解析或写出失败，以及格式非法时返回错误。
+func runStats() error {
+	if statsFormat != "table" && statsFormat != "json" {
+		return fmt.Errorf("stats: unknown format %q", statsFormat)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	reg := newRegistry(cfg)
+	files, err := reg.Collect(cfg.Parser.Dir)
+	if err != nil {
+		return fmt.Errorf("stats: %w", err)
+	}
+	opts := types.Options{ByHeader: statsByHeader, ChunkSize: statsChunkSize, Overlap: statsOverlap, Root: cfg.Parser.Dir}
+	chunks, err := reg.ParseFiles(files, opts)
+	if err != nil {
+		return fmt.Errorf("stats: %w", err)
+	}
+	th := stats.DefaultThresholds()
+	th.MinChars, th.MaxChars, th.TopHeaders = statsMinChars, statsMaxChars, statsTop
+	r := stats.Compute(chunks, th)
+	if statsFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	return stats.WriteTable(os.Stdout, r, statsLimit)
+}
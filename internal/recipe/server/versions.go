@@ -0,0 +1,190 @@
+// 文件功能：Elasticsearch 版本化索引；index 命令写入新版本并在校验后切换别名，index list/rollback 子命令查看与回滚版本。
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cook/internal/recipe/config"
+	"cook/internal/recipe/embedding"
+	"cook/internal/recipe/es"
+	"cook/internal/recipe/parser/types"
+	"cook/internal/recipe/vector"
+)
+
+// index list 子命令选项。
+var indexListFormat string // 输出格式：table|json
+
+var indexListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the versioned Elasticsearch indexes behind es8.index",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIndexList(cmd.Context())
+	},
+}
+
+var indexRollbackCmd = &cobra.Command{
+	Use:   "rollback [version]",
+	Short: "Point es8.index back at an earlier version (default: the one before the current)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to := ""
+		if len(args) == 1 {
+			to = args[0]
+		}
+		return runIndexRollback(cmd.Context(), to)
+	},
+}
+
+func init() {
+	indexListCmd.Flags().StringVar(&indexListFormat, "format", "table", "output format: table|json")
+	indexCmd.AddCommand(indexListCmd)
+	indexCmd.AddCommand(indexRollbackCmd)
+}
+
+// newVersions：按 es8 配置构造别名的版本管理。
+func newVersions(cfg *config.AppConfig) (*es.Versions, error) {
+	c, err := newESClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return es.NewVersions(c, cfg.ES8.Index, cfg.ES8.KeepVersions), nil
+}
+
+// release：一次全量索引写入的版本索引。
+type release struct {
+	versions *es.Versions
+	index    string
+}
+
+// newRelease：创建本次写入的版本索引名并返回写入该索引的存储；索引在首次写入时按向量维度建立，已存在时写入失败。
+func newRelease(cfg *config.AppConfig) (*es.Store, *release, error) {
+	c, err := newESClient(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := es.NewVersions(c, cfg.ES8.Index, cfg.ES8.KeepVersions)
+	r := &release{versions: v, index: v.Name(time.Now())}
+	return v.NewStore(r.index, cfg.ES8.BulkSize), r, nil
+}
+
+// promote：校验版本索引后切换别名并清理超出保留数的旧版本。
+// 功能说明：文档数须等于 chunks 数；冒烟查询以首个分块的正文向量检索，该分块须出现在结果前列。
+// 校验失败时删除本次的版本索引，别名保持不变。
+func (r *release) promote(ctx context.Context, emb embedding.Embedder, chunks []types.Chunk) error {
+	var probeID string
+	var probe []float64
+	if len(chunks) > 0 {
+		d := vector.FromChunk(chunks[0])
+		vecs, err := emb.EmbedStrings(ctx, []string{d.Content})
+		if err != nil {
+			return fmt.Errorf("smoke query: %w", err)
+		}
+		probeID, probe = d.ID, vecs[0]
+	}
+	if err := r.versions.Check(ctx, r.index, len(chunks), probeID, probe); err != nil {
+		if derr := r.versions.Drop(ctx, r.index); derr != nil {
+			return fmt.Errorf("%w (and deleting %s failed: %v)", err, r.index, derr)
+		}
+		return fmt.Errorf("%w; deleted %s, %s unchanged", err, r.index, r.versions.Alias)
+	}
+	prev, err := r.versions.Current(ctx)
+	if err != nil {
+		return err
+	}
+	if err := r.versions.Swap(ctx, r.index, indexReplace); err != nil {
+		return err
+	}
+	if prev == "" {
+		prev = "none"
+	}
+	fmt.Fprintf(os.Stderr, "alias %s -> %s (was %s)\n", r.versions.Alias, r.index, prev)
+	removed, err := r.versions.Trim(ctx)
+	if len(removed) > 0 {
+		fmt.Fprintf(os.Stderr, "deleted old versions: %s\n", strings.Join(removed, ", "))
+	}
+	return err
+}
+
+// runIndexList：列出版本索引；当前版本以 * 标记。
+func runIndexList(ctx context.Context) error {
+	if indexListFormat != "table" && indexListFormat != "json" {
+		return fmt.Errorf("index: unknown format %q", indexListFormat)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	v, err := newVersions(cfg)
+	if err != nil {
+		return fmt.Errorf("index: %w", err)
+	}
+	versions, err := v.List(ctx)
+	if err != nil {
+		return fmt.Errorf("index: %w", err)
+	}
+	if indexListFormat == "json" {
+		if versions == nil {
+			versions = []es.Version{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(versions)
+	}
+	if len(versions) == 0 {
+		fmt.Fprintf(os.Stderr, "no versions of %s; run `recipe-agent index --store es`\n", v.Alias)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, ver := range versions {
+		mark := " "
+		if ver.Current {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d docs\n", mark, ver.Index, ver.Created.Local().Format(time.DateTime), ver.Docs)
+	}
+	return w.Flush()
+}
+
+// runIndexRollback：将别名切回 to；to 为空时切回当前版本之前最近的版本。目标版本为空索引时拒绝切换。
+func runIndexRollback(ctx context.Context, to string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	v, err := newVersions(cfg)
+	if err != nil {
+		return fmt.Errorf("index: %w", err)
+	}
+	if to == "" {
+		prev, err := v.Previous(ctx)
+		if err != nil {
+			return fmt.Errorf("index: rollback: %w", err)
+		}
+		to = prev.Index
+	}
+	n, err := v.Count(ctx, to)
+	if err != nil {
+		return fmt.Errorf("index: rollback: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("index: rollback: %s is empty", to)
+	}
+	cur, err := v.Current(ctx)
+	if err != nil {
+		return fmt.Errorf("index: rollback: %w", err)
+	}
+	if err := v.Swap(ctx, to, false); err != nil {
+		return fmt.Errorf("index: rollback: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "alias %s -> %s (was %s, %d docs)\n", v.Alias, to, cur, n)
+	return nil
+}
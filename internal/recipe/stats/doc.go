@@ -0,0 +1,3 @@
+// 文件功能：语料质量统计包说明。
+// 包功能：stats 包，统计解析产物的文档分布、分块长度与 Token 直方图，并列出空块、超长块、缺失标准章节的菜谱与高频标题，用于调优 types.Options 与发现解析回归。
+package stats
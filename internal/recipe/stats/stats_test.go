@@ -0,0 +1,67 @@
+// 文件功能：语料质量统计的单元测试；验证分类计数、直方图归桶、异常分块与缺失章节识别。
+package stats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"cook/internal/recipe/parser/types"
+)
+
+func TestCompute(t *testing.T) {
+	chunks := []types.Chunk{
+		{ID: "1", DocID: "a", Category: "meat", Path: "meat/a.md", Header: "# 甲的做法", Text: "一道家常菜，做法简单。"},
+		{ID: "2", DocID: "a", Category: "meat", Path: "meat/a.md", Header: "## 必备原料和工具", Section: "必备原料和工具", Text: "- 盐"},
+		{ID: "3", DocID: "a", Category: "meat", Path: "meat/a.md", Header: "## 操作", Section: "操作", Text: ""},
+		{ID: "4", DocID: "b", Category: "soup", Path: "soup/b.md", Header: "# chunk 0", Text: "## 计算\n" + strings.Repeat("水", 30)},
+	}
+	th := Thresholds{MinChars: 5, MaxChars: 20, Sections: []string{"必备原料和工具", "计算", "操作"}, TopHeaders: 2}
+	r := Compute(chunks, th)
+
+	if r.Documents != 2 || r.Chunks != 4 {
+		t.Fatalf("counts: %d docs %d chunks", r.Documents, r.Chunks)
+	}
+	wantCats := []CategoryCount{{"meat", 1, 3}, {"soup", 1, 1}}
+	if !reflect.DeepEqual(r.Categories, wantCats) {
+		t.Fatalf("categories: %+v", r.Categories)
+	}
+	if len(r.Empty) != 2 || r.Empty[0].ID != "2" || r.Empty[1].ID != "3" {
+		t.Fatalf("empty: %+v", r.Empty)
+	}
+	if len(r.Oversized) != 1 || r.Oversized[0].ID != "4" {
+		t.Fatalf("oversized: %+v", r.Oversized)
+	}
+	wantMissing := []MissingSections{
+		{Path: "meat/a.md", Missing: []string{"计算"}},
+		{Path: "soup/b.md", Missing: []string{"必备原料和工具", "操作"}},
+	}
+	if !reflect.DeepEqual(r.Missing, wantMissing) {
+		t.Fatalf("missing: %+v", r.Missing)
+	}
+	if r.ChunksPerDoc.Min != 1 || r.ChunksPerDoc.Max != 3 || r.ChunksPerDoc.Histogram[0].Count != 1 {
+		t.Fatalf("chunks per doc: %+v", r.ChunksPerDoc)
+	}
+	if len(r.TopHeaders) != 2 || r.TopHeaders[0].Count != 1 {
+		t.Fatalf("top headers: %+v", r.TopHeaders)
+	}
+	var b strings.Builder
+	if err := WriteTable(&b, r, 1); err != nil || !strings.Contains(b.String(), "1 more") {
+		t.Fatalf("table: %v\n%s", err, b.String())
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	cases := map[string]int{
+		"":                0,
+		"红烧肉":             3,
+		"add salt":        2,
+		"猪肉 500g。":        4, // 猪、肉、500g、。
+		"temperature 180": 4, // 11 个字母计 3，数字计 1
+	}
+	for in, want := range cases {
+		if got := EstimateTokens(in); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
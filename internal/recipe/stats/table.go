@@ -0,0 +1,88 @@
+// 文件功能：质量报告的文本表格输出；便于在终端直接阅读与对比。
+package stats
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// barWidth：直方图条形的最大宽度（字符）。
+const barWidth = 40
+
+// WriteTable：以对齐的文本表格写出报告。
+// 参数说明：
+//   - w：输出目标；
+//   - r：质量报告；
+//   - limit：异常清单（空块、超长块、缺失章节）各自最多列出的条数；≤0 表示不限。
+//
+// 返回值说明：
+//   - error：写出失败时返回错误。
+func WriteTable(w io.Writer, r *Report, limit int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "documents\t%d\nchunks\t%d\n\n", r.Documents, r.Chunks)
+
+	fmt.Fprintln(tw, "CATEGORY\tDOCS\tCHUNKS")
+	for _, c := range r.Categories {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", c.Category, c.Documents, c.Chunks)
+	}
+	fmt.Fprintln(tw)
+
+	writeDistribution(tw, "chunks per doc", r.ChunksPerDoc)
+	writeDistribution(tw, "chunk length (chars)", r.Length)
+	writeDistribution(tw, "chunk tokens (estimated)", r.Tokens)
+
+	writeRefs(tw, "empty or near-empty chunks", r.Empty, limit)
+	writeRefs(tw, "oversized chunks", r.Oversized, limit)
+
+	fmt.Fprintf(tw, "recipes missing standard sections: %d\n", len(r.Missing))
+	for i, m := range r.Missing {
+		if limit > 0 && i == limit {
+			fmt.Fprintf(tw, "  ...\t%d more\n", len(r.Missing)-limit)
+			break
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", m.Path, strings.Join(m.Missing, ", "))
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "HEADER\tCOUNT")
+	for _, h := range r.TopHeaders {
+		fmt.Fprintf(tw, "%s\t%d\n", h.Header, h.Count)
+	}
+	return tw.Flush()
+}
+
+// writeDistribution：写出摘要行与直方图。
+func writeDistribution(w io.Writer, title string, d Distribution) {
+	fmt.Fprintf(w, "%s: min=%d p50=%d p90=%d p99=%d max=%d mean=%.1f\n", title, d.Min, d.P50, d.P90, d.P99, d.Max, d.Mean)
+	peak := 0
+	for _, b := range d.Histogram {
+		peak = max(peak, b.Count)
+	}
+	for _, b := range d.Histogram {
+		label := fmt.Sprintf("%d-%d", b.Min, b.Max)
+		if b.Max < 0 {
+			label = fmt.Sprintf(">%d", b.Min)
+		}
+		n := 0
+		if peak > 0 {
+			n = (b.Count*barWidth + peak - 1) / peak
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%s\n", label, b.Count, strings.Repeat("#", n))
+	}
+	fmt.Fprintln(w)
+}
+
+// writeRefs：写出异常分块清单。
+func writeRefs(w io.Writer, title string, refs []ChunkRef, limit int) {
+	fmt.Fprintf(w, "%s: %d\n", title, len(refs))
+	for i, c := range refs {
+		if limit > 0 && i == limit {
+			fmt.Fprintf(w, "  ...\t%d more\n", len(refs)-limit)
+			break
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%d chars\n", c.Path, c.Header, c.Chars)
+	}
+	fmt.Fprintln(w)
+}
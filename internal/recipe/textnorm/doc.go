@@ -0,0 +1,3 @@
+// 文件功能：文本归一化包说明。
+// 包功能：textnorm 包，为索引与查询生成统一的检索键：繁体折叠为简体、全角折叠为半角、CJK 标点折叠为 ASCII 标点；展示文本保持原样。
+package textnorm
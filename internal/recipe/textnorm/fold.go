@@ -0,0 +1,121 @@
+// 文件功能：检索键折叠；逐字符一对一映射，折叠结果与原文按 rune 位置对齐，便于将命中位置映射回原文高亮。
+package textnorm
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+//go:embed t2s.tsv
+var t2sData string
+
+var (
+	t2sOnce sync.Once
+	t2s     map[rune]rune
+)
+
+// punct：CJK 标点到 ASCII 标点的映射（全角 ASCII 区段另行按码位换算）。
+var punct = map[rune]rune{
+	'　': ' ', // 全角空格
+	'。': '.',
+	'、': ',',
+	'「': '"',
+	'」': '"',
+	'『': '"',
+	'』': '"',
+	'“': '"',
+	'”': '"',
+	'‘': '\'',
+	'’': '\'',
+	'【': '[',
+	'】': ']',
+	'〔': '[',
+	'〕': ']',
+	'《': '<',
+	'》': '>',
+	'〈': '<',
+	'〉': '>',
+	'—': '-',
+	'–': '-',
+	'·': '.',
+	'・': '.',
+	'…': '.',
+}
+
+// loadT2S：解析内嵌映射表；仅在首次使用时执行。
+func loadT2S() {
+	t2s = make(map[rune]rune, 2600)
+	for _, line := range strings.Split(t2sData, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		from, to, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		f, fn := utf8.DecodeRuneInString(from)
+		t, tn := utf8.DecodeRuneInString(to)
+		if fn == len(from) && tn == len(to) {
+			t2s[f] = t
+		}
+	}
+}
+
+// Simplify：单字繁体→简体；不在映射表中的字符原样返回。
+func Simplify(r rune) rune {
+	t2sOnce.Do(loadT2S)
+	if s, ok := t2s[r]; ok {
+		return s
+	}
+	return r
+}
+
+// Width：全角→半角。全角 ASCII（U+FF01–U+FF5E）换算为对应 ASCII，CJK 标点折叠为含义相近的 ASCII 标点。
+func Width(r rune) rune {
+	if r >= 0xFF01 && r <= 0xFF5E {
+		return r - 0xFEE0
+	}
+	if p, ok := punct[r]; ok {
+		return p
+	}
+	return r
+}
+
+// FoldRune：单字符检索键折叠（繁→简、全角→半角、标点、大写→小写）。
+func FoldRune(r rune) rune {
+	r = Width(r)
+	if r < utf8.RuneSelf {
+		if 'A' <= r && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		return r
+	}
+	return Simplify(r)
+}
+
+// Fold：生成检索键。
+// 功能说明：逐字符执行 FoldRune；输出与输入的 rune 数相同，第 i 个 rune 对应原文第 i 个 rune。
+// 索引时对正文、标题等字段折叠后建立倒排或向量，查询时对用户输入同样折叠，即可跨繁简与全半角匹配；
+// 返回给用户的展示文本仍使用原文。
+// 示例：Fold("豉汁蒸白鯧（２人份）") == "豉汁蒸白鲳(2人份)"。
+func Fold(s string) string {
+	for i, r := range s {
+		if FoldRune(r) != r {
+			var b strings.Builder
+			b.Grow(len(s))
+			b.WriteString(s[:i])
+			for _, r := range s[i:] {
+				b.WriteRune(FoldRune(r))
+			}
+			return b.String()
+		}
+	}
+	return s
+}
+
+// Contains：按检索键判断 s 是否包含 substr。
+func Contains(s, substr string) bool {
+	return strings.Contains(Fold(s), Fold(substr))
+}
@@ -0,0 +1,49 @@
+// 文件功能：检索键折叠的单元测试；验证繁简、全半角、标点折叠与 rune 位置对齐，以及映射表自洽。
+package textnorm
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFold(t *testing.T) {
+	cases := map[string]string{
+		"豉汁蒸白鯧（２人份）": "豉汁蒸白鲳(2人份)",
+		"預估烹飪難度：★★":  "预估烹饪难度:★★",
+		"「紅燒鱔魚」、麵條。": "\"红烧鳝鱼\",面条.",
+		"ＡＢＣ　Ｐａｎ":    "abc pan",
+		"已经是简体的文本":   "已经是简体的文本",
+		"糊涂，溜肉段":     "糊涂,溜肉段",
+	}
+	for in, want := range cases {
+		got := Fold(in)
+		if got != want {
+			t.Errorf("Fold(%q) = %q, want %q", in, got, want)
+		}
+		if utf8.RuneCountInString(got) != utf8.RuneCountInString(in) {
+			t.Errorf("Fold(%q) changed rune count", in)
+		}
+	}
+	if !Contains("豉汁蒸白鲳", "白鯧") || !Contains("豉汁蒸白鯧", "白鲳") {
+		t.Fatal("Contains should match across scripts")
+	}
+}
+
+func TestT2STable(t *testing.T) {
+	t2sOnce.Do(loadT2S)
+	lines := 0
+	for _, l := range strings.Split(t2sData, "\n") {
+		if l != "" && !strings.HasPrefix(l, "#") {
+			lines++
+		}
+	}
+	if len(t2s) != lines || lines < 2000 {
+		t.Fatalf("table: %d entries from %d lines", len(t2s), lines)
+	}
+	for from, to := range t2s {
+		if _, ok := t2s[to]; ok {
+			t.Errorf("%c→%c: target is itself mapped, folding would not be idempotent", from, to)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// 文件功能：用量归一与换算；计件与体积按食材表换算为质量或其他单位，并按单位体系选择展示单位。
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 换算错误。
+var (
+	ErrUnknownUnit  = errors.New("unknown unit")
+	ErrNoConversion = errors.New("no conversion")
+)
+
+// Measure：一个用量。
+//   - Value：数值；
+//   - Unit：规范单位名称（g、ml、tbsp、瓣……）；
+//   - Dim：量纲；
+//   - Exact：是否为精确换算；经约定量具、密度或单件重量换算的结果为估算；
+//   - Basis：估算依据（如「1 瓣 蒜 ≈ 5 g」）；精确换算时为空。
+type Measure struct {
+	Value float64   `json:"value"`           // 数值
+	Unit  string    `json:"unit"`            // 单位
+	Dim   Dimension `json:"-"`               // 量纲
+	Exact bool      `json:"exact"`           // 是否精确
+	Basis []string  `json:"basis,omitempty"` // 估算依据
+}
+
+// String：展示文本；估算值以「≈」开头，数值最多保留两位小数。
+func (m Measure) String() string {
+	s := formatValue(m.Value) + " " + m.Unit
+	if !m.Exact {
+		s = "≈" + s
+	}
+	return s
+}
+
+// Normalize：将用量归一为公制基准。
+// 功能说明：
+//  1. 质量单位换算为 g，体积单位换算为 ml；约定量具（勺、杯、碗）按常用容量估算；
+//  2. 计件单位按食材单件重量换算为 g（估算）；表中未收录时保持计件单位，Dim 为 Count。
+//
+// 参数说明：
+//   - ingredient：食材名；仅计件单位需要；
+//   - value：数值；
+//   - unit：单位写法（g、克、勺、瓣、一小块中的「小块」……）。
+//
+// 返回值说明：
+//   - Measure：归一结果；
+//   - error：单位无法识别时返回 ErrUnknownUnit。
+func Normalize(ingredient string, value float64, unit string) (Measure, error) {
+	u, ok := Lookup(unit)
+	if !ok {
+		return Measure{}, fmt.Errorf("units: %w %q", ErrUnknownUnit, unit)
+	}
+	m := Measure{Value: value, Unit: u.Name, Dim: u.Dim, Exact: true}
+	if u.Dim != Count {
+		return toBase(m, u), nil
+	}
+	g, ok := m.grams(ingredient, u)
+	if !ok {
+		if u.Factor != 1 {
+			m = Measure{Value: value * u.Factor, Unit: u.piece, Dim: Count, Basis: []string{fmt.Sprintf("1 %s ≈ %s %s", u.Name, formatValue(u.Factor), u.piece)}}
+		}
+		return m, nil
+	}
+	return g, nil
+}
+
+// Convert：将用量换算为目标单位。
+// 功能说明：同量纲直接按系数换算；质量与体积之间经食材密度换算；计件与质量/体积之间经单件重量换算；
+// 任一环节为估算时结果标记为估算，Basis 累积各环节的依据。
+// 参数说明：
+//   - ingredient：食材名；跨量纲换算时用于查表；
+//   - m：待换算用量（Unit 须为可识别的单位）；
+//   - target：目标单位写法。
+//
+// 返回值说明：
+//   - Measure：换算结果；
+//   - error：单位无法识别时返回 ErrUnknownUnit；缺少密度或单件重量时返回 ErrNoConversion。
+func Convert(ingredient string, m Measure, target string) (Measure, error) {
+	from, ok := Lookup(m.Unit)
+	if !ok {
+		return Measure{}, fmt.Errorf("units: %w %q", ErrUnknownUnit, m.Unit)
+	}
+	to, ok := Lookup(target)
+	if !ok {
+		return Measure{}, fmt.Errorf("units: %w %q", ErrUnknownUnit, target)
+	}
+	cur := m
+	cur.Dim = from.Dim
+	cur.Basis = append([]string(nil), m.Basis...)
+	if from.Dim == Count {
+		if to.Dim == Count && from.piece == to.piece {
+			out := scale(cur, from.Factor/to.Factor, to)
+			if from.Factor != to.Factor {
+				// 「小块」「大块」相对标准一件的倍数为约定值
+				out.Exact = false
+				out.Basis = append(out.Basis, fmt.Sprintf("1 %s ≈ %s %s", from.Name, formatValue(from.Factor/to.Factor), to.Name))
+			}
+			return out, nil
+		}
+		g, ok := cur.grams(ingredient, from)
+		if !ok {
+			return Measure{}, fmt.Errorf("units: %w from %s of %q", ErrNoConversion, from.Name, ingredient)
+		}
+		cur = g
+	} else {
+		cur = toBase(cur, from)
+	}
+	switch {
+	case cur.Dim == to.Dim:
+	case to.Dim == Count:
+		if cur.Dim == Volume {
+			if cur, ok = cur.viaDensity(ingredient, Mass); !ok {
+				return Measure{}, fmt.Errorf("units: %w from ml of %q", ErrNoConversion, ingredient)
+			}
+		}
+		w, ok := PieceWeight(ingredient, to.piece)
+		if !ok {
+			return Measure{}, fmt.Errorf("units: %w to %s of %q", ErrNoConversion, to.Name, ingredient)
+		}
+		w *= to.Factor
+		cur.Basis = append(cur.Basis, fmt.Sprintf("1 %s %s ≈ %s g", to.Name, ingredient, formatValue(w)))
+		return Measure{Value: cur.Value / w, Unit: to.Name, Dim: Count, Basis: cur.Basis}, nil
+	default:
+		if cur, ok = cur.viaDensity(ingredient, to.Dim); !ok {
+			return Measure{}, fmt.Errorf("units: %w between mass and volume of %q", ErrNoConversion, ingredient)
+		}
+	}
+	return scale(cur, 1/to.Factor, to), nil
+}
+
+// Display：按单位体系选择易读的展示单位。
+// 功能说明：
+//   - metric：≥1000 g/ml 用 kg/l，否则 g/ml；
+//   - imperial：质量不足 1 lb 用 oz，否则 lb；体积依次选 tsp、tbsp、cup；
+//   - chinese：质量 ≥500 g 用斤、≥50 g 用两，否则 g；体积同 metric；
+//
+// 计件用量先尝试换算为质量；无法换算时原样返回。
+// 参数说明：
+//   - ingredient：食材名；
+//   - m：用量；
+//   - system：单位体系（Metric/Imperial/Chinese）。
+//
+// 返回值说明：
+//   - Measure：展示用量；
+//   - error：单位体系或单位无法识别时返回错误。
+func Display(ingredient string, m Measure, system string) (Measure, error) {
+	u, ok := Lookup(m.Unit)
+	if !ok {
+		return Measure{}, fmt.Errorf("units: %w %q", ErrUnknownUnit, m.Unit)
+	}
+	base := m
+	base.Dim = u.Dim
+	if u.Dim == Count {
+		g, ok := base.grams(ingredient, u)
+		if !ok {
+			return m, nil
+		}
+		base = g
+	} else {
+		base = toBase(base, u)
+	}
+	var target string
+	switch system {
+	case Metric, Chinese:
+		target = Gram
+		if base.Dim == Volume {
+			target = Milliliter
+		}
+		if base.Value >= 1000 {
+			target = map[string]string{Gram: "kg", Milliliter: "l"}[target]
+		} else if system == Chinese && base.Dim == Mass {
+			switch {
+			case base.Value >= 500:
+				target = "斤"
+			case base.Value >= 50:
+				target = "两"
+			}
+		}
+	case Imperial:
+		switch {
+		case base.Dim == Mass && base.Value < byName["lb"].Factor:
+			target = "oz"
+		case base.Dim == Mass:
+			target = "lb"
+		case base.Value < byName["tbsp"].Factor:
+			target = "tsp"
+		case base.Value < byName["cup"].Factor/4:
+			target = "tbsp"
+		default:
+			target = "cup"
+		}
+	default:
+		return Measure{}, fmt.Errorf("units: unknown system %q", system)
+	}
+	return Convert(ingredient, base, target)
+}
+
+// toBase：同量纲换算为基准单位（g/ml）。
+func toBase(m Measure, u Unit) Measure {
+	base := Gram
+	if u.Dim == Volume {
+		base = Milliliter
+	}
+	out := Measure{Value: m.Value * u.Factor, Unit: base, Dim: u.Dim, Exact: m.Exact && u.Exact, Basis: m.Basis}
+	if !u.Exact {
+		out.Basis = append(out.Basis, fmt.Sprintf("1 %s ≈ %s %s", u.Name, formatValue(u.Factor), base))
+	}
+	return out
+}
+
+// scale：按系数换算为目标单位；目标单位为约定量具时结果为估算。
+func scale(m Measure, factor float64, to Unit) Measure {
+	out := Measure{Value: m.Value * factor, Unit: to.Name, Dim: to.Dim, Exact: m.Exact && to.Exact, Basis: m.Basis}
+	if !to.Exact && to.Dim != Count {
+		base := Gram
+		if to.Dim == Volume {
+			base = Milliliter
+		}
+		out.Basis = append(out.Basis, fmt.Sprintf("1 %s ≈ %s %s", to.Name, formatValue(to.Factor), base))
+	}
+	return out
+}
+
+// grams：计件用量按单件重量换算为 g（估算）。
+func (m Measure) grams(ingredient string, u Unit) (Measure, bool) {
+	w, ok := PieceWeight(ingredient, u.piece)
+	if !ok {
+		return Measure{}, false
+	}
+	w *= u.Factor
+	basis := append(append([]string(nil), m.Basis...), fmt.Sprintf("1 %s %s ≈ %s g", u.Name, strings.TrimSpace(ingredient), formatValue(w)))
+	return Measure{Value: m.Value * w, Unit: Gram, Dim: Mass, Basis: basis}, true
+}
+
+// viaDensity：基准单位下的质量与体积互换（估算）。
+func (m Measure) viaDensity(ingredient string, to Dimension) (Measure, bool) {
+	d, ok := Density(ingredient)
+	if !ok {
+		return Measure{}, false
+	}
+	basis := append(m.Basis, fmt.Sprintf("%s ≈ %s g/ml", strings.TrimSpace(ingredient), formatValue(d)))
+	if to == Mass {
+		return Measure{Value: m.Value * d, Unit: Gram, Dim: Mass, Basis: basis}, true
+	}
+	return Measure{Value: m.Value / d, Unit: Milliliter, Dim: Volume, Basis: basis}, true
+}
+
+// formatValue：数值文本；最多两位小数，去掉末尾的 0。
+func formatValue(v float64) string {
+	s := strconv.FormatFloat(math.Round(v*100)/100, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
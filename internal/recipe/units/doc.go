@@ -0,0 +1,5 @@
+// 文件功能：单位换算包说明。
+// 包功能：units 包，将菜谱中混用的质量、体积与计件单位（g、ml、克、勺、个、根、瓣……）归一为公制基准（g/ml），
+// 借助食材密度与单件重量表跨量纲换算，并可转换为用户偏好的展示单位（含英制）；每个结果标明是精确换算还是估算。
+// 另提供用量文本解析：中文数字、分数、范围与约数（「两勺」「十几个」「3~5 片」「约 350g」）解析为带不确定标记的数量。
+package units
@@ -0,0 +1,535 @@
+// 文件功能：用量文本解析；识别阿拉伯数字、中文数字、分数、带分数、范围与「约/左右/多/来」等约数标记，输出带不确定标记的数值。
+package units
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cook/internal/recipe/textnorm"
+)
+
+// Amount：数量。
+//   - Value：代表值；范围与约数取区间中点；
+//   - Min/Max：取值区间；精确数量时与 Value 相同；
+//   - Approx：是否为不确定数量（范围、「约」「左右」「十几」「多」等）。
+type Amount struct {
+	Value  float64 `json:"value"`  // 代表值
+	Min    float64 `json:"min"`    // 下限
+	Max    float64 `json:"max"`    // 上限
+	Approx bool    `json:"approx"` // 是否不确定
+}
+
+// Quantity：从文本中识别的一个用量。
+//   - Ingredient：食材名；仅 ExtractItem 从原料行中识别，步骤正文中为空；
+//   - Amount：数量；
+//   - Unit：规范单位名称（见 Lookup）；
+//   - Text：原文片段。
+type Quantity struct {
+	Ingredient string `json:"ingredient,omitempty"` // 食材
+	Amount     Amount `json:"amount"`               // 数量
+	Unit       string `json:"unit"`                 // 单位
+	Text       string `json:"text"`                 // 原文
+}
+
+// Measure：按代表值归一为公制基准，见 Normalize。
+func (q Quantity) Measure() (Measure, error) {
+	return Normalize(q.Ingredient, q.Amount.Value, q.Unit)
+}
+
+// 约数标记；前缀按长度优先匹配。
+var (
+	approxPrefixes = []string{"大约", "大概", "差不多", "将近", "约莫", "约", "近", "≈", "~"}
+	approxSuffixes = []string{"左右", "上下", "出头"}
+	rangeSeps      = []string{"-", "~", "〜", "到", "至"}
+	// notBefore：紧邻数字之前时表明是序数或指代（「第一个」「另一个锅」「同一个位置」「每一块」）。
+	notBefore = map[rune]bool{'第': true, '另': true, '同': true, '每': true, '任': true, '某': true, '哪': true, '唯': true, '逐': true, '单': true}
+	// notAfterUnit：紧随单位之后时表明并非用量（「两个小时」「一两分钟」「2 个人」「十块钱」）。
+	notAfterUnit = []string{"小时", "钟头", "时辰", "时间", "月", "星期", "礼拜", "周", "人", "字", "步", "部分", "方向", "面", "侧", "次", "遍", "分钟", "秒", "天", "钱", "儿"}
+)
+
+// 中文数字。
+var (
+	cnDigits = map[rune]float64{'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	cnMults  = map[rune]float64{'十': 10, '百': 100, '千': 1000, '万': 10000}
+	vulgar   = map[rune]float64{'½': 0.5, '⅓': 1.0 / 3, '⅔': 2.0 / 3, '¼': 0.25, '¾': 0.75, '⅛': 0.125}
+)
+
+// unitNames：可识别的单位写法（规范名与别名），按长度降序，供最长匹配。
+var unitNames = func() []string {
+	out := make([]string, 0, len(byName)+len(aliases))
+	for k := range byName {
+		out = append(out, k)
+	}
+	for k := range aliases {
+		out = append(out, k)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if li, lj := len([]rune(out[i])), len([]rune(out[j])); li != lj {
+			return li > lj
+		}
+		return out[i] < out[j]
+	})
+	return out
+}()
+
+// ParseAmount：将整段文本解析为数量（如「两」「十几」「1/2」「3~5」「约 350」「三分之一」）。
+// 返回值说明：
+//   - Amount：数量；
+//   - bool：文本不是单一数量时返回 false。
+func ParseAmount(s string) (Amount, bool) {
+	rs := []rune(textnorm.Fold(strings.TrimSpace(s)))
+	a, end, ok := amountAt(rs, 0)
+	if !ok {
+		return Amount{}, false
+	}
+	end = skipSpace(rs, end)
+	if n, ok := matchAny(rs, end, approxSuffixes); ok {
+		a = approx(a)
+		end = n
+	}
+	return a, end == len(rs)
+}
+
+// Extract：识别正文中的全部「数量 + 单位」用量（如「加入两勺生抽」「煮 3~5 片姜」「约 350g」）。
+// 功能说明：文本先经 textnorm.Fold 折叠（全角数字与符号、繁体单位均可识别），Text 取原文片段；
+// 只有数字、没有单位的数字（步骤序号、时间、温度）不视为用量；「两个小时」「2 个人」等按上下文排除。
+func Extract(text string) []Quantity {
+	orig := []rune(text)
+	rs := []rune(textnorm.Fold(text))
+	var out []Quantity
+	for i := 0; i < len(rs); {
+		if q, end, ok := quantityAt(rs, i); ok {
+			q.Text = strings.TrimSpace(string(orig[i:end]))
+			out = append(out, q)
+			i = end
+			continue
+		}
+		i++
+	}
+	return out
+}
+
+// ExtractItem：识别原料行中的用量，并以用量前（或用量后）的文字作为食材名。
+// 功能说明：括号内的补充说明与「#」之后的注释不参与识别（「猪油 15 g（添加蚝油时，减少到 10 g）」只取 15 g）；
+// 食材名写在用量之前（「土豆 2 个」）或之后（「2 cups flour」「两个鸡蛋」）均可。
+// 返回值说明：
+//   - []Quantity：用量（同一行可有多个，如「盐 3g 糖 5g」时后者的食材名为两者之间的文字）。
+func ExtractItem(line string) []Quantity {
+	line = stripNotes(line)
+	orig := []rune(line)
+	qs := Extract(line)
+	if len(qs) == 0 {
+		return nil
+	}
+	// 关键逻辑：按片段位置切分行文本；每个用量取其前方到上一个用量之间的文字作为食材名，首个用量前无文字时取其后的文字。
+	pos := 0
+	for i := range qs {
+		start := indexRunes(orig, []rune(qs[i].Text), pos)
+		if start < 0 {
+			break
+		}
+		qs[i].Ingredient = trimName(string(orig[pos:start]))
+		pos = start + len([]rune(qs[i].Text))
+		if i == 0 && qs[i].Ingredient == "" {
+			next := len(orig)
+			if len(qs) > 1 {
+				if n := indexRunes(orig, []rune(qs[1].Text), pos); n >= 0 {
+					next = n
+				}
+			}
+			qs[i].Ingredient = trimName(string(orig[pos:next]))
+			pos = next
+		}
+	}
+	return qs
+}
+
+// quantityAt：尝试在位置 i 识别「约数前缀 + 数量 + 单位 + 约数后缀」；返回用量与结束位置。
+// 位置 i 紧接在中文数字之后时不识别，避免从「三五片」的「五」开始只取后半截。
+func quantityAt(rs []rune, i int) (Quantity, int, bool) {
+	if i > 0 && (notBefore[rs[i-1]] || isASCIIAlnum(rs[i-1]) || isCNNumeral(rs[i-1]) || rs[i-1] == '.' || rs[i-1] == '/') {
+		return Quantity{}, 0, false
+	}
+	if a, end, ok := amountAt(rs, i); ok {
+		if unit, k, ok := unitAt(rs, skipSpace(rs, end)); ok {
+			return finishQuantity(rs, a, unit, k)
+		}
+	}
+	// 「一两猪肉」「二两肉」：中文数字串结尾的「两」是市制单位而非数字（「二两」整体不是合法数字，须单独处理）
+	end := i
+	if n, ok := matchAny(rs, end, approxPrefixes); ok {
+		end = skipSpace(rs, n)
+	}
+	for end < len(rs) && isCNNumeral(rs[end]) {
+		end++
+	}
+	if end-i >= 2 && rs[end-1] == '两' {
+		if b, e, ok := amountAt(rs[:end-1], i); ok && e == end-1 {
+			return finishQuantity(rs, b, "两", end)
+		}
+	}
+	return Quantity{}, 0, false
+}
+
+// finishQuantity：处理单位后的「半」「多」与约数后缀，并排除「两个小时」等非用量上下文。
+func finishQuantity(rs []rune, a Amount, unit string, k int) (Quantity, int, bool) {
+	u, _ := Lookup(unit)
+	if k < len(rs) && rs[k] == '半' && a.Min == a.Max && a.Value == math.Trunc(a.Value) {
+		a = Amount{Value: a.Value + 0.5, Min: a.Value + 0.5, Max: a.Value + 0.5, Approx: a.Approx}
+		k++
+	} else if k < len(rs) && (rs[k] == '多' || rs[k] == '余') && !a.Approx {
+		a = more(a.Value, 1)
+		k++
+	}
+	if _, ok := matchAny(rs, k, notAfterUnit); ok {
+		return Quantity{}, 0, false
+	}
+	if n, ok := matchAny(rs, skipSpace(rs, k), approxSuffixes); ok {
+		a = approx(a)
+		k = n
+	}
+	return Quantity{Amount: a, Unit: u.Name}, k, true
+}
+
+// amountAt：识别位置 i 起的数量（含约数前缀、范围、「十多」「20 余」与「十来」「百来」）。
+func amountAt(rs []rune, i int) (Amount, int, bool) {
+	marked := false
+	if n, ok := matchAny(rs, i, approxPrefixes); ok {
+		marked = true
+		i = skipSpace(rs, n)
+	}
+	a, j, ok := numberAt(rs, i)
+	if !ok {
+		return Amount{}, 0, false
+	}
+	// 范围：3~5、3 - 5、三到五
+	if n, ok := matchAny(rs, skipSpace(rs, j), rangeSeps); ok {
+		if b, e, ok := numberAt(rs, skipSpace(rs, n)); ok && b.Max >= a.Min {
+			a = Amount{Value: (a.Min + b.Max) / 2, Min: a.Min, Max: b.Max, Approx: true}
+			j = e
+		}
+	}
+	if j < len(rs) && (rs[j] == '多' || rs[j] == '余') && !a.Approx && a.Value >= 10 {
+		a = more(a.Value, place(a.Value))
+		j++
+	} else if j < len(rs) && rs[j] == '来' && !a.Approx && a.Value >= 10 {
+		// 「十来个」「百来克」：约数，代表值取该数本身（「一来」「二来」是连词，不在此列）
+		a = approx(a)
+		j++
+	}
+	if marked {
+		a = approx(a)
+	}
+	return a, j, true
+}
+
+// numberAt：识别位置 i 起的单个数字：阿拉伯数字（含小数、分数与「2 1/2」式带分数）、Unicode 分数、中文数字、「X 分之 Y」与「半」；
+// 单独的「百」「千」只在后接「来」时视为数字（「百来克」）。
+func numberAt(rs []rune, i int) (Amount, int, bool) {
+	if i >= len(rs) {
+		return Amount{}, 0, false
+	}
+	if v, ok := vulgar[rs[i]]; ok {
+		return exact(v), i + 1, true
+	}
+	if isDigit(rs[i]) {
+		j := i
+		for j < len(rs) && isDigit(rs[j]) {
+			j++
+		}
+		if j+1 < len(rs) && rs[j] == '.' && isDigit(rs[j+1]) {
+			j++
+			for j < len(rs) && isDigit(rs[j]) {
+				j++
+			}
+		}
+		v, _ := strconv.ParseFloat(string(rs[i:j]), 64)
+		if j+1 < len(rs) && rs[j] == '/' && isDigit(rs[j+1]) {
+			k := j + 1
+			for k < len(rs) && isDigit(rs[k]) {
+				k++
+			}
+			d, _ := strconv.ParseFloat(string(rs[j+1:k]), 64)
+			if d == 0 {
+				return Amount{}, 0, false
+			}
+			return exact(v / d), k, true
+		}
+		if j < len(rs) {
+			if f, ok := vulgar[rs[j]]; ok {
+				return exact(v + f), j + 1, true
+			}
+		}
+		if f, k, ok := properFraction(rs, j); ok && !strings.ContainsRune(string(rs[i:j]), '.') {
+			return exact(v + f), k, true
+		}
+		return exact(v), j, true
+	}
+	if rs[i] == '半' {
+		return exact(0.5), i + 1, true
+	}
+	j := i
+	for j < len(rs) && isCNNumeral(rs[j]) {
+		j++
+	}
+	if j == i {
+		return Amount{}, 0, false
+	}
+	run := string(rs[i:j])
+	// 三分之一
+	if j+1 < len(rs) && rs[j] == '分' && rs[j+1] == '之' {
+		k := j + 2
+		for k < len(rs) && isCNNumeral(rs[k]) {
+			k++
+		}
+		d, dok := evalCN(run)
+		n, nok := evalCN(string(rs[j+2 : k]))
+		if dok && nok && d.Min > 0 && d.Min == d.Max && n.Min == n.Max {
+			return exact(n.Value / d.Value), k, true
+		}
+		return Amount{}, 0, false
+	}
+	if run == "一" && j < len(rs) && rs[j] == '半' {
+		return exact(0.5), j + 1, true
+	}
+	if m := cnMults[rs[i]]; m > 10 && j == i+1 && j < len(rs) && rs[j] == '来' {
+		return exact(m), j, true
+	}
+	a, ok := evalCN(run)
+	if !ok {
+		return Amount{}, 0, false
+	}
+	return a, j, true
+}
+
+// properFraction：整数之后以空白隔开的真分数（「2 1/2」中的「 1/2」）；返回分数值与结束位置。
+func properFraction(rs []rune, i int) (float64, int, bool) {
+	j := i
+	for j < len(rs) && rs[j] == ' ' {
+		j++
+	}
+	if j == i || j >= len(rs) || !isDigit(rs[j]) {
+		return 0, 0, false
+	}
+	k := j
+	for k < len(rs) && isDigit(rs[k]) {
+		k++
+	}
+	if k+1 >= len(rs) || rs[k] != '/' || !isDigit(rs[k+1]) {
+		return 0, 0, false
+	}
+	e := k + 1
+	for e < len(rs) && isDigit(rs[e]) {
+		e++
+	}
+	n, _ := strconv.ParseFloat(string(rs[j:k]), 64)
+	d, _ := strconv.ParseFloat(string(rs[k+1:e]), 64)
+	if n <= 0 || d <= n {
+		return 0, 0, false
+	}
+	return n / d, e, true
+}
+
+// evalCN：计算中文数字串。
+// 算法说明：
+//   - 位值按「十/百/千/万」累加，「十五」=15，「二十五」=25，口语「一百二」=120；
+//   - 相邻递增数字表示范围：「两三」=2~3，「三四十」=30~40；口语「三五」同样表示范围（3~5）；
+//   - 「几」表示任意个位：「十几」=11~19，「几十」=10~90，单独的「几」=2~9。
+func evalCN(run string) (Amount, bool) {
+	rs := []rune(run)
+	if cnMults[rs[0]] > 10 {
+		return Amount{}, false // 「千张」「百叶」「万能」：以百、千、万开头的不是数字
+	}
+	if run == "几" {
+		return Amount{Value: 5.5, Min: 2, Max: 9, Approx: true}, true
+	}
+	lo := make([]rune, 0, len(rs))
+	hi := make([]rune, 0, len(rs))
+	for k := 0; k < len(rs); k++ {
+		r := rs[k]
+		if r == '几' {
+			lo, hi = append(lo, '一'), append(hi, '九')
+			continue
+		}
+		if k+1 < len(rs) {
+			d1, ok1 := cnDigits[r]
+			d2, ok2 := cnDigits[rs[k+1]]
+			if ok1 && ok2 && (d2 == d1+1 || d1 == 3 && d2 == 5) {
+				lo, hi = append(lo, r), append(hi, rs[k+1])
+				k++
+				continue
+			}
+		}
+		lo, hi = append(lo, r), append(hi, r)
+	}
+	a, ok := evalPlain(lo)
+	if !ok {
+		return Amount{}, false
+	}
+	b, ok := evalPlain(hi)
+	if !ok {
+		return Amount{}, false
+	}
+	if a == b {
+		return exact(a), true
+	}
+	return Amount{Value: (a + b) / 2, Min: a, Max: b, Approx: true}, true
+}
+
+// evalPlain：计算不含范围与「几」的中文数字。
+func evalPlain(rs []rune) (float64, bool) {
+	var total, section, cur, lastMult float64
+	prevDigit := false
+	for _, r := range rs {
+		if d, ok := cnDigits[r]; ok {
+			if prevDigit && cur != 0 {
+				return 0, false // 两个数字相连且不构成范围（如「一三」）
+			}
+			cur, prevDigit = d, true
+			if d == 0 {
+				lastMult = 0
+			}
+			continue
+		}
+		prevDigit = false
+		m, ok := cnMults[r]
+		if !ok {
+			return 0, false
+		}
+		if m == 10000 {
+			total = (total + section + cur) * m
+			section, cur, lastMult = 0, 0, 0
+			continue
+		}
+		if cur == 0 {
+			cur = 1
+		}
+		section += cur * m
+		cur, lastMult = 0, m
+	}
+	if cur != 0 && lastMult >= 100 {
+		cur *= lastMult / 10 // 口语省略末位：一百二 = 120，三千五 = 3500
+	}
+	return total + section + cur, true
+}
+
+// unitAt：最长匹配位置 i 起的单位写法；ASCII 单位须在词边界结束（避免「1 large」匹配 l）。
+func unitAt(rs []rune, i int) (string, int, bool) {
+	for _, name := range unitNames {
+		n, ok := matchAt(rs, i, name)
+		if !ok {
+			continue
+		}
+		if isASCIIAlnum(rs[n-1]) && n < len(rs) && isASCIIAlnum(rs[n]) {
+			continue
+		}
+		return name, n, true
+	}
+	return "", 0, false
+}
+
+// stripNotes：去掉括号内的补充说明与「#」之后的注释。
+func stripNotes(s string) string {
+	if k := strings.IndexAny(s, "#＃"); k >= 0 {
+		s = s[:k]
+	}
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch r {
+		case '(', '（', '[', '【':
+			depth++
+			continue
+		case ')', '）', ']', '】':
+			if depth > 0 {
+				depth--
+			}
+			continue
+		}
+		if depth == 0 {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// trimName：去掉食材名两端的空白、标点与连接词。
+func trimName(s string) string {
+	s = strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+	for _, w := range []string{"of ", "和", "及"} {
+		s = strings.TrimPrefix(s, w)
+	}
+	return strings.TrimSpace(s)
+}
+
+// exact：精确数量。
+func exact(v float64) Amount { return Amount{Value: v, Min: v, Max: v} }
+
+// approx：标记为约数；区间不变。
+func approx(a Amount) Amount {
+	a.Approx = true
+	return a
+}
+
+// more：「N 多」「N 余」；取值 (N, N+step)。
+func more(v, step float64) Amount {
+	return Amount{Value: v + step/2, Min: v, Max: v + step, Approx: true}
+}
+
+// place：数值的最高位权（20→10，350→100）。
+func place(v float64) float64 {
+	return math.Pow(10, math.Floor(math.Log10(v)))
+}
+
+func matchAny(rs []rune, i int, words []string) (int, bool) {
+	for _, w := range words {
+		if n, ok := matchAt(rs, i, w); ok {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func matchAt(rs []rune, i int, w string) (int, bool) {
+	j := i
+	for _, r := range w {
+		if j >= len(rs) || rs[j] != r {
+			return 0, false
+		}
+		j++
+	}
+	return j, true
+}
+
+func indexRunes(rs, sub []rune, from int) int {
+	for i := from; i+len(sub) <= len(rs); i++ {
+		if string(rs[i:i+len(sub)]) == string(sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func skipSpace(rs []rune, i int) int {
+	for i < len(rs) && unicode.IsSpace(rs[i]) {
+		i++
+	}
+	return i
+}
+
+func isDigit(r rune) bool { return r >= '0' && r <= '9' }
+
+func isASCIIAlnum(r rune) bool {
+	return isDigit(r) || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
+func isCNNumeral(r rune) bool {
+	_, d := cnDigits[r]
+	_, m := cnMults[r]
+	return d || m || r == '几'
+}
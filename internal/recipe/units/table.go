@@ -0,0 +1,145 @@
+// 文件功能：食材换算表；密度（g/ml）用于质量与体积互换，单件重量（g）用于计件单位换算为质量。
+package units
+
+import (
+	"strings"
+
+	"cook/internal/recipe/textnorm"
+)
+
+// densities：食材密度（g/ml）；取常温下的常见值，散装粉粒类为堆积密度。
+var densities = map[string]float64{
+	"水":    1.0,
+	"清水":   1.0,
+	"开水":   1.0,
+	"高汤":   1.0,
+	"油":    0.92,
+	"食用油":  0.92,
+	"香油":   0.92,
+	"芝麻油":  0.92,
+	"橄榄油":  0.91,
+	"黄油":   0.91,
+	"酱油":   1.15,
+	"生抽":   1.15,
+	"老抽":   1.2,
+	"蚝油":   1.25,
+	"醋":    1.01,
+	"料酒":   0.98,
+	"黄酒":   0.98,
+	"白酒":   0.93,
+	"啤酒":   1.01,
+	"牛奶":   1.03,
+	"淡奶油":  1.0,
+	"蜂蜜":   1.42,
+	"番茄酱":  1.1,
+	"豆瓣酱":  1.2,
+	"盐":    1.2,
+	"糖":    0.85,
+	"白糖":   0.85,
+	"红糖":   0.8,
+	"冰糖":   0.9,
+	"鸡精":   0.6,
+	"味精":   0.7,
+	"面粉":   0.53,
+	"淀粉":   0.55,
+	"玉米淀粉": 0.55,
+	"米":    0.85,
+	"大米":   0.85,
+	"小苏打":  0.9,
+	"胡椒粉":  0.45,
+	"辣椒粉":  0.45,
+	"孜然粉":  0.4,
+	"花椒":   0.3,
+}
+
+// pieceWeights：食材 → 计件单位 → 单件重量（g）；空单位键为该食材任意计件单位的兜底值。
+var pieceWeights = map[string]map[string]float64{
+	"蒜":   {"瓣": 5, "头": 50, "": 5},
+	"大蒜":  {"瓣": 5, "头": 50, "": 5},
+	"姜":   {"片": 3, "块": 15, "": 15},
+	"生姜":  {"片": 3, "块": 15, "": 15},
+	"葱":   {"根": 15, "段": 5, "": 15},
+	"小葱":  {"根": 10, "段": 3, "": 10},
+	"香葱":  {"根": 10, "段": 3, "": 10},
+	"大葱":  {"根": 100, "段": 15, "": 100},
+	"鸡蛋":  {"": 50},
+	"鸭蛋":  {"": 70},
+	"鹌鹑蛋": {"": 10},
+	"番茄":  {"": 150},
+	"西红柿": {"": 150},
+	"土豆":  {"": 200},
+	"洋葱":  {"": 200},
+	"青椒":  {"": 100},
+	"红椒":  {"": 100},
+	"辣椒":  {"": 10},
+	"干辣椒": {"": 0.5},
+	"小米椒": {"": 3},
+	"胡萝卜": {"": 150},
+	"黄瓜":  {"": 200},
+	"茄子":  {"": 250},
+	"西葫芦": {"": 300},
+	"柠檬":  {"片": 5, "": 100},
+	"苹果":  {"": 200},
+	"香蕉":  {"": 120},
+	"八角":  {"": 1},
+	"香叶":  {"": 0.5},
+	"桂皮":  {"": 3},
+	"冰糖":  {"块": 5, "粒": 3, "": 5},
+	"鸡翅":  {"": 50},
+	"鸡腿":  {"": 150},
+	"虾":   {"": 15},
+	"馒头":  {"": 100},
+	"豆腐":  {"块": 300, "": 300},
+	"生菜":  {"片": 15, "棵": 300, "": 300},
+	"香菜":  {"根": 5, "把": 30, "": 5},
+	"菠菜":  {"棵": 30, "把": 250, "": 30},
+}
+
+// lookupKey：在表中查找与食材名最匹配的键。
+// 算法说明：食材名折叠后先精确匹配，否则取其包含的最长键（「紫皮大蒜」→「大蒜」，「小葱段」→「小葱」）。
+func lookupKey[V any](table map[string]V, ingredient string) (string, bool) {
+	name := textnorm.Fold(strings.TrimSpace(ingredient))
+	if name == "" {
+		return "", false
+	}
+	if _, ok := table[name]; ok {
+		return name, true
+	}
+	best := ""
+	for k := range table {
+		if len([]rune(k)) > len([]rune(best)) && strings.Contains(name, k) {
+			best = k
+		}
+	}
+	return best, best != ""
+}
+
+// Density：食材密度（g/ml）；未收录时返回 false。
+func Density(ingredient string) (float64, bool) {
+	k, ok := lookupKey(densities, ingredient)
+	if !ok {
+		return 0, false
+	}
+	return densities[k], true
+}
+
+// PieceWeight：食材单件重量（g）。
+// 参数说明：
+//   - ingredient：食材名；
+//   - unit：计件单位（瓣、根、个……）；大小修饰（小块/大块）由调用方按 Unit.Factor 处理。
+//
+// 返回值说明：
+//   - float64：单件重量；先取该单位的专属值，再取食材兜底值；
+//   - bool：未收录时返回 false。
+func PieceWeight(ingredient, unit string) (float64, bool) {
+	k, ok := lookupKey(pieceWeights, ingredient)
+	if !ok {
+		return 0, false
+	}
+	w := pieceWeights[k]
+	if g, ok := w[unit]; ok {
+		return g, true
+	}
+	g, ok := w[""]
+	return g, ok
+}
@@ -0,0 +1,140 @@
+// 文件功能：单位定义与别名表；质量以 g、体积以 ml 为基准，计件单位按食材的单件重量换算。
+package units
+
+import (
+	"strings"
+
+	"cook/internal/recipe/textnorm"
+)
+
+// Dimension：量纲。
+type Dimension int
+
+// 量纲取值。
+const (
+	Mass   Dimension = iota + 1 // 质量，基准单位 g
+	Volume                      // 体积，基准单位 ml
+	Count                       // 计件（个、根、瓣……），需按单件重量换算
+)
+
+// String：量纲名称。
+func (d Dimension) String() string {
+	switch d {
+	case Mass:
+		return "mass"
+	case Volume:
+		return "volume"
+	case Count:
+		return "count"
+	}
+	return "unknown"
+}
+
+// 基准单位。
+const (
+	Gram       = "g"
+	Milliliter = "ml"
+)
+
+// 单位体系；用于选择展示单位。
+const (
+	Metric   = "metric"   // 公制：g、kg、ml、l
+	Imperial = "imperial" // 英制/美制：oz、lb、tsp、tbsp、cup、fl oz
+	Chinese  = "chinese"  // 市制：斤、两
+	Kitchen  = "kitchen"  // 厨房约定量具：勺、杯、碗
+	Piece    = "piece"    // 计件
+)
+
+// Unit：单位定义。
+//   - Name：规范名称（如 g、tbsp、瓣）；
+//   - Dim：量纲；
+//   - Factor：换算到基准单位的系数（计件单位为相对「标准一件」的倍数，如「小块」为 0.5）；
+//   - Exact：系数是否为定义值（如 1 oz = 28.349523125 g）；「一勺」「一杯」等约定量为估算；
+//   - System：所属单位体系。
+type Unit struct {
+	Name   string
+	Dim    Dimension
+	Factor float64
+	Exact  bool
+	System string
+	piece  string // 计件单位对应的单件重量表键（「小块」→「块」）
+}
+
+// units：规范单位表。
+var units = []Unit{
+	{Name: "mg", Dim: Mass, Factor: 0.001, Exact: true, System: Metric},
+	{Name: "g", Dim: Mass, Factor: 1, Exact: true, System: Metric},
+	{Name: "kg", Dim: Mass, Factor: 1000, Exact: true, System: Metric},
+	{Name: "斤", Dim: Mass, Factor: 500, Exact: true, System: Chinese},
+	{Name: "两", Dim: Mass, Factor: 50, Exact: true, System: Chinese},
+	{Name: "oz", Dim: Mass, Factor: 28.349523125, Exact: true, System: Imperial},
+	{Name: "lb", Dim: Mass, Factor: 453.59237, Exact: true, System: Imperial},
+
+	{Name: "ml", Dim: Volume, Factor: 1, Exact: true, System: Metric},
+	{Name: "l", Dim: Volume, Factor: 1000, Exact: true, System: Metric},
+	{Name: "tsp", Dim: Volume, Factor: 4.92892159375, Exact: true, System: Imperial},
+	{Name: "tbsp", Dim: Volume, Factor: 14.78676478125, Exact: true, System: Imperial},
+	{Name: "fl oz", Dim: Volume, Factor: 29.5735295625, Exact: true, System: Imperial},
+	{Name: "cup", Dim: Volume, Factor: 236.5882365, Exact: true, System: Imperial},
+	{Name: "pint", Dim: Volume, Factor: 473.176473, Exact: true, System: Imperial},
+	{Name: "茶匙", Dim: Volume, Factor: 5, Exact: true, System: Kitchen},
+	{Name: "汤匙", Dim: Volume, Factor: 15, Exact: true, System: Kitchen},
+	{Name: "勺", Dim: Volume, Factor: 15, System: Kitchen}, // 未说明大小的勺按汤匙估算
+	{Name: "杯", Dim: Volume, Factor: 240, System: Kitchen},
+	{Name: "碗", Dim: Volume, Factor: 300, System: Kitchen},
+
+	{Name: "个", Dim: Count, Factor: 1, System: Piece},
+	{Name: "只", Dim: Count, Factor: 1, System: Piece},
+	{Name: "根", Dim: Count, Factor: 1, System: Piece},
+	{Name: "瓣", Dim: Count, Factor: 1, System: Piece},
+	{Name: "片", Dim: Count, Factor: 1, System: Piece},
+	{Name: "支", Dim: Count, Factor: 1, System: Piece},
+	{Name: "块", Dim: Count, Factor: 1, System: Piece},
+	{Name: "颗", Dim: Count, Factor: 1, System: Piece},
+	{Name: "粒", Dim: Count, Factor: 1, System: Piece},
+	{Name: "条", Dim: Count, Factor: 1, System: Piece},
+	{Name: "段", Dim: Count, Factor: 1, System: Piece},
+	{Name: "头", Dim: Count, Factor: 1, System: Piece},
+	{Name: "棵", Dim: Count, Factor: 1, System: Piece},
+	{Name: "把", Dim: Count, Factor: 1, System: Piece},
+	{Name: "朵", Dim: Count, Factor: 1, System: Piece},
+	{Name: "枚", Dim: Count, Factor: 1, System: Piece},
+	{Name: "张", Dim: Count, Factor: 1, System: Piece},
+	{Name: "小块", Dim: Count, Factor: 0.5, System: Piece, piece: "块"},
+	{Name: "大块", Dim: Count, Factor: 2, System: Piece, piece: "块"},
+}
+
+// aliases：单位写法 → 规范名称；查找前统一经 textnorm.Fold 折叠（全角、大小写）。
+var aliases = map[string]string{
+	"毫克": "mg", "克": "g", "公克": "g", "gram": "g", "grams": "g", "千克": "kg", "公斤": "kg",
+	"ounce": "oz", "ounces": "oz", "lbs": "lb", "pound": "lb", "pounds": "lb", "磅": "lb", "盎司": "oz",
+	"毫升": "ml", "cc": "ml", "升": "l", "公升": "l", "litre": "l", "liter": "l",
+	"teaspoon": "tsp", "teaspoons": "tsp", "tablespoon": "tbsp", "tablespoons": "tbsp", "tbs": "tbsp",
+	"floz": "fl oz", "fl.oz": "fl oz", "fluid ounce": "fl oz", "cups": "cup", "pints": "pint",
+	"小勺": "茶匙", "小匙": "茶匙", "茶勺": "茶匙", "大勺": "汤匙", "汤勺": "汤匙", "大匙": "汤匙", "匙": "勺", "调羹": "汤匙",
+	"只装": "只", "颗粒": "粒",
+}
+
+var byName = func() map[string]Unit {
+	m := make(map[string]Unit, len(units))
+	for _, u := range units {
+		if u.piece == "" {
+			u.piece = u.Name
+		}
+		m[u.Name] = u
+	}
+	return m
+}()
+
+// Lookup：按写法查找单位；大小写、全半角与繁简不敏感，首尾空白忽略。
+func Lookup(name string) (Unit, bool) {
+	key := strings.TrimSpace(textnorm.Fold(name))
+	if u, ok := byName[key]; ok {
+		return u, true
+	}
+	if canon, ok := aliases[key]; ok {
+		u, ok := byName[canon]
+		return u, ok
+	}
+	return Unit{}, false
+}
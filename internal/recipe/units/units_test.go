@@ -0,0 +1,201 @@
+// 文件功能：单位换算的单元测试；验证单位别名、归一、跨量纲估算、展示单位选择与用量文本解析。
+package units
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		ingredient, unit string
+		value            float64
+		want             string
+	}{
+		{"鲜面条", "克", 300, "300 g"},
+		{"盐", "Ｇ", 5, "5 g"},
+		{"清水", "毫升", 500, "500 ml"},
+		{"面粉", "斤", 1, "500 g"},
+		{"生抽", "勺", 2, "≈30 ml"},
+		{"白糖", "小勺", 1, "5 ml"},
+		{"大蒜", "瓣", 3, "≈15 g"},
+		{"生姜", "小块", 1, "≈7.5 g"},
+		{"鸡蛋", "个", 2, "≈100 g"},
+		{"大葱", "根", 1, "≈100 g"},
+		{"小葱", "根", 2, "≈20 g"},
+		{"神秘食材", "个", 2, "2 个"},
+		{"神秘食材", "小块", 1, "≈0.5 块"},
+	}
+	for _, c := range cases {
+		m, err := Normalize(c.ingredient, c.value, c.unit)
+		if err != nil {
+			t.Fatalf("Normalize(%s %v %s): %v", c.ingredient, c.value, c.unit, err)
+		}
+		if got := m.String(); got != c.want {
+			t.Errorf("Normalize(%s %v %s) = %s, want %s", c.ingredient, c.value, c.unit, got, c.want)
+		}
+		if !m.Exact && len(m.Basis) == 0 {
+			t.Errorf("Normalize(%s %v %s): estimate without basis", c.ingredient, c.value, c.unit)
+		}
+	}
+	if _, err := Normalize("盐", 1, "撮"); !errors.Is(err, ErrUnknownUnit) {
+		t.Fatalf("unknown unit: err = %v", err)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		ingredient string
+		in         Measure
+		target     string
+		want       string
+	}{
+		{"面粉", Measure{Value: 1, Unit: "lb", Exact: true}, "g", "453.59 g"},
+		{"牛奶", Measure{Value: 1, Unit: "cup", Exact: true}, "ml", "236.59 ml"},
+		{"牛奶", Measure{Value: 1, Unit: "cup", Exact: true}, "g", "≈243.69 g"},
+		{"蜂蜜", Measure{Value: 71, Unit: "g"}, "tbsp", "≈3.38 tbsp"},
+		{"蒜", Measure{Value: 20, Unit: "g"}, "瓣", "≈4 瓣"},
+		{"姜", Measure{Value: 2, Unit: "小块"}, "块", "≈1 块"},
+		{"鸡蛋", Measure{Value: 3, Unit: "个"}, "oz", "≈5.29 oz"},
+	}
+	for _, c := range cases {
+		m, err := Convert(c.ingredient, c.in, c.target)
+		if err != nil {
+			t.Fatalf("Convert(%s %v → %s): %v", c.ingredient, c.in, c.target, err)
+		}
+		if got := m.String(); got != c.want {
+			t.Errorf("Convert(%s %v → %s) = %s, want %s", c.ingredient, c.in, c.target, got, c.want)
+		}
+	}
+	if _, err := Convert("神秘食材", Measure{Value: 100, Unit: "g"}, "ml"); !errors.Is(err, ErrNoConversion) {
+		t.Fatalf("missing density: err = %v", err)
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	cases := []struct {
+		ingredient, unit, system string
+		value                    float64
+		want                     string
+	}{
+		{"五花肉", "g", Imperial, 500, "1.1 lb"},
+		{"五花肉", "g", Imperial, 200, "7.05 oz"},
+		{"五花肉", "g", Chinese, 750, "1.5 斤"},
+		{"五花肉", "g", Chinese, 150, "3 两"},
+		{"清水", "ml", Metric, 1500, "1.5 l"},
+		{"生抽", "ml", Imperial, 10, "2.03 tsp"},
+		{"生抽", "ml", Imperial, 30, "2.03 tbsp"},
+		{"牛奶", "ml", Imperial, 250, "1.06 cup"},
+		{"蒜", "瓣", Imperial, 4, "≈0.71 oz"},
+		{"神秘食材", "个", Imperial, 2, "2 个"},
+	}
+	for _, c := range cases {
+		m, err := Display(c.ingredient, Measure{Value: c.value, Unit: c.unit, Exact: true}, c.system)
+		if err != nil {
+			t.Fatalf("Display(%v %s %s): %v", c.value, c.unit, c.system, err)
+		}
+		if got := m.String(); got != c.want {
+			t.Errorf("Display(%v %s %s) = %s, want %s", c.value, c.unit, c.system, got, c.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	cases := map[string]Amount{
+		"两":     {2, 2, 2, false},
+		"半":     {0.5, 0.5, 0.5, false},
+		"十五":    {15, 15, 15, false},
+		"一百二":   {120, 120, 120, false},
+		"一百零二":  {102, 102, 102, false},
+		"十几":    {15, 11, 19, true},
+		"两三":    {2.5, 2, 3, true},
+		"三四十":   {35, 30, 40, true},
+		"1/2":   {0.5, 0.5, 0.5, false},
+		"1½":    {1.5, 1.5, 1.5, false},
+		"三分之一":  {1.0 / 3, 1.0 / 3, 1.0 / 3, false},
+		"3~5":   {4, 3, 5, true},
+		"3 - 5": {4, 3, 5, true},
+		"三到五":   {4, 3, 5, true},
+		"约 350": {350, 350, 350, true},
+		"２０左右":  {20, 20, 20, true},
+		"二十多":   {25, 20, 30, true},
+		"2 1/2": {2.5, 2.5, 2.5, false},
+		"三五":    {4, 3, 5, true},
+		"十来":    {10, 10, 10, true},
+		"百来":    {100, 100, 100, true},
+		"20来":   {20, 20, 20, true},
+	}
+	for in, want := range cases {
+		got, ok := ParseAmount(in)
+		if !ok || got != want {
+			t.Errorf("ParseAmount(%q) = %+v %t, want %+v", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "适量", "一三", "3 个", "2 3/2", "一来", "百"} {
+		if _, ok := ParseAmount(in); ok {
+			t.Errorf("ParseAmount(%q) should fail", in)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	text := "加入两勺生抽、半个洋葱、一根葱、1/2 个柠檬和 3~5 片姜，约 350g 清水，一两猪肉，三斤多排骨；" +
+		"蒸两个小时，第一个鸡蛋放入另一个碗，煮 3 分钟，2 个人吃，一块钱，千张 1 张"
+	var got []string
+	for _, q := range Extract(text) {
+		got = append(got, fmt.Sprintf("%s=%v~%v%s/%t", q.Text, q.Amount.Min, q.Amount.Max, q.Unit, q.Amount.Approx))
+	}
+	want := "两勺=2~2勺/false 半个=0.5~0.5个/false 一根=1~1根/false 1/2 个=0.5~0.5个/false " +
+		"3~5 片=3~5片/true 约 350g=350~350g/true 一两=1~1两/false 三斤多=3~4斤/true 1 张=1~1张/false"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("Extract = %s", strings.Join(got, " "))
+	}
+}
+
+func TestExtractCases(t *testing.T) {
+	cases := map[string]string{
+		"生粉 2 1/2 茶匙": "2 1/2 茶匙=2.5~2.5茶匙/false",
+		"姜 三五片":       "三五片=3~5片/true",
+		"十来个鸡翅":       "十来个=10~10个/true",
+		"面粉百来克":       "百来克=100~100g/true",
+		"二两肉":         "二两=2~2两/false",
+		"约二两肉":        "约二两=2~2两/true",
+		"一来省事，二来好吃，两个蛋": "两个=2~2个/false",
+	}
+	for in, want := range cases {
+		var got []string
+		for _, q := range Extract(in) {
+			got = append(got, fmt.Sprintf("%s=%v~%v%s/%t", q.Text, q.Amount.Min, q.Amount.Max, q.Unit, q.Amount.Approx))
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("Extract(%q) = %s, want %s", in, strings.Join(got, " "), want)
+		}
+	}
+}
+
+func TestExtractItem(t *testing.T) {
+	cases := map[string]string{
+		"土豆 2 个（每个土豆大约重 120g，共约 240g）": "土豆:2个",
+		"猪油 15 g（添加蚝油时，减少到 10 g）":      "猪油:15g",
+		"肥肉末 20g #不喜可不加":               "肥肉末:20g",
+		"两个鸡蛋":                         "鸡蛋:2个",
+		"2 cups flour":                 "flour:2cup",
+		"盐 3g 糖 5g":                    "盐:3g 糖:5g",
+		"葱花 适量":                        "",
+	}
+	for in, want := range cases {
+		var got []string
+		for _, q := range ExtractItem(in) {
+			got = append(got, fmt.Sprintf("%s:%v%s", q.Ingredient, q.Amount.Value, q.Unit))
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("ExtractItem(%q) = %q, want %q", in, got, want)
+		}
+	}
+	m, err := ExtractItem("大蒜 三瓣")[0].Measure()
+	if err != nil || m.String() != "≈15 g" {
+		t.Fatalf("measure: %v %v", m, err)
+	}
+}
@@ -0,0 +1,4 @@
+// 文件功能：向量检索封装包说明。
+// 包功能：vector 包，负责解析产物与检索文档之间的转换，以及索引、检索相关的通用结构；
+// 与 Eino schema.Document 的相互转换在 eino 构建标签下提供（go build -tags eino）。
+package vector
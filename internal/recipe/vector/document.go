@@ -0,0 +1,310 @@
+// 文件功能：types.Chunk 与检索文档之间的双向转换；统一元数据键名，供索引器、检索器与问答链路消费。
+package vector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cook/internal/recipe/parser"
+	"cook/internal/recipe/parser/types"
+)
+
+// 元数据键名；索引映射、过滤条件与回转换均使用同一组常量。
+const (
+	MetaDocID     = "doc_id"
+	MetaIndex     = "index"
+	MetaCategory  = "category"
+	MetaName      = "name"
+	MetaPath      = "path"
+	MetaHeader    = "header"
+	MetaSection   = "section"
+	MetaSource    = "source"
+	MetaServings  = "servings"
+	MetaNutrition = "nutrition"
+	MetaAllergens = "allergens"
+
+	MetaDifficulty  = "difficulty"    // 难度（1～5）
+	MetaMinutes     = "total_minutes" // 总时长（分钟）
+	MetaDiets       = "diets"         // 饮食标签
+	MetaIngredients = "ingredients"   // 原料名
+	MetaHeat        = "heat"          // 步骤中的命名火候（大火、旺油、中温等）
+	// 由营养成分换算的每份数值，供范围过滤；只写不读（ToChunk 以 nutrition 为准）。
+	MetaKcal    = "kcal"
+	MetaProtein = "protein_g"
+	MetaFat     = "fat_g"
+	MetaCarbs   = "carbs_g"
+
+	// metaScore：检索得分；与 Eino schema.Document 的 _score 约定一致。
+	metaScore = "_score"
+	// metaDenseVector：稠密向量；与 Eino schema.Document 的 _dense_vector 约定一致。
+	metaDenseVector = "_dense_vector"
+)
+
+// Document：检索文档；本包的镜像结构，不是 Eino 的 schema.Document。字段名（ID/Content/MetaData）、JSON 结构
+// （id/content/meta_data）与得分、向量的元数据键名与 schema.Document 相同，交给 Eino 组件前需经 ToSchema 转换
+// （eino 构建标签，见 eino.go）；JSON 表示可直接按 schema.Document 反序列化。
+//   - ID：文档唯一标识（即 Chunk.ID）；
+//   - Content：检索与向量化使用的正文（标题 + 正文）；
+//   - MetaData：元数据映射，键见 Meta* 常量。
+type Document struct {
+	ID       string         `json:"id"`        // 文档标识
+	Content  string         `json:"content"`   // 正文
+	MetaData map[string]any `json:"meta_data"` // 元数据
+}
+
+// String：返回 Content，与 schema.Document.String 相同。
+func (d *Document) String() string { return d.Content }
+
+// Score：返回检索得分；未设置时为 0。
+func (d *Document) Score() float64 {
+	if d.MetaData == nil {
+		return 0
+	}
+	f, _ := toFloat(d.MetaData[metaScore])
+	return f
+}
+
+// WithScore：设置检索得分并返回自身，便于链式调用。
+func (d *Document) WithScore(score float64) *Document {
+	if d.MetaData == nil {
+		d.MetaData = make(map[string]any)
+	}
+	d.MetaData[metaScore] = score
+	return d
+}
+
+// DenseVector：返回稠密向量；未设置或类型无法识别时为 nil；兼容 JSON 往返后的 []any。
+func (d *Document) DenseVector() []float64 {
+	if d.MetaData == nil {
+		return nil
+	}
+	switch v := d.MetaData[metaDenseVector].(type) {
+	case []float64:
+		return v
+	case []any:
+		out := make([]float64, 0, len(v))
+		for _, x := range v {
+			f, ok := toFloat(x)
+			if !ok {
+				return nil
+			}
+			out = append(out, f)
+		}
+		return out
+	}
+	return nil
+}
+
+// WithDenseVector：设置稠密向量并返回自身，便于链式调用。
+func (d *Document) WithDenseVector(v []float64) *Document {
+	if d.MetaData == nil {
+		d.MetaData = make(map[string]any)
+	}
+	d.MetaData[metaDenseVector] = v
+	return d
+}
+
+// FromChunk：将 Chunk 转换为 Document。
+// 功能说明：Content 由标题与正文拼接，使向量化时保留章节语义；分类、名称、路径、标题、章节、来源、份量、
+// 营养成分、过敏原、难度、总时长、饮食标签与原料名全部写入 MetaData，营养成分另换算为 kcal 与克数供范围过滤；
+// 空的可选字段省略。
+// 参数说明：
+//   - c：解析得到的分块。
+//
+// 返回值说明：
+//   - *Document：转换结果。
+func FromChunk(c types.Chunk) *Document {
+	meta := map[string]any{
+		MetaDocID:    c.DocID,
+		MetaIndex:    c.Index,
+		MetaCategory: c.Category,
+		MetaName:     c.Name,
+		MetaPath:     c.Path,
+		MetaHeader:   c.Header,
+		MetaSection:  c.Section,
+		MetaSource:   c.Source,
+	}
+	if c.Servings > 0 {
+		meta[MetaServings] = c.Servings
+	}
+	if len(c.Nutrition) > 0 {
+		n := make(map[string]string, len(c.Nutrition))
+		for k, v := range c.Nutrition {
+			n[k] = v
+		}
+		meta[MetaNutrition] = n
+	}
+	if len(c.Allergens) > 0 {
+		meta[MetaAllergens] = append([]string(nil), c.Allergens...)
+	}
+	if c.Difficulty > 0 {
+		meta[MetaDifficulty] = c.Difficulty
+	}
+	if c.Minutes > 0 {
+		meta[MetaMinutes] = c.Minutes
+	}
+	if len(c.Diets) > 0 {
+		meta[MetaDiets] = append([]string(nil), c.Diets...)
+	}
+	if len(c.Ingredients) > 0 {
+		meta[MetaIngredients] = append([]string(nil), c.Ingredients...)
+	}
+	if len(c.Heat) > 0 {
+		meta[MetaHeat] = append([]string(nil), c.Heat...)
+	}
+	for key, nutrient := range nutrientKeys {
+		if v, ok := parser.NutritionValue(c.Nutrition, nutrient); ok {
+			meta[key] = v
+		}
+	}
+	return &Document{
+		ID:       c.ID,
+		Content:  joinContent(c.Header, c.Text),
+		MetaData: meta,
+	}
+}
+
+// nutrientKeys：范围过滤字段与 schema.org 营养字段的对应关系。
+var nutrientKeys = map[string]string{
+	MetaKcal:    "calories",
+	MetaProtein: "proteinContent",
+	MetaFat:     "fatContent",
+	MetaCarbs:   "carbohydrateContent",
+}
+
+// FromChunks：批量转换 Chunk。
+func FromChunks(chunks []types.Chunk) []*Document {
+	out := make([]*Document, 0, len(chunks))
+	for _, c := range chunks {
+		out = append(out, FromChunk(c))
+	}
+	return out
+}
+
+// ToChunk：将检索返回的 Document 还原为 Chunk。
+// 功能说明：兼容经 JSON 往返后的元数据类型（数字为 float64、数组为 []any、对象为 map[string]any）；
+// Content 以标题开头时剥离标题还原 Text。
+// 参数说明：
+//   - d：检索得到的文档；为 nil 时报错。
+//
+// 返回值说明：
+//   - types.Chunk：还原的分块；
+//   - error：文档为空或元数据类型无法识别时返回错误。
+func ToChunk(d *Document) (types.Chunk, error) {
+	if d == nil {
+		return types.Chunk{}, fmt.Errorf("to chunk: nil document")
+	}
+	m := d.MetaData
+	c := types.Chunk{
+		ID:       d.ID,
+		DocID:    metaString(m, MetaDocID),
+		Category: metaString(m, MetaCategory),
+		Name:     metaString(m, MetaName),
+		Path:     metaString(m, MetaPath),
+		Header:   metaString(m, MetaHeader),
+		Section:  metaString(m, MetaSection),
+		Source:   metaString(m, MetaSource),
+	}
+	if v, ok := m[MetaIndex]; ok {
+		f, ok := toFloat(v)
+		if !ok {
+			return types.Chunk{}, fmt.Errorf("to chunk %s: bad %s %v", d.ID, MetaIndex, v)
+		}
+		c.Index = int(f)
+	}
+	if v, ok := m[MetaServings]; ok {
+		f, ok := toFloat(v)
+		if !ok {
+			return types.Chunk{}, fmt.Errorf("to chunk %s: bad %s %v", d.ID, MetaServings, v)
+		}
+		c.Servings = int(f)
+	}
+	for key, dst := range map[string]*int{MetaDifficulty: &c.Difficulty, MetaMinutes: &c.Minutes} {
+		if v, ok := m[key]; ok {
+			f, ok := toFloat(v)
+			if !ok {
+				return types.Chunk{}, fmt.Errorf("to chunk %s: bad %s %v", d.ID, key, v)
+			}
+			*dst = int(f)
+		}
+	}
+	switch n := m[MetaNutrition].(type) {
+	case nil:
+	case map[string]string:
+		c.Nutrition = n
+	case map[string]any:
+		c.Nutrition = make(map[string]string, len(n))
+		for k, v := range n {
+			c.Nutrition[k] = fmt.Sprint(v)
+		}
+	default:
+		return types.Chunk{}, fmt.Errorf("to chunk %s: bad %s %T", d.ID, MetaNutrition, n)
+	}
+	for key, dst := range map[string]*[]string{MetaAllergens: &c.Allergens, MetaDiets: &c.Diets, MetaIngredients: &c.Ingredients, MetaHeat: &c.Heat} {
+		switch a := m[key].(type) {
+		case nil:
+		case []string:
+			*dst = a
+		case []any:
+			for _, v := range a {
+				*dst = append(*dst, fmt.Sprint(v))
+			}
+		default:
+			return types.Chunk{}, fmt.Errorf("to chunk %s: bad %s %T", d.ID, key, a)
+		}
+	}
+	c.Text = d.Content
+	if c.Header != "" && strings.HasPrefix(c.Text, c.Header) {
+		c.Text = strings.TrimSpace(strings.TrimPrefix(c.Text, c.Header))
+	}
+	return c, nil
+}
+
+// ToChunks：批量还原 Document；遇到首个错误即返回。
+func ToChunks(docs []*Document) ([]types.Chunk, error) {
+	out := make([]types.Chunk, 0, len(docs))
+	for _, d := range docs {
+		c, err := ToChunk(d)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// joinContent：拼接标题与正文；任一为空时不产生多余空行。
+func joinContent(header, text string) string {
+	switch {
+	case header == "":
+		return text
+	case text == "":
+		return header
+	}
+	return header + "\n\n" + text
+}
+
+// metaString：读取字符串元数据；缺失或类型不符时返回空串。
+func metaString(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// toFloat：将 JSON 往返后可能出现的数值类型统一为 float64。
+func toFloat(v any) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case float64:
+		return t, true
+	case float32:
+		return float64(t), true
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,92 @@
+// 文件功能：Chunk 与 Document 转换的单元测试；验证元数据完整性与 JSON 往返后的还原。
+package vector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"cook/internal/recipe/parser/types"
+)
+
+func TestChunkDocumentRoundTrip(t *testing.T) {
+	c := types.Chunk{
+		ID: "chunk-3", DocID: "doc-1", Index: 2,
+		Header: "## 计算", Text: "鲈鱼 1 条", Source: "aquatic/清蒸鲈鱼.md",
+		Category: "aquatic", Name: "清蒸鲈鱼", Path: "aquatic/清蒸鲈鱼.md",
+		Section: "计算", Servings: 2,
+		Nutrition:  map[string]string{"calories": "180 kcal"},
+		Allergens:  []string{"鱼类", "大豆"},
+		Difficulty: 2, Minutes: 15,
+		Diets:       []string{"无麸质", "低卡"},
+		Ingredients: []string{"鲈鱼", "葱", "蒸鱼豉油"},
+		Heat:        []string{"大火"},
+	}
+	d := FromChunk(c)
+	if d.Content != "## 计算\n\n鲈鱼 1 条" || d.MetaData[MetaSection] != "计算" || d.MetaData[MetaServings] != 2 || d.MetaData[MetaKcal] != 180.0 {
+		t.Fatalf("unexpected document: %+v", d)
+	}
+
+	b, err := json.Marshal(d.WithScore(0.5))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var back Document
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if back.Score() != 0.5 {
+		t.Fatalf("score = %v", back.Score())
+	}
+	got, err := ToChunk(&back)
+	if err != nil {
+		t.Fatalf("to chunk: %v", err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, c)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	doc := FromChunk(types.Chunk{
+		Category: "meat_dish", Allergens: []string{"大豆"}, Difficulty: 2, Minutes: 25,
+		Diets: []string{"无麸质", "低脂"}, Ingredients: []string{"鸡胸肉", "青椒", "生抽"},
+		Nutrition: map[string]string{"calories": "320 kcal"},
+	})
+	// 经 JSON 往返，与本地存储与 ES 返回的元数据类型一致
+	var meta map[string]any
+	b, _ := json.Marshal(doc.MetaData)
+	_ = json.Unmarshal(b, &meta)
+
+	num := func(v float64) *float64 { return &v }
+	cases := []struct {
+		name string
+		f    Filter
+		want bool
+	}{
+		{"empty", Filter{}, true},
+		{"terms", Filter{Terms: map[string][]string{MetaCategory: {"soup", "meat_dish"}}}, true},
+		{"all", Filter{All: map[string][]string{MetaDiets: {"无麸质", "低脂"}}}, true},
+		{"all missing", Filter{All: map[string][]string{MetaDiets: {"无麸质", "素食"}}}, false},
+		{"not", Filter{Not: map[string][]string{MetaAllergens: {"花生"}}}, true},
+		{"not hit", Filter{Not: map[string][]string{MetaAllergens: {"花生", "大豆"}}}, false},
+		{"range", Filter{Ranges: map[string]Range{MetaMinutes: {Max: num(30)}, MetaKcal: {Min: num(200), Max: num(400)}}}, true},
+		{"range out", Filter{Ranges: map[string]Range{MetaDifficulty: {Min: num(3)}}}, false},
+		{"range missing field", Filter{Ranges: map[string]Range{MetaFat: {Max: num(10)}}}, false},
+		{"contains", Filter{Contains: map[string][]string{MetaIngredients: {"鸡", "椒"}}}, true},
+		{"contains missing", Filter{Contains: map[string][]string{MetaIngredients: {"鸡", "花生"}}}, false},
+		{"not contains", Filter{NotContains: map[string][]string{MetaIngredients: {"花生"}}}, true},
+		{"not contains hit", Filter{NotContains: map[string][]string{MetaIngredients: {"胸肉"}}}, false},
+	}
+	for _, c := range cases {
+		if got := c.f.Match(meta); got != c.want {
+			t.Errorf("%s: Match = %v, want %v", c.name, got, c.want)
+		}
+		if c.name != "empty" && c.f.Empty() {
+			t.Errorf("%s: Empty = true", c.name)
+		}
+	}
+	if !(Filter{Ranges: map[string]Range{MetaKcal: {}}, Terms: map[string][]string{MetaCategory: nil}}).Empty() {
+		t.Error("filter with only empty conditions is not Empty")
+	}
+}
@@ -0,0 +1,52 @@
+//go:build eino
+
+// 文件功能：Document 与 Eino schema.Document 之间的转换；仅在 eino 构建标签下编译（go build -tags eino），
+// 默认构建不依赖 Eino 模块。
+package vector
+
+import (
+	"github.com/cloudwego/eino/schema"
+)
+
+// ToSchema：转换为 Eino schema.Document，供 Eino 索引器与检索器使用；元数据复制顶层映射，得分与稠密向量沿用相同的键名。
+func (d *Document) ToSchema() *schema.Document {
+	return &schema.Document{ID: d.ID, Content: d.Content, MetaData: copyMeta(d.MetaData)}
+}
+
+// FromSchema：由 Eino schema.Document 转换；nil 返回 nil。
+func FromSchema(d *schema.Document) *Document {
+	if d == nil {
+		return nil
+	}
+	return &Document{ID: d.ID, Content: d.Content, MetaData: copyMeta(d.MetaData)}
+}
+
+// ToSchemaDocuments：批量转换为 Eino schema.Document。
+func ToSchemaDocuments(docs []*Document) []*schema.Document {
+	out := make([]*schema.Document, len(docs))
+	for i, d := range docs {
+		out[i] = d.ToSchema()
+	}
+	return out
+}
+
+// FromSchemaDocuments：批量由 Eino schema.Document 转换。
+func FromSchemaDocuments(docs []*schema.Document) []*Document {
+	out := make([]*Document, len(docs))
+	for i, d := range docs {
+		out[i] = FromSchema(d)
+	}
+	return out
+}
+
+// copyMeta：复制元数据顶层映射，转换后双方写入得分互不影响。
+func copyMeta(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
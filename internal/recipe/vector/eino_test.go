@@ -0,0 +1,51 @@
+//go:build eino
+
+// 文件功能：Document 与 Eino schema.Document 转换的单元测试（go test -tags eino）；验证元数据、得分与稠密向量的往返还原。
+package vector
+
+import (
+	"reflect"
+	"testing"
+
+	"cook/internal/recipe/parser/types"
+)
+
+func TestSchemaRoundTrip(t *testing.T) {
+	c := types.Chunk{
+		ID: "chunk-3", DocID: "doc-1", Index: 2,
+		Header: "## 计算", Text: "鲈鱼 1 条", Source: "aquatic/清蒸鲈鱼.md",
+		Category: "aquatic", Name: "清蒸鲈鱼", Path: "aquatic/清蒸鲈鱼.md",
+		Section: "计算", Servings: 2,
+		Nutrition:  map[string]string{"calories": "180 kcal"},
+		Allergens:  []string{"鱼类", "大豆"},
+		Difficulty: 2, Minutes: 15,
+		Diets:       []string{"无麸质", "低卡"},
+		Ingredients: []string{"鲈鱼", "葱", "蒸鱼豉油"},
+		Heat:        []string{"大火"},
+	}
+	d := FromChunk(c).WithScore(0.5).WithDenseVector([]float64{1, 0, 0})
+	s := d.ToSchema()
+	if s.ID != c.ID || s.Content != d.Content || s.Score() != 0.5 || !reflect.DeepEqual(s.DenseVector(), []float64{1, 0, 0}) {
+		t.Fatalf("schema = %+v", s)
+	}
+	if s.MetaData[MetaName] != "清蒸鲈鱼" || s.MetaData[MetaKcal] != 180.0 {
+		t.Fatalf("schema metadata = %v", s.MetaData)
+	}
+
+	back := FromSchema(s.WithScore(0.9))
+	if back.Score() != 0.9 || d.Score() != 0.5 {
+		t.Fatalf("round trip score = %v, original %v", back.Score(), d.Score())
+	}
+	got, err := ToChunk(back)
+	if err != nil {
+		t.Fatalf("to chunk: %v", err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, c)
+	}
+
+	docs := FromSchemaDocuments(ToSchemaDocuments([]*Document{d, FromChunk(c)}))
+	if len(docs) != 2 || docs[0].ID != c.ID || FromSchema(nil) != nil {
+		t.Fatalf("batch round trip = %+v", docs)
+	}
+}
@@ -0,0 +1,210 @@
+// 文件功能：向量存储接口；索引流程通过该接口写入文档，Elasticsearch 与本地存储等后端各自实现。
+package vector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Store：向量存储。
+//   - Ensure：按向量维度准备存储（建索引、写映射）；已存在且维度一致时不做修改；
+//   - Upsert：写入或覆盖文档；文档须带稠密向量（WithDenseVector）；
+//   - Delete：按 ID 删除文档；不存在的 ID 忽略；
+//   - Prune：删除不在 keep 中的全部文档，用于全量索引后清理已从语料中消失的分块；
+//   - Flush：使此前的写入持久化并可检索（Elasticsearch 刷新索引，本地存储写出快照）。
+//
+// 部分文档写入失败时返回 *BulkError，其余文档已写入。
+type Store interface {
+	Ensure(ctx context.Context, dims int) error
+	Upsert(ctx context.Context, docs []*Document) error
+	Delete(ctx context.Context, ids []string) error
+	Prune(ctx context.Context, keep []string) (int, error)
+	Flush(ctx context.Context) error
+}
+
+// SearchOptions：向量检索参数。
+//   - TopK：返回条数；
+//   - Filter：元数据过滤；零值表示不过滤。
+type SearchOptions struct {
+	TopK   int
+	Filter Filter
+}
+
+// Searcher：向量检索；按余弦相似度降序返回文档，得分写入 Score（不含稠密向量）。
+type Searcher interface {
+	Search(ctx context.Context, vec []float64, opts SearchOptions) ([]*Document, error)
+}
+
+// Filter：元数据过滤；各类条件之间、不同字段之间均为“且”。
+//   - Terms：关键字字段（如 category、name、path、section、allergens）到可接受取值的映射；同一字段的多个取值为“或”，
+//     数组字段（allergens、diets）任一元素命中即可；
+//   - All：数组字段到必须全部出现的取值（如同时满足多个饮食标签）；
+//   - Not：关键字字段到不得出现的取值（如排除过敏原）；字段缺失视为满足；
+//   - Ranges：数值字段（如 difficulty、total_minutes、kcal）到取值范围；字段缺失视为不满足；
+//   - Contains：字符串或数组字段到子串列表；每个子串都须出现在某个元素中（如必须包含的原料）；
+//   - NotContains：字符串或数组字段到子串列表；任何元素都不得包含其中任一子串（如排除的原料）。
+type Filter struct {
+	Terms       map[string][]string
+	All         map[string][]string
+	Not         map[string][]string
+	Ranges      map[string]Range
+	Contains    map[string][]string
+	NotContains map[string][]string
+}
+
+// Range：数值范围（闭区间）；Min/Max 为 nil 表示该侧不限。
+type Range struct {
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+// Empty：是否两侧都不限。
+func (r Range) Empty() bool { return r.Min == nil && r.Max == nil }
+
+// Contains：v 是否在范围内。
+func (r Range) Contains(v float64) bool {
+	return (r.Min == nil || v >= *r.Min) && (r.Max == nil || v <= *r.Max)
+}
+
+// Empty：是否没有任何过滤条件。
+func (f Filter) Empty() bool {
+	for _, m := range []map[string][]string{f.Terms, f.All, f.Not, f.Contains, f.NotContains} {
+		for _, vs := range m {
+			if len(vs) > 0 {
+				return false
+			}
+		}
+	}
+	for _, r := range f.Ranges {
+		if !r.Empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Match：文档元数据是否满足过滤条件。
+func (f Filter) Match(meta map[string]any) bool {
+	for field, want := range f.Terms {
+		if len(want) > 0 && !anyTerm(meta[field], want) {
+			return false
+		}
+	}
+	for field, want := range f.All {
+		for _, w := range want {
+			if !anyTerm(meta[field], []string{w}) {
+				return false
+			}
+		}
+	}
+	for field, not := range f.Not {
+		if len(not) > 0 && anyTerm(meta[field], not) {
+			return false
+		}
+	}
+	for field, r := range f.Ranges {
+		if r.Empty() {
+			continue
+		}
+		v, ok := toFloat(meta[field])
+		if !ok || !r.Contains(v) {
+			return false
+		}
+	}
+	for field, subs := range f.Contains {
+		for _, sub := range subs {
+			if !anySubstring(meta[field], sub) {
+				return false
+			}
+		}
+	}
+	for field, subs := range f.NotContains {
+		for _, sub := range subs {
+			if anySubstring(meta[field], sub) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// anySubstring：元数据取值（字符串或数组）中是否有元素包含 sub。
+func anySubstring(v any, sub string) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.Contains(t, sub)
+	case []string:
+		for _, s := range t {
+			if strings.Contains(s, sub) {
+				return true
+			}
+		}
+		return false
+	case []any:
+		for _, s := range t {
+			if anySubstring(s, sub) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(fmt.Sprint(v), sub)
+}
+
+// anyTerm：元数据取值（字符串、数值或数组）是否命中 want 中任一取值。
+func anyTerm(v any, want []string) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case []string:
+		for _, s := range t {
+			if anyTerm(s, want) {
+				return true
+			}
+		}
+		return false
+	case []any:
+		for _, s := range t {
+			if anyTerm(s, want) {
+				return true
+			}
+		}
+		return false
+	}
+	s := fmt.Sprint(v)
+	for _, w := range want {
+		if s == w {
+			return true
+		}
+	}
+	return false
+}
+
+// Failure：单个文档的写入失败。
+type Failure struct {
+	ID     string `json:"id"`     // 文档标识
+	Status int    `json:"status"` // 状态码（后端定义）
+	Reason string `json:"reason"` // 失败原因
+}
+
+// BulkError：批量写入中部分文档失败。
+type BulkError struct {
+	Failures []Failure
+}
+
+// Error：汇总失败条数与前三条原因。
+func (e *BulkError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d documents failed", len(e.Failures))
+	for i, f := range e.Failures {
+		if i == 3 {
+			b.WriteString("; ...")
+			break
+		}
+		fmt.Fprintf(&b, "; %s: %d %s", f.ID, f.Status, f.Reason)
+	}
+	return b.String()
+}
@@ -1,4 +1,4 @@
-// 文件功能：命令行入口，读取菜谱 Markdown 文档并委派内部解析器生成 JSONL 输出；提供基础 CLI 参数配置与文件写出服务。
+// 文件功能：命令行入口，读取菜谱文档（Markdown、纯文本等已注册格式）并委派内部解析器生成 JSONL、CSV、Eino Document、JSON-LD 等格式输出；提供基础 CLI 参数配置与文件写出服务。
 package main
 
 import (
@@ -6,17 +6,23 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"cook/internal/recipe/config"
+	"cook/internal/recipe/corpus"
+	"cook/internal/recipe/export"
 	parser "cook/internal/recipe/parser"
-	"cook/internal/recipe/parser/impl"
+	_ "cook/internal/recipe/parser/impl"
 	"cook/internal/recipe/parser/types"
 )
 
 // keep CLI flags and behavior; parsing is delegated to internal parser implementation
 
-// main：解析 CLI 参数并调用内部解析器完成 Markdown→Chunk→JSONL 的生成；对错误进行标准输出与退出码处理。
+// main：解析 CLI 参数并调用解析器注册表完成 文档→Chunk/Recipe→目标格式 的生成；对错误进行标准输出与退出码处理。
 func main() {
 	var (
 		dir       string
@@ -24,22 +30,58 @@ func main() {
 		chunkSize int
 		overlap   int
 		byHeader  bool
+		include   string
+		exclude   string
+		format    string
+		incr      bool
+		manifest  string
 	)
 	flag.StringVar(&dir, "dir", defaultRecipesDir(), "recipes root directory")
-	flag.StringVar(&out, "out", filepath.Join("parse", "out", "chunks.jsonl"), "output JSONL file path")
+	flag.StringVar(&out, "out", "", "output file path (default parse/out/<file named by format>)")
+	flag.StringVar(&format, "format", export.FormatJSONL, "output format: "+strings.Join(export.Formats, "|"))
 	flag.IntVar(&chunkSize, "chunk", 1200, "max characters per chunk (when not splitting by header)")
 	flag.IntVar(&overlap, "overlap", 100, "overlap characters between chunks")
 	flag.BoolVar(&byHeader, "byHeader", true, "split chunks using Markdown headers when possible")
+	flag.StringVar(&include, "include", "", "comma-separated include globs, appended to parser.include in config")
+	flag.StringVar(&exclude, "exclude", "", "comma-separated exclude globs, appended to parser.exclude in config")
+	flag.BoolVar(&incr, "incremental", false, "emit only changed chunks and deletion tombstones as delta JSONL, tracked by -manifest")
+	flag.StringVar(&manifest, "manifest", filepath.Join("parse", "out", "manifest.json"), "file manifest path for -incremental")
 	flag.Parse()
+	if !slices.Contains(export.Formats, format) {
+		fmt.Fprintf(os.Stderr, "unknown format: %s\n", format)
+		os.Exit(1)
+	}
+	if incr && format != export.FormatJSONL {
+		fmt.Fprintf(os.Stderr, "-incremental only supports format %s\n", export.FormatJSONL)
+		os.Exit(1)
+	}
+	if out == "" {
+		out = filepath.Join("parse", "out", export.DefaultFileName(format))
+		if incr {
+			out = filepath.Join("parse", "out", "delta.jsonl")
+		}
+	}
 
-	var p parser.Parser = impl.NewMarkdownParser()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load config error: %v\n", err)
+		os.Exit(1)
+	}
+	// 关键逻辑：使用默认注册表按文件类型分派；Markdown、纯文本等实现通过导入 impl 包自行注册。
+	reg := parser.Default()
+	reg.Filter = parser.Filter{
+		Include:    append(cfg.Parser.Include, splitList(include)...),
+		Exclude:    append(cfg.Parser.Exclude, splitList(exclude)...),
+		IgnoreFile: cfg.Parser.IgnoreFile,
+	}
+	var p parser.Parser = reg
 	files, err := p.Collect(dir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "collect files error: %v\n", err)
 		os.Exit(1)
 	}
 	if len(files) == 0 {
-		fmt.Fprintf(os.Stderr, "no markdown files under: %s\n", dir)
+		fmt.Fprintf(os.Stderr, "no recipe files under: %s\n", dir)
 		os.Exit(2)
 	}
 
@@ -52,20 +94,63 @@ func main() {
 		fmt.Fprintf(os.Stderr, "create out file error: %v\n", err)
 		os.Exit(4)
 	}
-	defer f.Close()
-	w := bufio.NewWriter(f)
-	defer w.Flush()
 
-	chunks, err := p.ParseFiles(files, types.Options{ByHeader: byHeader, ChunkSize: chunkSize, Overlap: overlap, Timestamp: true})
+	// 关键逻辑：Recipe 类格式走结构化解析，其余格式输出 Chunk 流；两者共享同一注册表与过滤规则。
+	opts := types.Options{ByHeader: byHeader, ChunkSize: chunkSize, Overlap: overlap, Timestamp: true, Root: dir}
+	var werr error
+	switch {
+	case incr:
+		werr = writeDelta(f, p, files, opts, manifest)
+	case export.IsRecipeFormat(format):
+		recipes, err := reg.ParseRecipes(files, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "parse recipes error: %v\n", err)
+			os.Exit(5)
+		}
+		werr = export.WriteRecipes(f, format, recipes)
+	default:
+		chunks, err := p.ParseFiles(files, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "parse files error: %v\n", err)
+			os.Exit(5)
+		}
+		// 关键逻辑：全量输出与 -incremental 变更使用同一套稳定 ID，变更记录才能直接作用于全量输出；
+		corpus.AssignStableIDs(chunks)
+		werr = export.WriteChunks(f, format, chunks)
+	}
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		fmt.Fprintf(os.Stderr, "write %s error: %v\n", format, werr)
+		os.Exit(6)
+	}
+}
+
+// writeDelta：增量模式；对比清单写出 upsert/delete 变更，成功后再保存新清单，保证失败时可安全重跑。
+func writeDelta(w io.Writer, p parser.Parser, files []string, opts types.Options, manifestPath string) error {
+	prev, err := corpus.LoadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	deltas, next, st, err := corpus.Incremental(p, files, opts, prev)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "parse files error: %v\n", err)
-		os.Exit(5)
+		return err
 	}
-	for _, c := range chunks {
-		if err := writeJSONL(w, c); err != nil {
-			fmt.Fprintf(os.Stderr, "write jsonl error: %v\n", err)
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	for _, d := range deltas {
+		if err := enc.Encode(d); err != nil {
+			return err
 		}
 	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "files=%d added=%d changed=%d removed=%d unchanged=%d upserts=%d deletes=%d\n",
+		st.Files, st.Added, st.Changed, st.Removed, st.Unchanged, st.Upserts, st.Deletes)
+	return next.Save(manifestPath)
 }
 
 // defaultRecipesDir：自动探测默认菜谱目录；优先使用 FitDietAI/recipes，其次兼容 recipes/recipies。
@@ -84,17 +169,13 @@ func defaultRecipesDir() string {
 	return filepath.Join("FitDietAI", "recipes")
 }
 
-// writeJSONL：将单个分块以一行 JSON 形式写入到输出文件；调用方负责缓冲区刷新与文件关闭。
-func writeJSONL(w *bufio.Writer, c types.Chunk) error {
-	b, err := json.Marshal(c)
-	if err != nil {
-		return err
-	}
-	if _, err := w.Write(b); err != nil {
-		return err
-	}
-	if _, err := w.WriteString("\n"); err != nil {
-		return err
+// splitList：将逗号分隔的参数拆分为去空白的非空项集合。
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
 	}
-	return nil
+	return out
 }